@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"plane.watch/lib/export"
+	"plane.watch/lib/nats_io"
+	"strings"
+	"time"
+)
+
+// handles ACAS (TCAS) Resolution Advisories coming in from the acas-ra NATS queue
+const acasRaSubject = "acas-ra"
+
+// acasAlertRepeat is how long before we tell a user about the same aircraft's RA again
+const acasAlertRepeat = 5 * time.Minute
+
+type (
+	pwAcasClient struct {
+		server *nats_io.Server
+		log    zerolog.Logger
+
+		handleRa func(ra *export.AcasRa)
+	}
+
+	acasAlert struct {
+		time        time.Time
+		alert       *location
+		ra          *export.AcasRa
+		distanceMtr int
+	}
+)
+
+func (ac *pwAcasClient) handleAcasFeed(url string) {
+	if nil == ac.handleRa {
+		panic("You need to specify the handleRa method")
+	}
+	var err error
+	ac.log.Info().Str("url", url).Msg("Connecting...")
+	if ac.server, err = nats_io.NewServer(url); nil != err {
+		ac.log.Error().Err(err).Msg("Unable to connect to NATS, no ACAS RA alerts will be sent")
+		return
+	}
+	ch, err := ac.server.Subscribe(acasRaSubject)
+	if nil != err {
+		ac.log.Error().Err(err).Str("subject", acasRaSubject).Msg("Unable to subscribe")
+		return
+	}
+
+	for msg := range ch {
+		ra := export.AcasRa{}
+		if err = json.Unmarshal(msg.Data, &ra); nil != err {
+			ac.log.Error().Err(err).Msg("Unable to decode ACAS RA")
+			continue
+		}
+		ac.handleRa(&ra)
+	}
+}
+
+func (ac *pwAcasClient) stop() error {
+	if nil != ac.server {
+		ac.server.Close()
+	}
+	return nil
+}
+
+// handleAcasRa tells everyone who has a location near the aircraft about the RA
+func (a *pwAlertBot) handleAcasRa(ra *export.AcasRa) {
+	if nil == ra || !ra.HasLocation {
+		return
+	}
+
+	forLocation(ra.TileLocation, func(alert *location) {
+		distance := getDistanceBetween(ra.Lat, ra.Lon, alert.Lat, alert.Lon)
+		ac := alert.AlertConfig.configForHeight(ra.Altitude)
+		if nil == ac {
+			return
+		}
+		// RAs are rare enough that we alert even if the user has turned off proximity alerts at this height
+		if distance <= ac.AlertRadiusMtr {
+			a.alertUserAcas(&acasAlert{
+				time:        time.Now(),
+				alert:       alert,
+				ra:          ra,
+				distanceMtr: distance,
+			})
+		}
+	})
+}
+
+func (a *pwAlertBot) alertUserAcas(aa *acasAlert) {
+	if nil == aa || nil == a.sendAcasAlert {
+		return
+	}
+	key := aa.Key()
+
+	// an RA is reported many times while it is active, only tell the user once
+	if existing, ok := a.acasAlerts.Load(key); ok {
+		if !existing.(*acasAlert).time.Before(aa.time.Add(-acasAlertRepeat)) {
+			return
+		}
+	}
+	a.acasAlerts.Store(key, aa)
+	a.log.Debug().Msgf("Sending ACAS Alert")
+
+	a.sendAcasAlert(aa)
+}
+
+func (aa *acasAlert) Key() string {
+	return aa.alert.DiscordUserId + aa.alert.LocationName + aa.ra.Icao
+}
+
+// Plane gives us something to look at for the aircraft that had the RA
+func (aa *acasAlert) Plane() string {
+	if nil != aa.ra.CallSign && "" != *aa.ra.CallSign {
+		return *aa.ra.CallSign
+	}
+	return "ICAO: " + aa.ra.Icao
+}
+
+// Threat gives us something to look at for the other aircraft
+func (aa *acasAlert) Threat() string {
+	if nil != aa.ra.ThreatCallSign && "" != *aa.ra.ThreatCallSign {
+		return *aa.ra.ThreatCallSign
+	}
+	if nil != aa.ra.ThreatIcao {
+		return "ICAO: " + *aa.ra.ThreatIcao
+	}
+	return "Unknown"
+}
+
+func (aa *acasAlert) Advisories() string {
+	if 0 == len(aa.ra.Advisories) {
+		return "None"
+	}
+	return strings.Join(aa.ra.Advisories, ", ")
+}
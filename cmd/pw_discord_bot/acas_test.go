@@ -0,0 +1,64 @@
+package main
+
+import (
+	"plane.watch/lib/dedupe"
+	"plane.watch/lib/export"
+	"testing"
+	"time"
+)
+
+func Test_pwAlertBot_alertUserAcas(t *testing.T) {
+	// make sure we only tell the user once about an RA, even though it is reported many times
+	var alertCount int
+	a := &pwAlertBot{
+		acasAlerts: dedupe.NewForgetfulSyncMap(time.Minute, acasAlertRepeat),
+		sendAcasAlert: func(aa *acasAlert) {
+			alertCount++
+		},
+	}
+
+	a.alertUserAcas(nil)
+	if 0 != alertCount {
+		t.Errorf("Sent an alert when nil")
+	}
+
+	aa := acasAlert{
+		time: time.Now(),
+		alert: &location{
+			LocationName:  "test-1",
+			DiscordUserId: "testerer",
+		},
+		ra: &export.AcasRa{
+			Icao:        "01AB23",
+			Advisories:  []string{"Corrective", "Upward Sense"},
+			HasLocation: true,
+		},
+		distanceMtr: 23,
+	}
+	expected := "testerertest-101AB23"
+	if expected != aa.Key() {
+		t.Errorf("Did not generate the correct key. %s != %s", expected, aa.Key())
+	}
+	if "Unknown" != aa.Threat() {
+		t.Errorf("Expected an unknown threat, got %s", aa.Threat())
+	}
+	if "Corrective, Upward Sense" != aa.Advisories() {
+		t.Errorf("Incorrect advisories: %s", aa.Advisories())
+	}
+
+	a.alertUserAcas(&aa)
+	if 1 != alertCount {
+		t.Errorf("Expected to send an alert")
+	}
+	aa.time = aa.time.Add(time.Minute)
+	a.alertUserAcas(&aa)
+	if 1 != alertCount {
+		t.Errorf("Should not have sent the same RA alert twice")
+	}
+	later := aa
+	later.time = aa.time.Add(10 * time.Minute)
+	a.alertUserAcas(&later)
+	if 2 != alertCount {
+		t.Errorf("Expected to alert again for a new RA")
+	}
+}
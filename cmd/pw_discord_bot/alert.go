@@ -4,16 +4,14 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"math"
+	"plane.watch/lib/dedupe"
 	"plane.watch/lib/export"
+	"plane.watch/lib/geo"
 	"sync"
 	"time"
 )
 
 // handles the determining if an alert needs to be sent to a user
-const (
-	earthRadiusMtr = 6371000
-)
-
 type (
 	pwAlertBot struct {
 		locationUpdates  chan *export.PlaneLocation
@@ -26,6 +24,10 @@ type (
 		log zerolog.Logger
 
 		sendAlert func(pa *proximityAlert)
+
+		// keeps track of when we told a user about an aircraft's ACAS RA, forgotten after acasAlertRepeat
+		acasAlerts    *dedupe.ForgetfulSyncMap
+		sendAcasAlert func(aa *acasAlert)
 	}
 
 	proximityAlert struct {
@@ -132,19 +134,5 @@ func (pa *proximityAlert) Key() string {
 // getDistanceBetween takes 2 Lat/Lon pairs and calculates the distance between them, in metres
 // we use the Great Circle calculation method
 func getDistanceBetween(lat1, lon1, lat2, lon2 float64) int {
-	// http://janmatuschek.de/LatitudeLongitudeBoundingCoordinates
-	//  1 Distance Between Two Given Points
-	//  dist = arccos(sin(lat1) · sin(lat2) + cos(lat1) · cos(lat2) · cos(lon1 - lon2)) · R
-
-	radLat1 := lat1 * math.Pi / 180
-	radLon1 := lon1 * math.Pi / 180
-	radLat2 := lat2 * math.Pi / 180
-	radLon2 := lon2 * math.Pi / 180
-
-	distanceBetween := math.Acos(
-		(math.Sin(radLat1)*math.Sin(radLat2))+
-			(math.Cos(radLat1)*math.Cos(radLat2)*math.Cos(radLon1-radLon2)),
-	) * earthRadiusMtr
-
-	return int(math.Round(distanceBetween))
+	return int(math.Round(geo.Distance(lat1, lon1, lat2, lon2)))
 }
@@ -10,8 +10,10 @@ import (
 	"github.com/urfave/cli/v2"
 	"os"
 	"os/signal"
+	"plane.watch/lib/dedupe"
 	"plane.watch/lib/export"
 	"syscall"
+	"time"
 )
 
 type (
@@ -19,6 +21,7 @@ type (
 		pwDiscordBot
 		pwWsClient
 		pwAlertBot
+		pwAcasClient
 
 		log zerolog.Logger
 	}
@@ -29,6 +32,7 @@ func NewPlaneWatchBot(token string) (*PwBot, error) {
 		pwAlertBot: pwAlertBot{
 			locationUpdates:  make(chan *export.PlaneLocation, 100),
 			numUpdateWorkers: 10,
+			acasAlerts:       dedupe.NewForgetfulSyncMap(time.Minute, acasAlertRepeat),
 			log:              log.With().Str("Service", "Alert Handler").Logger(),
 		},
 		pwDiscordBot: pwDiscordBot{
@@ -38,6 +42,9 @@ func NewPlaneWatchBot(token string) (*PwBot, error) {
 		pwWsClient: pwWsClient{
 			wsLog: log.With().Str("Service", "WS Handler").Logger(),
 		},
+		pwAcasClient: pwAcasClient{
+			log: log.With().Str("Service", "ACAS Handler").Logger(),
+		},
 		log: log.With().Str("Service", "PW Main Bot").Logger(),
 	}
 
@@ -47,6 +54,12 @@ func NewPlaneWatchBot(token string) (*PwBot, error) {
 	b.pwAlertBot.sendAlert = func(pa *proximityAlert) {
 		b.pwDiscordBot.sendPlaneAlert(pa)
 	}
+	b.pwAcasClient.handleRa = func(ra *export.AcasRa) {
+		b.pwAlertBot.handleAcasRa(ra)
+	}
+	b.pwAlertBot.sendAcasAlert = func(aa *acasAlert) {
+		b.pwDiscordBot.sendAcasAlert(aa)
+	}
 
 	if err := b.pwDiscordBot.setup(token); nil != err {
 		return nil, err
@@ -61,6 +74,9 @@ func (b *PwBot) Run(c *cli.Context) error {
 	loadLocationsList()
 
 	go b.handleWebsocketClient(c.String("host"), c.Bool("insecure"))
+	if "" != c.String("acas-nats") {
+		go b.handleAcasFeed(c.String("acas-nats"))
+	}
 	b.runAlerts()
 
 	b.session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
@@ -89,6 +105,9 @@ func (b *PwBot) Run(c *cli.Context) error {
 	if err = b.pwWsClient.stop(); nil != err {
 		errs = append(errs, err)
 	}
+	if err = b.pwAcasClient.stop(); nil != err {
+		errs = append(errs, err)
+	}
 	if err = b.pwAlertBot.stop(); nil != err {
 		errs = append(errs, err)
 	}
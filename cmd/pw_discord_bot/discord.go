@@ -6,6 +6,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/olekukonko/tablewriter"
 	"github.com/rs/zerolog"
+	"plane.watch/lib/icao"
 	"strconv"
 	"strings"
 	"sync"
@@ -468,6 +469,50 @@ func (b *pwDiscordBot) sendPlaneAlert(pa *proximityAlert) {
 			},
 		},
 	}
+	if icaoAddr, err := strconv.ParseUint(pa.update.Icao, 16, 32); nil == err {
+		if country, ok := icao.Lookup(uint32(icaoAddr)); ok {
+			e.Fields = append(e.Fields, &discordgo.MessageEmbedField{Name: "Registered In", Value: country.Name, Inline: true})
+		}
+	}
+	if pa.update.Military {
+		e.Title = "Military " + e.Title
+	}
 
 	b.sendDirectEmbedMsg(pa.alert.DiscordUserId, &e)
 }
+
+func (b *pwDiscordBot) sendAcasAlert(aa *acasAlert) {
+	if nil == aa || nil == aa.alert || nil == aa.ra {
+		return
+	}
+	b.log.
+		Debug().
+		Str("User", aa.alert.DiscordUserName).
+		Str("Plane", aa.ra.Icao).
+		Int("Distance (m)", aa.distanceMtr).
+		Msg("Alerting user of ACAS RA")
+
+	e := discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("ACAS Resolution Advisory near %s", aa.alert.LocationName),
+		Description: fmt.Sprintf("%s has been given a resolution advisory against %s", aa.Plane(), aa.Threat()),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Advisory",
+				Value:  aa.Advisories(),
+				Inline: false,
+			},
+			{
+				Name:   "Altitude",
+				Value:  fmt.Sprintf("%d %s", aa.ra.Altitude, aa.ra.AltitudeUnits),
+				Inline: true,
+			},
+			{
+				Name:   "Distance",
+				Value:  fmt.Sprintf("%d m", aa.distanceMtr),
+				Inline: true,
+			},
+		},
+	}
+
+	b.sendDirectEmbedMsg(aa.alert.DiscordUserId, &e)
+}
@@ -50,6 +50,11 @@ func main() {
 			Usage: "Use this if your connection is not TLS protected",
 			Value: false,
 		},
+		&cli.StringFlag{
+			Name:  "acas-nats",
+			Usage: "The NATS server to listen to for ACAS Resolution Advisories, e.g. nats://localhost:4222. Leave empty to not send RA alerts",
+			Value: "",
+		},
 	}
 
 	logging.IncludeVerbosityFlags(app)
@@ -14,7 +14,10 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 	"plane.watch/lib/dedupe"
+	"plane.watch/lib/enrich"
+	"plane.watch/lib/geofence"
 	"plane.watch/lib/monitoring"
+	"plane.watch/lib/setup"
 
 	"plane.watch/lib/logging"
 )
@@ -39,6 +42,13 @@ type (
 
 		syncSamples *dedupe.ForgetfulSyncMap
 
+		// geofences are the areas we send enter/exit/dwell events for, nil if we have none
+		geofences        *geofence.Engine
+		geofenceRouteKey string
+
+		// enrichers fill in what we know about a plane before we pass it on, empty if we have none
+		enrichers enrich.Enrichers
+
 		haveSourceSinkConnection bool
 
 		incomingMessages chan []byte
@@ -78,6 +88,14 @@ var (
 		Name: "pw_router_cache_eviction_total",
 		Help: "The number of cache evictions made from the cache.",
 	})
+	geofenceEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pw_router_geofence_events_total",
+		Help: "The total number of geofence enter, exit and dwell events published.",
+	})
+	updatesEnriched = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pw_router_updates_enriched_total",
+		Help: "The total number of messages we filled in aircraft or route details for.",
+	})
 )
 
 func main() {
@@ -164,7 +182,16 @@ func main() {
 			Name:  "register-test-queues",
 			Usage: "Subscribes a bunch of queues to our routing keys.",
 		},
+		&cli.StringFlag{
+			Name:    "geofence-route-key",
+			Usage:   "Name of the routing key to publish geofence events to.",
+			Value:   "geofence",
+			EnvVars: []string{"GEOFENCE_ROUTE_KEY"},
+		},
 	}
+	setup.IncludeGeofenceFlags(app)
+	setup.IncludeAirportFlags(app)
+	setup.IncludeEnrichFlags(app)
 	logging.IncludeVerbosityFlags(app)
 	monitoring.IncludeMonitoringFlags(app, 9601)
 
@@ -194,7 +221,18 @@ func run(c *cli.Context) error {
 	var err error
 	// connect to rabbitmq, create ourselves 2 queues
 	r := pwRouter{
-		syncSamples: dedupe.NewForgetfulSyncMap(time.Duration(c.Int("update-age-sweep-interval"))*time.Second, time.Duration(c.Int("update-age"))*time.Second),
+		syncSamples:      dedupe.NewForgetfulSyncMap(time.Duration(c.Int("update-age-sweep-interval"))*time.Second, time.Duration(c.Int("update-age"))*time.Second),
+		geofenceRouteKey: c.String("geofence-route-key"),
+	}
+	if r.geofences, err = setup.HandleGeofenceFlags(c); nil != err {
+		return err
+	}
+	airportsDb, err := setup.HandleAirportFlags(c)
+	if nil != err {
+		return err
+	}
+	if r.enrichers, err = setup.HandleEnrichFlags(c, airportsDb); nil != err {
+		return err
 	}
 
 	r.syncSamples.SetEvictionAction(func(key interface{}, value interface{}) {
@@ -252,6 +290,10 @@ func run(c *cli.Context) error {
 		for _, theMq := range r.mqs {
 			theMq.close()
 		}
+		if nil != r.geofences {
+			r.geofences.Stop()
+		}
+		r.enrichers.Stop()
 		// and then close all the things
 		cancel()
 	}()
@@ -23,9 +23,12 @@ var (
 
 const SigHeadingChange = 1.0 // at least 1.0 degrees change.
 
+const feetPerMetre = 3.28084
+
 func (w *worker) isSignificant(last export.PlaneLocation, candidate export.PlaneLocation) bool {
 	// check the candidate vs last, if any of the following have changed
 	// - Heading, VerticalRate, Velocity, Altitude, FlightNumber, FlightStatus, OnGround, Special, Squawk
+	// - Selected Altitude/Heading, QNH, Autopilot modes
 
 	// if any of these fields differ, indicate this update is significant
 	if candidate.HasHeading && last.HasHeading && math.Abs(candidate.Heading-last.Heading) > SigHeadingChange {
@@ -122,6 +125,15 @@ func (w *worker) isSignificant(last export.PlaneLocation, candidate export.Plane
 		return true
 	}
 
+	if field := autopilotChange(last, candidate); "" != field {
+		log.Debug().
+			Str("aircraft", candidate.Icao).
+			Str("field", field).
+			Int64("diff_time", int64(candidate.LastMsg.Sub(last.LastMsg))).
+			Msg("Significant autopilot change.")
+		return true
+	}
+
 	log.Debug().
 		Str("aircraft", candidate.Icao).
 		Msg("Ignoring insignificant event.")
@@ -129,6 +141,43 @@ func (w *worker) isSignificant(last export.PlaneLocation, candidate export.Plane
 	return false
 }
 
+// autopilotChange gives the name of the first autopilot field that has changed (or that we now know about), "" if none have
+func autopilotChange(last, candidate export.PlaneLocation) string {
+	int32Changed := func(l, c *int32) bool {
+		return nil != c && (nil == l || *l != *c)
+	}
+	floatChanged := func(l, c *float64) bool {
+		return nil != c && (nil == l || *l != *c)
+	}
+	boolChanged := func(l, c *bool) bool {
+		return nil != c && (nil == l || *l != *c)
+	}
+
+	switch {
+	case int32Changed(last.SelectedAltitudeMcp, candidate.SelectedAltitudeMcp):
+		return "SelectedAltitudeMcp"
+	case int32Changed(last.SelectedAltitudeFms, candidate.SelectedAltitudeFms):
+		return "SelectedAltitudeFms"
+	case floatChanged(last.SelectedHeading, candidate.SelectedHeading):
+		return "SelectedHeading"
+	case floatChanged(last.BaroSetting, candidate.BaroSetting):
+		return "BaroSetting"
+	case boolChanged(last.AutopilotEngaged, candidate.AutopilotEngaged):
+		return "AutopilotEngaged"
+	case boolChanged(last.VnavEngaged, candidate.VnavEngaged):
+		return "VnavEngaged"
+	case boolChanged(last.LnavEngaged, candidate.LnavEngaged):
+		return "LnavEngaged"
+	case boolChanged(last.AltitudeHoldEngaged, candidate.AltitudeHoldEngaged):
+		return "AltitudeHoldEngaged"
+	case boolChanged(last.ApproachEngaged, candidate.ApproachEngaged):
+		return "ApproachEngaged"
+	case boolChanged(last.TcasOperational, candidate.TcasOperational):
+		return "TcasOperational"
+	}
+	return ""
+}
+
 func (w *worker) run(ctx context.Context, ch <-chan []byte) {
 	for {
 		select {
@@ -171,6 +220,10 @@ func (w *worker) handleMsg(msg []byte) error {
 		updatesProcessed.Inc()
 	}
 
+	msg = w.enrich(&update, msg)
+
+	w.checkGeofences(update)
+
 	// lookup what we know about this plane.
 	item, ok := w.router.syncSamples.Load(update.Icao)
 
@@ -257,6 +310,58 @@ func (w *worker) handleInsignificantUpdate(update export.PlaneLocation, msg []by
 	}
 }
 
+// enrich fills in what we know about the aircraft, the message is encoded again if anything was added
+func (w *worker) enrich(update *export.PlaneLocation, msg []byte) []byte {
+	if !w.router.enrichers.Enrich(update) {
+		return msg
+	}
+	enriched, err := json.Marshal(update)
+	if nil != err {
+		log.Error().Err(err).Str("aircraft", update.Icao).Msg("could not create enriched json bytes for sending")
+		return msg
+	}
+	updatesEnriched.Inc()
+	return enriched
+}
+
+// checkGeofences publishes an event for every geofence the aircraft has gone into or out of (or dwelled in)
+func (w *worker) checkGeofences(update export.PlaneLocation) {
+	if nil == w.router.geofences {
+		return
+	}
+	if update.Removed {
+		w.router.geofences.Forget(update.Icao)
+		return
+	}
+	if !update.HasLocation {
+		return
+	}
+	var altitude *float64
+	if "" != update.AltitudeUnits {
+		feet := float64(update.Altitude)
+		if "metres" == update.AltitudeUnits {
+			feet *= feetPerMetre
+		}
+		altitude = &feet
+	}
+
+	for _, event := range w.router.geofences.Update(update.Icao, update.Lat, update.Lon, altitude, update.LastMsg) {
+		fence := event.Export(update.CallSign, update.SourceTag)
+		msg, err := json.Marshal(&fence)
+		if nil != err {
+			log.Error().Err(err).Msg("could not create geofence json bytes for sending")
+			continue
+		}
+		log.Debug().
+			Str("aircraft", update.Icao).
+			Str("fence", event.Fence.Id).
+			Str("event", event.Kind.String()).
+			Msg("Geofence event.")
+		w.publishLocationUpdate(w.router.geofenceRouteKey, msg)
+		geofenceEvents.Inc()
+	}
+}
+
 func (w *worker) publishLocationUpdate(routingKey string, msg []byte) {
 	log.Trace().Str("routing-key", routingKey).Bytes("Location", msg).Msg("Publish")
 	var sent bool
@@ -1,7 +1,64 @@
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"plane.watch/lib/enrich"
+	"plane.watch/lib/export"
+	"testing"
+)
 
 func TestWorker_handleInsignificantUpdate(t *testing.T) {
 
 }
+
+func TestWorker_isSignificantAutopilot(t *testing.T) {
+	alt1, alt2 := int32(16992), int32(17024)
+	engaged, disengaged := true, false
+	baro := 1012.8
+
+	tests := []struct {
+		name      string
+		last      export.PlaneLocation
+		candidate export.PlaneLocation
+		want      bool
+	}{
+		{name: "nothing", want: false},
+		{name: "same selected altitude", last: export.PlaneLocation{SelectedAltitudeMcp: &alt1}, candidate: export.PlaneLocation{SelectedAltitudeMcp: &alt1}, want: false},
+		{name: "new selected altitude", candidate: export.PlaneLocation{SelectedAltitudeMcp: &alt1}, want: true},
+		{name: "changed selected altitude", last: export.PlaneLocation{SelectedAltitudeMcp: &alt1}, candidate: export.PlaneLocation{SelectedAltitudeMcp: &alt2}, want: true},
+		{name: "lost selected altitude", last: export.PlaneLocation{SelectedAltitudeMcp: &alt1}, want: false},
+		{name: "new qnh", candidate: export.PlaneLocation{BaroSetting: &baro}, want: true},
+		{name: "autopilot disengaged", last: export.PlaneLocation{AutopilotEngaged: &engaged}, candidate: export.PlaneLocation{AutopilotEngaged: &disengaged}, want: true},
+		{name: "approach same", last: export.PlaneLocation{ApproachEngaged: &engaged}, candidate: export.PlaneLocation{ApproachEngaged: &engaged}, want: false},
+	}
+	w := &worker{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.isSignificant(tt.last, tt.candidate); got != tt.want {
+				t.Errorf("isSignificant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorker_enrich(t *testing.T) {
+	w := &worker{router: &pwRouter{}}
+	msg := []byte(`{"Icao":"7C6DB2"}`)
+	update := export.PlaneLocation{Icao: "7C6DB2"}
+	if got := w.enrich(&update, msg); string(msg) != string(got) {
+		t.Errorf("Without enrichers the message should not change, got %s", got)
+	}
+
+	w.router.enrichers = enrich.Enrichers{enrich.NewAircraftDbWith([]*enrich.Aircraft{{Icao: 0x7C6DB2, Registration: "VH-VXB"}})}
+	got := w.enrich(&update, msg)
+	enriched := export.PlaneLocation{}
+	if err := json.Unmarshal(got, &enriched); nil != err {
+		t.Fatalf("Enriched message is not valid JSON: %s", err)
+	}
+	if nil == enriched.Registration || "VH-VXB" != *enriched.Registration {
+		t.Errorf("Expected the registration to be filled in, got %s", got)
+	}
+	if again := w.enrich(&update, got); string(again) != string(got) {
+		t.Errorf("Enriching again should not change the message, got %s", again)
+	}
+}
@@ -65,6 +65,11 @@ func runCli(c *cli.Context) error {
 	monitoring.RunWebServer(c)
 
 	trackerOpts := make([]tracker.Option, 0)
+	crcFix, err := setup.HandleErrorCorrectionFlag(c)
+	if nil != err {
+		return err
+	}
+	trackerOpts = append(trackerOpts, crcFix)
 	trk := tracker.NewTracker(trackerOpts...)
 
 	producers, err := setup.HandleSourceFlags(c)
@@ -11,8 +11,10 @@ import (
 	"os"
 	"os/signal"
 	"path"
+	"plane.watch/lib/export"
 	"plane.watch/lib/logging"
 	"plane.watch/lib/monitoring"
+	"plane.watch/lib/sink"
 	"plane.watch/lib/tracker"
 	"plane.watch/lib/tracker/mode_s"
 	"runtime/debug"
@@ -103,21 +105,20 @@ func runHttpServer(c *cli.Context) error {
 		}()
 		switch r.URL.Path {
 		case "/decode":
-			pt := tracker.NewTracker()
-			var submittedPackets string
-			_ = r.ParseForm()
-			submittedPackets = r.FormValue("packet")
-			if "" == submittedPackets {
+			if strings.Contains(r.Header.Get("Accept"), "application/json") {
+				decodeJson(w, r)
+				return
+			}
+			packets := submittedPackets(r)
+			if 0 == len(packets) {
 				_, _ = fmt.Fprintln(w, "No Packet Provided")
 				return
 			}
-			packets := strings.Split(submittedPackets, ";")
-			icaoList := make(map[uint32]uint32)
+			pt := tracker.NewTracker()
+			// each request gets its own tracker, stop it so its goroutines do not hang around
+			defer pt.Stop()
+			icaoList := make(map[uint32]mode_s.AddressType)
 			for _, packet := range packets {
-				packet = strings.TrimSpace(packet)
-				if "" == packet {
-					continue
-				}
 				log.Debug().Str("frame", packet).Msg("Decoding Frame")
 				frame, err := mode_s.DecodeString(packet, time.Now())
 				if err != nil {
@@ -128,19 +129,17 @@ func runHttpServer(c *cli.Context) error {
 					_, _ = fmt.Fprintln(w, "Not an AVR Frame", err)
 					return
 				}
-				pt.GetPlane(frame.Icao()).HandleModeSFrame(frame, nil, nil)
-				icaoList[frame.Icao()] = frame.Icao()
+				pt.GetPlaneByAddress(frame.Icao(), frame.AddressType()).HandleModeSFrame(frame, nil, nil)
+				icaoList[frame.Icao()] = frame.AddressType()
 				frame.Describe(w)
 			}
 
-			for _, icao := range icaoList {
+			for icao, addressType := range icaoList {
 				_, _ = fmt.Fprintln(w, "")
-				plane := pt.GetPlane(icao)
+				plane := pt.GetPlaneByAddress(icao, addressType)
 				encoded, _ := json.MarshalIndent(plane, "", "  ")
 				_, _ = fmt.Fprintf(w, "%s", string(encoded))
 			}
-
-			pt = nil
 		default:
 			http.NotFound(w, r)
 			_, _ = fmt.Fprintln(w, "<br/>\n"+r.RequestURI)
@@ -165,6 +164,71 @@ func runHttpServer(c *cli.Context) error {
 	return nil
 }
 
+// submittedPackets gives us the ; separated frames from the packet form value
+func submittedPackets(r *http.Request) []string {
+	_ = r.ParseForm()
+	var packets []string
+	for _, packet := range strings.Split(r.FormValue("packet"), ";") {
+		packet = strings.TrimSpace(packet)
+		if "" != packet {
+			packets = append(packets, packet)
+		}
+	}
+	return packets
+}
+
+// decodeJson is /decode for tools, asked for with an Accept: application/json header
+func decodeJson(w http.ResponseWriter, r *http.Request) {
+	type decodeResponse struct {
+		Frames []*mode_s.Frame
+		Planes []export.PlaneLocation
+		Error  string `json:",omitempty"`
+	}
+	response := decodeResponse{
+		Frames: []*mode_s.Frame{},
+		Planes: []export.PlaneLocation{},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+
+	packets := submittedPackets(r)
+	if 0 == len(packets) {
+		w.WriteHeader(http.StatusBadRequest)
+		response.Error = "No Packet Provided"
+		_ = encoder.Encode(response)
+		return
+	}
+
+	pt := tracker.NewTracker()
+	defer pt.Stop()
+	var planes []*tracker.Plane
+	seen := make(map[*tracker.Plane]bool)
+	for _, packet := range packets {
+		frame, err := mode_s.DecodeString(packet, time.Now())
+		if nil == err && nil == frame {
+			err = errors.New("not an AVR frame")
+		}
+		if nil != err {
+			w.WriteHeader(http.StatusBadRequest)
+			response.Error = fmt.Sprintf("Failed to decode %s: %s", packet, err)
+			_ = encoder.Encode(response)
+			return
+		}
+		plane := pt.GetPlaneByAddress(frame.Icao(), frame.AddressType())
+		plane.HandleModeSFrame(frame, nil, nil)
+		response.Frames = append(response.Frames, frame)
+		if !seen[plane] {
+			seen[plane] = true
+			planes = append(planes, plane)
+		}
+	}
+	// only once all the frames are in, so we have everything they told us
+	for _, plane := range planes {
+		response.Planes = append(response.Planes, sink.PlaneLocation(plane, ""))
+	}
+	_ = encoder.Encode(response)
+}
+
 func listenHttp(exitChan chan bool, port string) {
 	log.Info().Msgf("Decode Listening on %s...", port)
 	if err := http.ListenAndServe(port, nil); nil != err {
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"plane.watch/lib/export"
+	"strings"
+	"testing"
+)
+
+func Test_decodeJson(t *testing.T) {
+	// an even and odd airborne position, examples taken from https://mode-s.org/decode/content/ads-b/3-airborne-position.html
+	form := url.Values{"packet": {"*8D40621D58C382D690C8AC2863A7;*8D40621D58C386435CC412692AD6;"}}
+	r := httptest.NewRequest(http.MethodPost, "/decode", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	decodeJson(w, r)
+
+	if http.StatusOK != w.Code {
+		t.Fatalf("Expected a 200, got %d: %s", w.Code, w.Body.String())
+	}
+	response := struct {
+		Frames []json.RawMessage
+		Planes []export.PlaneLocation
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); nil != err {
+		t.Fatalf("Invalid JSON: %s", err)
+	}
+	if 2 != len(response.Frames) || 1 != len(response.Planes) {
+		t.Fatalf("Expected 2 frames and 1 plane, got %s", w.Body.String())
+	}
+	plane := response.Planes[0]
+	if "40621D" != plane.Icao {
+		t.Errorf("Expected plane 40621D, got %q", plane.Icao)
+	}
+	if !plane.HasLocation || 52.2572 != float64(int(plane.Lat*10000))/10000 || 3.9193 != float64(int(plane.Lon*10000))/10000 {
+		t.Errorf("Expected the plane at 52.2572,3.9193, got %t %0.6f,%0.6f", plane.HasLocation, plane.Lat, plane.Lon)
+	}
+	if 38000 != plane.Altitude {
+		t.Errorf("Expected an altitude of 38000, got %d", plane.Altitude)
+	}
+}
+
+func Test_decodeJsonNoPacket(t *testing.T) {
+	w := httptest.NewRecorder()
+	decodeJson(w, httptest.NewRequest(http.MethodPost, "/decode", nil))
+	if http.StatusBadRequest != w.Code || !strings.Contains(w.Body.String(), "No Packet Provided") {
+		t.Errorf("Expected a bad request, got %d: %s", w.Code, w.Body.String())
+	}
+}
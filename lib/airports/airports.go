@@ -0,0 +1,239 @@
+package airports
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"plane.watch/lib/geo"
+	"plane.watch/lib/internal/datafile"
+)
+
+type (
+	// Airport is one row of an OurAirports style airports.csv, with its runways
+	Airport struct {
+		Ident        string // ICAO code where there is one
+		Type         string // small_airport, medium_airport, large_airport, heliport, seaplane_base...
+		Name         string
+		Lat, Lon     float64
+		Elevation    int // feet
+		Country      string
+		Municipality string
+		IataCode     string
+		Runways      []*Runway
+	}
+
+	// Runway is one row of an OurAirports style runways.csv, it has an end for each direction it can be used in
+	Runway struct {
+		Length, Width int // feet
+		Surface       string
+		Ends          [2]RunwayEnd
+	}
+
+	// RunwayEnd is where a runway starts when it is used in one direction, e.g. 03 or 21
+	RunwayEnd struct {
+		Ident       string
+		Lat, Lon    float64
+		HasLocation bool
+		Heading     float64 // degrees true, the direction aircraft roll in when they use this end
+	}
+
+	// Database is a collection of airports we can search by location
+	Database struct {
+		airports []*Airport
+		byIdent  map[string]*Airport
+		byIata   map[string]*Airport
+		// grid is our airports in 1 degree squares, so we do not have to look at them all
+		grid map[gridKey][]*Airport
+	}
+
+	gridKey struct {
+		lat, lon int
+	}
+)
+
+// LoadFiles loads our airports and runways from OurAirports style CSV files, runwaysFile can be empty
+func LoadFiles(airportsFile, runwaysFile string) (*Database, error) {
+	var db *Database
+	err := datafile.ReadFile(airportsFile, func(airports io.Reader) error {
+		var err error
+		if "" == runwaysFile {
+			db, err = Load(airports, nil)
+			return err
+		}
+		return datafile.ReadFile(runwaysFile, func(runways io.Reader) error {
+			db, err = Load(airports, runways)
+			return err
+		})
+	})
+	return db, err
+}
+
+// Load reads OurAirports style airports and runways CSVs, runways can be nil. Closed airports and runways are skipped
+func Load(airports, runways io.Reader) (*Database, error) {
+	db := &Database{
+		airports: make([]*Airport, 0),
+		byIdent:  map[string]*Airport{},
+		byIata:   map[string]*Airport{},
+		grid:     map[gridKey][]*Airport{},
+	}
+
+	err := datafile.ReadCsv(airports, nil, nil, func(row datafile.Row) error {
+		if "closed" == row.Str("type") {
+			return nil
+		}
+		a := &Airport{
+			Ident:        row.Str("ident"),
+			Type:         row.Str("type"),
+			Name:         row.Str("name"),
+			Country:      row.Str("iso_country"),
+			Municipality: row.Str("municipality"),
+			IataCode:     row.Str("iata_code"),
+		}
+		var ok bool
+		if a.Lat, ok = row.Float("latitude_deg"); !ok {
+			return fmt.Errorf("airport %s does not have a latitude", a.Ident)
+		}
+		if a.Lon, ok = row.Float("longitude_deg"); !ok {
+			return fmt.Errorf("airport %s does not have a longitude", a.Ident)
+		}
+		elevation, _ := row.Float("elevation_ft")
+		a.Elevation = int(elevation)
+		db.add(a)
+		return nil
+	})
+	if nil != err {
+		return nil, fmt.Errorf("failed to load airports: %w", err)
+	}
+
+	if nil != runways {
+		err = datafile.ReadCsv(runways, nil, nil, func(row datafile.Row) error {
+			a, ok := db.byIdent[row.Str("airport_ident")]
+			if !ok || "1" == row.Str("closed") {
+				return nil
+			}
+			length, _ := row.Float("length_ft")
+			width, _ := row.Float("width_ft")
+			r := &Runway{
+				Length:  int(length),
+				Width:   int(width),
+				Surface: row.Str("surface"),
+				Ends:    [2]RunwayEnd{runwayEnd(row, "le_"), runwayEnd(row, "he_")},
+			}
+			r.fillHeadings()
+			a.Runways = append(a.Runways, r)
+			return nil
+		})
+		if nil != err {
+			return nil, fmt.Errorf("failed to load runways: %w", err)
+		}
+	}
+	return db, nil
+}
+
+func (db *Database) add(a *Airport) {
+	db.airports = append(db.airports, a)
+	db.byIdent[a.Ident] = a
+	if "" != a.IataCode {
+		db.byIata[a.IataCode] = a
+	}
+	key := gridKeyFor(a.Lat, a.Lon)
+	db.grid[key] = append(db.grid[key], a)
+}
+
+// Len is how many airports we have
+func (db *Database) Len() int {
+	if nil == db {
+		return 0
+	}
+	return len(db.airports)
+}
+
+// Airport finds an airport by its ident
+func (db *Database) Airport(ident string) (*Airport, bool) {
+	if nil == db {
+		return nil, false
+	}
+	a, ok := db.byIdent[ident]
+	return a, ok
+}
+
+// Lookup finds an airport by its ident (the ICAO code where there is one) or its IATA code
+func (db *Database) Lookup(code string) (*Airport, bool) {
+	if nil == db {
+		return nil, false
+	}
+	if a, ok := db.byIdent[code]; ok {
+		return a, true
+	}
+	a, ok := db.byIata[code]
+	return a, ok
+}
+
+// Nearby gives us the airports within metres of lat/lon, nearest first. metres should be less than 50km
+func (db *Database) Nearby(lat, lon, metres float64) []*Airport {
+	if nil == db {
+		return nil
+	}
+	type found struct {
+		a *Airport
+		d float64
+	}
+	var candidates []found
+	key := gridKeyFor(lat, lon)
+	for dLat := -1; dLat <= 1; dLat++ {
+		for dLon := -1; dLon <= 1; dLon++ {
+			lonKey := key.lon + dLon
+			// wrap around the date line
+			if lonKey < -180 {
+				lonKey += 360
+			} else if lonKey >= 180 {
+				lonKey -= 360
+			}
+			for _, a := range db.grid[gridKey{lat: key.lat + dLat, lon: lonKey}] {
+				if d := geo.Distance(lat, lon, a.Lat, a.Lon); d <= metres {
+					candidates = append(candidates, found{a: a, d: d})
+				}
+			}
+		}
+	}
+	// insertion sort, there are only ever a handful
+	for i := 1; i < len(candidates); i++ {
+		for j := i; j > 0 && candidates[j].d < candidates[j-1].d; j-- {
+			candidates[j], candidates[j-1] = candidates[j-1], candidates[j]
+		}
+	}
+	out := make([]*Airport, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.a
+	}
+	return out
+}
+
+// Nearest gives us the closest airport within metres of lat/lon
+func (db *Database) Nearest(lat, lon, metres float64) (*Airport, bool) {
+	nearby := db.Nearby(lat, lon, metres)
+	if 0 == len(nearby) {
+		return nil, false
+	}
+	return nearby[0], true
+}
+
+func gridKeyFor(lat, lon float64) gridKey {
+	return gridKey{lat: int(math.Floor(lat)), lon: int(math.Floor(lon))}
+}
+
+// runwayEnd reads one end of a runway, the columns for each end start with prefix (le_ or he_)
+func runwayEnd(row datafile.Row, prefix string) RunwayEnd {
+	end := RunwayEnd{Ident: row.Str(prefix + "ident")}
+	lat, hasLat := row.Float(prefix + "latitude_deg")
+	lon, hasLon := row.Float(prefix + "longitude_deg")
+	if hasLat && hasLon {
+		end.Lat, end.Lon, end.HasLocation = lat, lon, true
+	}
+	if heading, ok := row.Float(prefix + "heading_degT"); ok {
+		end.Heading = heading
+	} else {
+		end.Heading = -1
+	}
+	return end
+}
@@ -0,0 +1,163 @@
+package airports
+
+import (
+	"strings"
+	"testing"
+)
+
+const testAirports = `"id","ident","type","name","latitude_deg","longitude_deg","elevation_ft","continent","iso_country","iso_region","municipality","scheduled_service","gps_code","iata_code","local_code","home_link","wikipedia_link","keywords"
+26576,"YPPH","large_airport","Perth International Airport",-31.94029998779297,115.96700286865234,67,"OC","AU","AU-WA","Perth","yes","YPPH","PER",,,,
+27066,"YPJT","medium_airport","Perth Jandakot Airport",-32.09749984741211,115.88099670410156,99,"OC","AU","AU-WA","Perth","no","YPJT","JAD",,,,
+99999,"YXXX","closed","Old Perth Strip",-31.95,115.95,50,"OC","AU","AU-WA","Perth","no","YXXX",,,,,
+`
+
+const testRunways = `"id","airport_ref","airport_ident","length_ft","width_ft","surface","lighted","closed","le_ident","le_latitude_deg","le_longitude_deg","le_elevation_ft","le_heading_degT","le_displaced_threshold_ft","he_ident","he_latitude_deg","he_longitude_deg","he_elevation_ft","he_heading_degT","he_displaced_threshold_ft"
+1,26576,"YPPH",11299,148,"ASP",1,0,"03",-31.9658,115.9551,56,22,,"21",-31.9374,115.9698,67,202,
+2,26576,"YPPH",7096,148,"ASP",1,0,"06",-31.9448,115.9565,51,,,"24",-31.9360,115.9770,61,,
+3,27066,"YPJT",4301,98,"ASP",1,0,"06L",,,,,,"24R",,,,,
+4,27066,"YPJT",3000,60,"GRS",0,1,"12",,,,,,"30",,,,,
+`
+
+func loadTestDatabase(t *testing.T) *Database {
+	db, err := Load(strings.NewReader(testAirports), strings.NewReader(testRunways))
+	if nil != err {
+		t.Fatalf("Failed to load test airports: %s", err)
+	}
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	db := loadTestDatabase(t)
+	if 2 != db.Len() {
+		t.Fatalf("Expected 2 airports, got %d", db.Len())
+	}
+	if _, ok := db.Airport("YXXX"); ok {
+		t.Error("Closed airports should be skipped")
+	}
+	perth, ok := db.Airport("YPPH")
+	if !ok {
+		t.Fatal("Expected to find YPPH")
+	}
+	if "PER" != perth.IataCode || 67 != perth.Elevation || "AU" != perth.Country {
+		t.Errorf("Unexpected airport details %+v", perth)
+	}
+	if 2 != len(perth.Runways) {
+		t.Fatalf("Expected YPPH to have 2 runways, got %d", len(perth.Runways))
+	}
+	if 22 != perth.Runways[0].Ends[0].Heading || 202 != perth.Runways[0].Ends[1].Heading {
+		t.Errorf("Expected the headings from the CSV, got %0.1f/%0.1f", perth.Runways[0].Ends[0].Heading, perth.Runways[0].Ends[1].Heading)
+	}
+	// 06/24 has no headings, so they come from its ends
+	if h := perth.Runways[1].Ends[0].Heading; h < 55 || h > 70 {
+		t.Errorf("Expected runway 06 to head about 62 degrees, got %0.1f", h)
+	}
+	if h := perth.Runways[1].Ends[1].Heading; h < 235 || h > 250 {
+		t.Errorf("Expected runway 24 to head about 242 degrees, got %0.1f", h)
+	}
+
+	jandakot, _ := db.Airport("YPJT")
+	if 1 != len(jandakot.Runways) {
+		t.Fatalf("Expected the closed runway to be skipped, got %d runways", len(jandakot.Runways))
+	}
+	// no locations or headings, so they come from the idents
+	if 60 != jandakot.Runways[0].Ends[0].Heading || 240 != jandakot.Runways[0].Ends[1].Heading {
+		t.Errorf("Expected runway headings from the idents, got %0.1f/%0.1f", jandakot.Runways[0].Ends[0].Heading, jandakot.Runways[0].Ends[1].Heading)
+	}
+}
+
+func TestLoad_Bad(t *testing.T) {
+	if _, err := Load(strings.NewReader("ident,latitude_deg\nYPPH,-31.9\n"), nil); nil == err {
+		t.Error("Expected an airport without a longitude to fail")
+	}
+	if _, err := Load(strings.NewReader(""), nil); nil == err {
+		t.Error("Expected an empty airports file to fail")
+	}
+}
+
+func TestDatabase_Nearby(t *testing.T) {
+	db := loadTestDatabase(t)
+	tests := []struct {
+		name     string
+		lat, lon float64
+		metres   float64
+		want     []string
+	}{
+		{name: "On the apron", lat: -31.9400, lon: 115.9660, metres: 5000, want: []string{"YPPH"}},
+		{name: "Between them", lat: -32.02, lon: 115.92, metres: 20000, want: []string{"YPJT", "YPPH"}},
+		{name: "Out to sea", lat: -32.0, lon: 115.0, metres: 5000, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := db.Nearby(tt.lat, tt.lon, tt.metres)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d airports, got %d", len(tt.want), len(got))
+			}
+			for i, a := range got {
+				if tt.want[i] != a.Ident {
+					t.Errorf("Expected %s at %d, got %s", tt.want[i], i, a.Ident)
+				}
+			}
+		})
+	}
+
+	var nilDb *Database
+	if _, ok := nilDb.Nearest(-31.94, 115.96, 5000); ok {
+		t.Error("A nil database should not find anything")
+	}
+}
+
+func TestDatabase_Movement(t *testing.T) {
+	db := loadTestDatabase(t)
+	tests := []struct {
+		name        string
+		lat, lon    float64
+		heading     float64
+		wantAirport string
+		wantRunway  string
+	}{
+		{name: "Departing 03", lat: -31.9550, lon: 115.9607, heading: 24, wantAirport: "YPPH", wantRunway: "03"},
+		{name: "Landing 21", lat: -31.9450, lon: 115.9660, heading: 199, wantAirport: "YPPH", wantRunway: "21"},
+		{name: "Landing 24", lat: -31.9400, lon: 115.9700, heading: 240, wantAirport: "YPPH", wantRunway: "24"},
+		{name: "Crosswind", lat: -31.9550, lon: 115.9607, heading: 110, wantAirport: "YPPH"},
+		{name: "No heading", lat: -31.9550, lon: 115.9607, heading: -1, wantAirport: "YPPH"},
+		{name: "Jandakot without locations", lat: -32.0975, lon: 115.8810, heading: 245, wantAirport: "YPJT", wantRunway: "24R"},
+		{name: "Nowhere", lat: -33.0, lon: 117.0, heading: 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			airport, runway, ok := db.Movement(tt.lat, tt.lon, tt.heading, 5000)
+			if ok != ("" != tt.wantAirport) {
+				t.Fatalf("Expected to find an airport: %t, got %t", "" != tt.wantAirport, ok)
+			}
+			if !ok {
+				return
+			}
+			if tt.wantAirport != airport.Ident {
+				t.Errorf("Expected airport %s, got %s", tt.wantAirport, airport.Ident)
+			}
+			gotRunway := ""
+			if nil != runway {
+				gotRunway = runway.Ident
+			}
+			if tt.wantRunway != gotRunway {
+				t.Errorf("Expected runway %q, got %q", tt.wantRunway, gotRunway)
+			}
+		})
+	}
+}
+
+func TestDatabase_Lookup(t *testing.T) {
+	db := loadTestDatabase(t)
+	for _, code := range []string{"YPPH", "PER"} {
+		if a, ok := db.Lookup(code); !ok || "YPPH" != a.Ident {
+			t.Errorf("Expected %s to be YPPH, got %v", code, a)
+		}
+	}
+	if _, ok := db.Lookup("SYD"); ok {
+		t.Error("Did not expect to find SYD")
+	}
+	var nilDb *Database
+	if _, ok := nilDb.Lookup("PER"); ok {
+		t.Error("A nil database should not find anything")
+	}
+}
@@ -0,0 +1,130 @@
+package airports
+
+import (
+	"math"
+	"plane.watch/lib/geo"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxRunwayHeadingDifference is how far off the runway heading an aircraft can be and still be using it
+	maxRunwayHeadingDifference = 30.0
+	// maxRunwayDistance (metres) is how far from the runway centreline an aircraft can be and still be on it
+	maxRunwayDistance = 500.0
+)
+
+// fillHeadings works out any runway headings the CSV did not have, from the ends' locations or failing that
+// their idents (which are magnetic, but close enough to tell the ends apart)
+func (r *Runway) fillHeadings() {
+	le, he := &r.Ends[0], &r.Ends[1]
+	if le.Heading < 0 && le.HasLocation && he.HasLocation {
+		le.Heading = geo.Bearing(le.Lat, le.Lon, he.Lat, he.Lon)
+	}
+	if he.Heading < 0 && le.HasLocation && he.HasLocation {
+		he.Heading = geo.Bearing(he.Lat, he.Lon, le.Lat, le.Lon)
+	}
+	for _, end := range []*RunwayEnd{le, he} {
+		if end.Heading >= 0 {
+			continue
+		}
+		number := strings.TrimRight(end.Ident, "LRCGWSUT")
+		if n, err := strconv.Atoi(number); nil == err && n >= 1 && n <= 36 {
+			end.Heading = float64(n * 10)
+		}
+	}
+	// still nothing for one end? it is the opposite of the other
+	if le.Heading < 0 && he.Heading >= 0 {
+		le.Heading = math.Mod(he.Heading+180, 360)
+	}
+	if he.Heading < 0 && le.Heading >= 0 {
+		he.Heading = math.Mod(le.Heading+180, 360)
+	}
+}
+
+// distanceFrom is how far lat/lon is (in metres) from the runway centreline. We need both ends' locations
+func (r *Runway) distanceFrom(lat, lon float64) (float64, bool) {
+	le, he := r.Ends[0], r.Ends[1]
+	if !le.HasLocation || !he.HasLocation {
+		return 0, false
+	}
+	// flat earth around our point is plenty for something a few km long
+	toLocal := func(pLat, pLon float64) (x, y float64) {
+		x = (pLon - lon) * math.Pi / 180 * geo.EarthRadius * math.Cos(lat*math.Pi/180)
+		y = (pLat - lat) * math.Pi / 180 * geo.EarthRadius
+		return
+	}
+	ax, ay := toLocal(le.Lat, le.Lon)
+	bx, by := toLocal(he.Lat, he.Lon)
+	dx, dy := bx-ax, by-ay
+	lengthSq := dx*dx + dy*dy
+	t := 0.0
+	if lengthSq > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	}
+	px, py := ax+t*dx, ay+t*dy
+	return math.Sqrt(px*px + py*py), true
+}
+
+// RunwayFor works out which runway end an aircraft at lat/lon heading along heading (degrees true) is using.
+// Without runway locations we go on heading alone, without a heading (< 0) we cannot tell
+func (a *Airport) RunwayFor(lat, lon, heading float64) (*RunwayEnd, bool) {
+	if nil == a || heading < 0 {
+		return nil, false
+	}
+	var best *RunwayEnd
+	bestDistance := math.MaxFloat64
+	for _, r := range a.Runways {
+		distance, hasDistance := r.distanceFrom(lat, lon)
+		if hasDistance && distance > maxRunwayDistance {
+			continue
+		}
+		if !hasDistance {
+			// we have no idea where it is, so anything with a heading that matches is as good as the next
+			distance = maxRunwayDistance
+		}
+		for i := range r.Ends {
+			end := &r.Ends[i]
+			if end.Heading < 0 || geo.HeadingDifference(end.Heading, heading) > maxRunwayHeadingDifference {
+				continue
+			}
+			if distance < bestDistance {
+				best = end
+				bestDistance = distance
+			}
+		}
+	}
+	return best, nil != best
+}
+
+// distanceToRunways is how far lat/lon is from the closest runway we know the location of
+func (a *Airport) distanceToRunways(lat, lon float64) (float64, bool) {
+	closest := math.MaxFloat64
+	found := false
+	for _, r := range a.Runways {
+		if d, ok := r.distanceFrom(lat, lon); ok && d < closest {
+			closest = d
+			found = true
+		}
+	}
+	return closest, found
+}
+
+// Movement works out which airport (within metres of lat/lon) and runway an aircraft at lat/lon heading along
+// heading is taking off from or landing at. Airports whose runways we are on win over ones we are merely close to
+func (db *Database) Movement(lat, lon, heading, metres float64) (*Airport, *RunwayEnd, bool) {
+	nearby := db.Nearby(lat, lon, metres)
+	if 0 == len(nearby) {
+		return nil, nil, false
+	}
+	airport := nearby[0]
+	closest := math.MaxFloat64
+	for _, a := range nearby {
+		if d, ok := a.distanceToRunways(lat, lon); ok && d <= maxRunwayDistance && d < closest {
+			airport = a
+			closest = d
+		}
+	}
+	runway, _ := airport.RunwayFor(lat, lon, heading)
+	return airport, runway, true
+}
@@ -0,0 +1,203 @@
+package enrich
+
+import (
+	"errors"
+	"github.com/rs/zerolog/log"
+	"io"
+	"plane.watch/lib/dedupe"
+	"plane.watch/lib/export"
+	"plane.watch/lib/internal/datafile"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Aircraft is what an aircraft database knows about an airframe
+	Aircraft struct {
+		Icao            uint32
+		Registration    string
+		TypeCode        string // ICAO type designator, e.g. B738
+		Serial          string // the manufacturer's serial number
+		RegisteredOwner string
+		COFAOwner       string // who holds the certificate of airworthiness, the operator in most databases
+	}
+
+	// AircraftDb fills in registration, type and owner details from local CSV files of aircraft.
+	// It is safe to use from many goroutines
+	AircraftDb struct {
+		generation uint64 // incremented on every reload, so we know when a cached lookup is stale (first for alignment)
+		watcher    *datafile.Watcher
+		aircraft   atomic.Value // map[uint32]*Aircraft
+
+		// cache is the export fields of the aircraft we have recently looked up (or not found)
+		cache *dedupe.ForgetfulSyncMap
+	}
+
+	cachedAircraft struct {
+		generation                                       uint64
+		registration, typeCode, serial, owner, cofaOwner *string
+	}
+)
+
+// aircraftColumns are the names different aircraft databases use for the columns we want
+var aircraftColumns = map[string][]string{
+	"icao":         {"icao24", "icao", "hex", "icaohex", "modes", "modescode", "modescodehex", "modeshex"},
+	"registration": {"registration", "reg", "regid", "mark"},
+	"typecode":     {"typecode", "icaotype", "icaotypecode", "type"},
+	"serial":       {"serialnumber", "serial", "msn", "serialno"},
+	"owner":        {"owner", "registeredowner", "registeredholder", "registrant"},
+	"cofaowner":    {"cofaowner", "operator", "registeredoperator"},
+}
+
+// ReadAircraft reads a CSV of aircraft with a header row. It needs an ICAO address column (icao24, icao, hex...)
+// and uses the registration, typecode, serialnumber, owner and operator columns it finds
+func ReadAircraft(in io.Reader) ([]*Aircraft, error) {
+	aircraft := make([]*Aircraft, 0)
+	skipped := 0
+	err := datafile.ReadCsv(in, aircraftColumns, []string{"icao"}, func(row datafile.Row) error {
+		icao, err := strconv.ParseUint(row.Str("icao"), 16, 32)
+		if nil != err || icao > 0xFFFFFF {
+			skipped++
+			return nil
+		}
+		aircraft = append(aircraft, &Aircraft{
+			Icao:            uint32(icao),
+			Registration:    row.Str("registration"),
+			TypeCode:        row.Str("typecode"),
+			Serial:          row.Str("serial"),
+			RegisteredOwner: row.Str("owner"),
+			COFAOwner:       row.Str("cofaowner"),
+		})
+		return nil
+	})
+	if skipped > 0 {
+		log.Debug().Int("rows", skipped).Msg("Skipped aircraft without a valid ICAO address")
+	}
+	return aircraft, err
+}
+
+// NewAircraftDb loads our aircraft CSV files, an aircraft in a later file replaces the same one in an earlier file
+func NewAircraftDb(files ...string) (*AircraftDb, error) {
+	if 0 == len(files) {
+		return nil, errors.New("no aircraft database files given")
+	}
+	db := newAircraftDb()
+	db.watcher = datafile.NewWatcher("aircraft database", files, db.Reload)
+	if err := db.Reload(); nil != err {
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewAircraftDbWith gives us a database of aircraft that did not come from files, Reload does nothing
+func NewAircraftDbWith(aircraft []*Aircraft) *AircraftDb {
+	db := newAircraftDb()
+	db.watcher = datafile.NewWatcher("aircraft database", nil, db.Reload)
+	db.store(aircraft)
+	return db
+}
+
+func newAircraftDb() *AircraftDb {
+	return &AircraftDb{
+		cache: dedupe.NewForgetfulSyncMap(time.Minute, 10*time.Minute),
+	}
+}
+
+// Reload reads our files again. If any of them cannot be loaded we keep the aircraft we have
+func (db *AircraftDb) Reload() error {
+	if 0 == len(db.watcher.Files()) {
+		return nil
+	}
+	db.watcher.Stat()
+	aircraft := make([]*Aircraft, 0)
+	for _, fileName := range db.watcher.Files() {
+		err := datafile.ReadFile(fileName, func(in io.Reader) error {
+			loaded, err := ReadAircraft(in)
+			aircraft = append(aircraft, loaded...)
+			return err
+		})
+		if nil != err {
+			return err
+		}
+	}
+	db.store(aircraft)
+	log.Info().Int("aircraft", db.Len()).Strs("files", db.watcher.Files()).Msg("Loaded aircraft database")
+	return nil
+}
+
+// Watch reloads our files whenever we get a SIGHUP, and when they change if interval is more than 0
+func (db *AircraftDb) Watch(interval time.Duration) {
+	db.watcher.Watch(interval)
+}
+
+// Stop stops watching our files
+func (db *AircraftDb) Stop() {
+	db.watcher.Stop()
+}
+
+func (db *AircraftDb) store(aircraft []*Aircraft) {
+	byIcao := make(map[uint32]*Aircraft, len(aircraft))
+	for _, a := range aircraft {
+		byIcao[a.Icao] = a
+	}
+	db.aircraft.Store(byIcao)
+	atomic.AddUint64(&db.generation, 1)
+}
+
+// Len is how many aircraft we know about
+func (db *AircraftDb) Len() int {
+	if nil == db {
+		return 0
+	}
+	byIcao, _ := db.aircraft.Load().(map[uint32]*Aircraft)
+	return len(byIcao)
+}
+
+// Lookup finds an aircraft by its ICAO address in hex, e.g. 7C1BE8. Non ICAO addresses (~7C1BE8) are never found
+func (db *AircraftDb) Lookup(icao string) (*Aircraft, bool) {
+	if nil == db {
+		return nil, false
+	}
+	address, err := strconv.ParseUint(icao, 16, 32)
+	if nil != err {
+		return nil, false
+	}
+	byIcao, _ := db.aircraft.Load().(map[uint32]*Aircraft)
+	a, ok := byIcao[uint32(address)]
+	return a, ok
+}
+
+// lookupCached gives us the export fields for an aircraft, only going to the database the first time we see it
+func (db *AircraftDb) lookupCached(icao string) *cachedAircraft {
+	generation := atomic.LoadUint64(&db.generation)
+	if v, ok := db.cache.Load(icao); ok {
+		if c := v.(*cachedAircraft); generation == c.generation {
+			return c
+		}
+	}
+	c := &cachedAircraft{generation: generation}
+	if a, ok := db.Lookup(icao); ok {
+		c.registration = optional(a.Registration)
+		c.typeCode = optional(a.TypeCode)
+		c.serial = optional(a.Serial)
+		c.owner = optional(a.RegisteredOwner)
+		c.cofaOwner = optional(a.COFAOwner)
+	}
+	db.cache.Store(icao, c)
+	return c
+}
+
+// Enrich fills in the registration, type code, serial and owners of the aircraft, keeping anything already set
+func (db *AircraftDb) Enrich(loc *export.PlaneLocation) bool {
+	if nil == db || nil == loc || "" == loc.Icao {
+		return false
+	}
+	c := db.lookupCached(loc.Icao)
+	changed := fill(&loc.Registration, c.registration)
+	changed = fill(&loc.TypeCode, c.typeCode) || changed
+	changed = fill(&loc.Serial, c.serial) || changed
+	changed = fill(&loc.RegisteredOwner, c.owner) || changed
+	changed = fill(&loc.COFAOwner, c.cofaOwner) || changed
+	return changed
+}
@@ -0,0 +1,151 @@
+package enrich
+
+import (
+	"os"
+	"path/filepath"
+	"plane.watch/lib/export"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAircraft = `"icao24","registration","manufacturericao","typecode","serialnumber","operator","owner"
+"7c6db2","VH-VXB","BOEING","B738","30101","Qantas","Qantas Airways Ltd"
+"7C1BE8","VH-OQA","AIRBUS","A388","014","","Qantas Airways Ltd"
+"not hex","VH-BAD","","","","",""
+"7c0000","","","","","",""
+`
+
+func str(s string) *string {
+	return &s
+}
+
+func TestReadAircraft(t *testing.T) {
+	aircraft, err := ReadAircraft(strings.NewReader(testAircraft))
+	if nil != err {
+		t.Fatalf("Failed to read aircraft: %s", err)
+	}
+	if 3 != len(aircraft) {
+		t.Fatalf("Expected 3 aircraft, got %d", len(aircraft))
+	}
+	a := aircraft[0]
+	if 0x7C6DB2 != a.Icao || "VH-VXB" != a.Registration || "B738" != a.TypeCode || "30101" != a.Serial ||
+		"Qantas Airways Ltd" != a.RegisteredOwner || "Qantas" != a.COFAOwner {
+		t.Errorf("Unexpected aircraft %+v", a)
+	}
+
+	// different databases call their columns different things
+	aircraft, err = ReadAircraft(strings.NewReader("Mode S Code (hex),Reg,ICAO Type,MSN\n7C1BE8,VH-OQA,A388,014\n"))
+	if nil != err || 1 != len(aircraft) || "VH-OQA" != aircraft[0].Registration || "A388" != aircraft[0].TypeCode {
+		t.Errorf("Failed to read aircraft with different column names: %v %+v", err, aircraft)
+	}
+
+	if _, err = ReadAircraft(strings.NewReader("registration,typecode\nVH-VXB,B738\n")); nil == err {
+		t.Error("Expected a CSV without an ICAO column to fail")
+	}
+}
+
+func TestAircraftDb_Enrich(t *testing.T) {
+	aircraft, err := ReadAircraft(strings.NewReader(testAircraft))
+	if nil != err {
+		t.Fatalf("Failed to read aircraft: %s", err)
+	}
+	db := NewAircraftDbWith(aircraft)
+
+	tests := []struct {
+		name    string
+		loc     export.PlaneLocation
+		changed bool
+		reg     string
+		owner   string
+		cofa    string
+	}{
+		{name: "Known", loc: export.PlaneLocation{Icao: "7C6DB2"}, changed: true, reg: "VH-VXB", owner: "Qantas Airways Ltd", cofa: "Qantas"},
+		{name: "No operator", loc: export.PlaneLocation{Icao: "7C1BE8"}, changed: true, reg: "VH-OQA", owner: "Qantas Airways Ltd"},
+		{name: "Keeps what we decoded", loc: export.PlaneLocation{Icao: "7C6DB2", Registration: str("VH-ABC")}, changed: true, reg: "VH-ABC", owner: "Qantas Airways Ltd", cofa: "Qantas"},
+		{name: "Nothing known", loc: export.PlaneLocation{Icao: "7C0000"}},
+		{name: "Unknown", loc: export.PlaneLocation{Icao: "7C0001"}},
+		{name: "Not an ICAO address", loc: export.PlaneLocation{Icao: "~7C6DB2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// twice, so the second one comes from the cache
+			for i := 0; i < 2; i++ {
+				loc := tt.loc
+				if changed := db.Enrich(&loc); changed != tt.changed {
+					t.Errorf("Enrich() = %t, want %t", changed, tt.changed)
+				}
+				if got := deref(loc.Registration); got != tt.reg {
+					t.Errorf("Registration = %q, want %q", got, tt.reg)
+				}
+				if got := deref(loc.RegisteredOwner); got != tt.owner {
+					t.Errorf("RegisteredOwner = %q, want %q", got, tt.owner)
+				}
+				if got := deref(loc.COFAOwner); got != tt.cofa {
+					t.Errorf("COFAOwner = %q, want %q", got, tt.cofa)
+				}
+			}
+		})
+	}
+
+	var nilDb *AircraftDb
+	if nilDb.Enrich(&export.PlaneLocation{Icao: "7C6DB2"}) {
+		t.Error("A nil database should not enrich anything")
+	}
+}
+
+func TestAircraftDb_Reload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "aircraft.csv")
+	if err := os.WriteFile(file, []byte(testAircraft), 0644); nil != err {
+		t.Fatal(err)
+	}
+	db, err := NewAircraftDb(file)
+	if nil != err {
+		t.Fatalf("Failed to load the aircraft database: %s", err)
+	}
+	if 3 != db.Len() {
+		t.Fatalf("Expected 3 aircraft, got %d", db.Len())
+	}
+	loc := export.PlaneLocation{Icao: "7C6DB2"}
+	if !db.Enrich(&loc) || "VH-VXB" != deref(loc.Registration) {
+		t.Fatalf("Expected to find VH-VXB, got %q", deref(loc.Registration))
+	}
+
+	if err = os.WriteFile(file, []byte("icao24,registration\n7C6DB2,VH-XZA\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	// make sure the change is noticed on file systems with coarse timestamps
+	_ = os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if !db.watcher.Changed() {
+		t.Error("Expected the database to notice the file changed")
+	}
+	if err = db.Reload(); nil != err {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	// the cached lookup from before the reload must not be used
+	loc = export.PlaneLocation{Icao: "7C6DB2"}
+	if !db.Enrich(&loc) || "VH-XZA" != deref(loc.Registration) || nil != loc.TypeCode {
+		t.Errorf("Expected the reloaded registration VH-XZA and no type, got %q %v", deref(loc.Registration), loc.TypeCode)
+	}
+
+	if err = os.WriteFile(file, []byte("registration\nVH-XZA\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err = db.Reload(); nil == err {
+		t.Error("Expected a bad file to fail to reload")
+	}
+	if 1 != db.Len() {
+		t.Errorf("Expected to keep our aircraft after a failed reload, got %d", db.Len())
+	}
+
+	enrichers := Enrichers{db}
+	enrichers.Stop()
+	enrichers.Stop()
+}
+
+func deref(s *string) string {
+	if nil == s {
+		return ""
+	}
+	return *s
+}
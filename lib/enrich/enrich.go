@@ -0,0 +1,58 @@
+package enrich
+
+import (
+	"plane.watch/lib/export"
+)
+
+type (
+	// Enricher adds what it knows about an aircraft to a location update before it is published
+	Enricher interface {
+		// Enrich fills in the fields it knows about, it returns true if it changed anything
+		Enrich(loc *export.PlaneLocation) bool
+	}
+
+	// Enrichers are run one after the other
+	Enrichers []Enricher
+
+	stopper interface {
+		Stop()
+	}
+)
+
+// Enrich runs the location update through each of our enrichers, true if any of them changed it
+func (e Enrichers) Enrich(loc *export.PlaneLocation) bool {
+	if nil == loc {
+		return false
+	}
+	changed := false
+	for _, enricher := range e {
+		changed = enricher.Enrich(loc) || changed
+	}
+	return changed
+}
+
+// Stop stops any of our enrichers that are watching their files for changes
+func (e Enrichers) Stop() {
+	for _, enricher := range e {
+		if s, ok := enricher.(stopper); ok {
+			s.Stop()
+		}
+	}
+}
+
+// fill sets field to value, unless we do not have a value or the field is already set
+func fill(field **string, value *string) bool {
+	if nil == value || (nil != *field && "" != **field) {
+		return false
+	}
+	*field = value
+	return true
+}
+
+// optional is nil for an empty string, so we do not publish fields we know nothing about
+func optional(s string) *string {
+	if "" == s {
+		return nil
+	}
+	return &s
+}
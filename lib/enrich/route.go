@@ -0,0 +1,350 @@
+package enrich
+
+import (
+	"errors"
+	"github.com/rs/zerolog/log"
+	"io"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/dedupe"
+	"plane.watch/lib/export"
+	"plane.watch/lib/geo"
+	"plane.watch/lib/internal/datafile"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// routeTolerance is how far (metres) an aircraft can be from a leg of its route and still be flying it,
+	// on top of routeToleranceRatio of the leg's length, as nobody flies a great circle exactly
+	routeTolerance      = 50000
+	routeToleranceRatio = 0.1
+)
+
+type (
+	// Route is where a flight goes, e.g. QFA1 goes YSSY-WSSS-EGLL
+	Route struct {
+		CallSign string // normalised by ParseCallSign, e.g. QFA1
+		Airports []string
+		Operator string // the airline's name, if the route table has it
+	}
+
+	// Airline is an airline we know the ICAO designator of
+	Airline struct {
+		Icao, Iata string
+		Name       string
+	}
+
+	// RouteDb fills in the operator and route of a flight from local CSV files of routes and airlines,
+	// only trusting a route when the aircraft is somewhere along it. It is safe to use from many goroutines
+	RouteDb struct {
+		generation               uint64 // incremented on every reload, so we know when a cached lookup is stale (first for alignment)
+		watcher                  *datafile.Watcher
+		routeFiles, airlineFiles []string
+		airports                 *airports.Database
+
+		data atomic.Value // *routeData
+
+		// cache is what we have recently worked out for a call sign, including that we know nothing
+		cache *dedupe.ForgetfulSyncMap
+	}
+
+	routeData struct {
+		routes   map[string]*Route
+		airlines map[string]*Airline
+	}
+
+	cachedRoute struct {
+		generation uint64
+		operator   *string
+		routeCode  *string
+		segments   []export.Segment
+		legs       [][2]*airports.Airport
+	}
+)
+
+// routeColumns are the names different route tables use for the columns we want.
+// A route needs a call sign (or an airline and number) and its airports (or an origin and destination)
+var routeColumns = map[string][]string{
+	"callsign":    {"callsign", "flight", "flightid"},
+	"airline":     {"airlinecode", "airline", "airlineicao", "operatoricao"},
+	"number":      {"number", "flightnumber", "flightno"},
+	"airports":    {"airportcodes", "airports", "route"},
+	"origin":      {"origin", "from", "departure"},
+	"destination": {"destination", "to", "arrival"},
+	"operator":    {"operator", "airlinename"},
+}
+
+// airlineColumns are the names different airline tables use for the columns we want
+var airlineColumns = map[string][]string{
+	"icao": {"icao", "icaocode", "designator"},
+	"iata": {"iata", "iatacode"},
+	"name": {"name", "airline"},
+}
+
+// ParseCallSign splits an airline call sign into its ICAO airline designator and flight number,
+// e.g. QFA0012 is QFA and 12. Call signs that are registrations (VHOQA, N123AB) are not something we can
+// look up, so ok is false for anything that is not 3 letters then 1 to 4 characters starting with a digit
+func ParseCallSign(callSign string) (airline, flight string, ok bool) {
+	callSign = strings.ToUpper(strings.TrimSpace(callSign))
+	if len(callSign) < 4 || len(callSign) > 7 {
+		return "", "", false
+	}
+	for i, r := range callSign {
+		isLetter := r >= 'A' && r <= 'Z'
+		isDigit := r >= '0' && r <= '9'
+		switch {
+		case i < 3 && !isLetter:
+			return "", "", false
+		case 3 == i && !isDigit:
+			return "", "", false
+		case i > 3 && !isLetter && !isDigit:
+			return "", "", false
+		}
+	}
+	flight = strings.TrimLeft(callSign[3:], "0")
+	if "" == flight || !(flight[0] >= '0' && flight[0] <= '9') {
+		// keep a zero so QFA0 stays QFA0 and QFA0A stays QFA0A
+		flight = "0" + flight
+	}
+	return callSign[:3], flight, true
+}
+
+// ReadRoutes reads a CSV of routes with a header row, like the Virtual Radar Server routes.csv
+// (Callsign, AirlineCode, Number, AirportCodes) or a simpler callsign, origin, destination table
+func ReadRoutes(in io.Reader) ([]*Route, error) {
+	routes := make([]*Route, 0)
+	skipped := 0
+	err := datafile.ReadCsv(in, routeColumns, nil, func(row datafile.Row) error {
+		airline, flight, ok := ParseCallSign(row.Str("callsign"))
+		if !ok {
+			airline, flight, ok = ParseCallSign(row.Str("airline") + row.Str("number"))
+		}
+		route := &Route{
+			CallSign: airline + flight,
+			Airports: splitAirports(row.Str("airports")),
+			Operator: row.Str("operator"),
+		}
+		if 0 == len(route.Airports) {
+			route.Airports = splitAirports(row.Str("origin") + "-" + row.Str("destination"))
+		}
+		if !ok || len(route.Airports) < 2 {
+			skipped++
+			return nil
+		}
+		routes = append(routes, route)
+		return nil
+	})
+	if nil == err && 0 == len(routes) && skipped > 0 {
+		err = errors.New("no routes found, expected callsign (or airlinecode and number) and airportcodes (or origin and destination) columns")
+	}
+	if skipped > 0 {
+		log.Debug().Int("rows", skipped).Msg("Skipped routes without a valid call sign or airports")
+	}
+	return routes, err
+}
+
+// splitAirports splits a list of airport codes, e.g. YSSY-WSSS-EGLL or YPPH YSSY
+func splitAirports(codes string) []string {
+	return strings.FieldsFunc(strings.ToUpper(codes), func(r rune) bool {
+		return '-' == r || ' ' == r || ':' == r || '>' == r || ',' == r
+	})
+}
+
+// ReadAirlines reads a CSV of airlines with a header row, like the Virtual Radar Server airlines.csv (Code, Name, ICAO, IATA)
+func ReadAirlines(in io.Reader) ([]*Airline, error) {
+	airlines := make([]*Airline, 0)
+	err := datafile.ReadCsv(in, airlineColumns, []string{"icao", "name"}, func(row datafile.Row) error {
+		icao := strings.ToUpper(row.Str("icao"))
+		if 3 != len(icao) {
+			return nil
+		}
+		airlines = append(airlines, &Airline{Icao: icao, Iata: row.Str("iata"), Name: row.Str("name")})
+		return nil
+	})
+	return airlines, err
+}
+
+// NewRouteDb loads our route and airline CSV files. airportsDb is needed for routes, to check aircraft are flying
+// the route their call sign says they are. Routes with airports it does not have are never trusted
+func NewRouteDb(airportsDb *airports.Database, routeFiles, airlineFiles []string) (*RouteDb, error) {
+	if 0 == len(routeFiles) && 0 == len(airlineFiles) {
+		return nil, errors.New("no route or airline files given")
+	}
+	if 0 != len(routeFiles) && nil == airportsDb {
+		return nil, errors.New("routes need an airports database to check aircraft are on them")
+	}
+	db := newRouteDb(airportsDb)
+	db.routeFiles, db.airlineFiles = routeFiles, airlineFiles
+	db.watcher = datafile.NewWatcher("routes", append(append([]string{}, routeFiles...), airlineFiles...), db.Reload)
+	if err := db.Reload(); nil != err {
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewRouteDbWith gives us a database of routes and airlines that did not come from files, Reload does nothing
+func NewRouteDbWith(airportsDb *airports.Database, routes []*Route, airlines []*Airline) *RouteDb {
+	db := newRouteDb(airportsDb)
+	db.watcher = datafile.NewWatcher("routes", nil, db.Reload)
+	db.store(routes, airlines)
+	return db
+}
+
+func newRouteDb(airportsDb *airports.Database) *RouteDb {
+	return &RouteDb{
+		airports: airportsDb,
+		cache:    dedupe.NewForgetfulSyncMap(time.Minute, 10*time.Minute),
+	}
+}
+
+// Reload reads our files again. If any of them cannot be loaded we keep the routes and airlines we have
+func (db *RouteDb) Reload() error {
+	if 0 == len(db.watcher.Files()) {
+		return nil
+	}
+	db.watcher.Stat()
+	routes := make([]*Route, 0)
+	for _, fileName := range db.routeFiles {
+		err := datafile.ReadFile(fileName, func(in io.Reader) error {
+			loaded, err := ReadRoutes(in)
+			routes = append(routes, loaded...)
+			return err
+		})
+		if nil != err {
+			return err
+		}
+	}
+	airlines := make([]*Airline, 0)
+	for _, fileName := range db.airlineFiles {
+		err := datafile.ReadFile(fileName, func(in io.Reader) error {
+			loaded, err := ReadAirlines(in)
+			airlines = append(airlines, loaded...)
+			return err
+		})
+		if nil != err {
+			return err
+		}
+	}
+	db.store(routes, airlines)
+	log.Info().Int("routes", len(routes)).Int("airlines", len(airlines)).Strs("files", db.watcher.Files()).Msg("Loaded routes")
+	return nil
+}
+
+// Watch reloads our files whenever we get a SIGHUP, and when they change if interval is more than 0
+func (db *RouteDb) Watch(interval time.Duration) {
+	db.watcher.Watch(interval)
+}
+
+// Stop stops watching our files
+func (db *RouteDb) Stop() {
+	db.watcher.Stop()
+}
+
+func (db *RouteDb) store(routes []*Route, airlines []*Airline) {
+	data := &routeData{
+		routes:   make(map[string]*Route, len(routes)),
+		airlines: make(map[string]*Airline, len(airlines)),
+	}
+	for _, r := range routes {
+		data.routes[r.CallSign] = r
+	}
+	for _, a := range airlines {
+		data.airlines[a.Icao] = a
+	}
+	db.data.Store(data)
+	atomic.AddUint64(&db.generation, 1)
+}
+
+// Route finds the route for a call sign, e.g. QFA0012 finds QFA12
+func (db *RouteDb) Route(callSign string) (*Route, bool) {
+	airline, flight, ok := ParseCallSign(callSign)
+	if nil == db || !ok {
+		return nil, false
+	}
+	data, _ := db.data.Load().(*routeData)
+	r, ok := data.routes[airline+flight]
+	return r, ok
+}
+
+// Airline finds an airline by its ICAO designator, e.g. QFA
+func (db *RouteDb) Airline(icao string) (*Airline, bool) {
+	if nil == db {
+		return nil, false
+	}
+	data, _ := db.data.Load().(*routeData)
+	a, ok := data.airlines[strings.ToUpper(icao)]
+	return a, ok
+}
+
+// lookupCached works out the operator and route for a call sign, only going to the database the first time we see it
+func (db *RouteDb) lookupCached(airline, flight string) *cachedRoute {
+	key := airline + flight
+	generation := atomic.LoadUint64(&db.generation)
+	if v, ok := db.cache.Load(key); ok {
+		if c := v.(*cachedRoute); generation == c.generation {
+			return c
+		}
+	}
+	c := &cachedRoute{generation: generation}
+	if a, ok := db.Airline(airline); ok {
+		c.operator = optional(a.Name)
+	}
+	if r, ok := db.Route(key); ok {
+		if nil == c.operator {
+			c.operator = optional(r.Operator)
+		}
+		c.routeCode = optional(strings.Join(r.Airports, "-"))
+		var previous *airports.Airport
+		for _, code := range r.Airports {
+			a, found := db.airports.Lookup(code)
+			if !found {
+				// we cannot check an aircraft is on a route that goes somewhere we do not know
+				c.routeCode, c.segments, c.legs = nil, nil, nil
+				break
+			}
+			c.segments = append(c.segments, export.Segment{Name: a.Name, ICAOCode: a.Ident})
+			if nil != previous {
+				c.legs = append(c.legs, [2]*airports.Airport{previous, a})
+			}
+			previous = a
+		}
+	}
+	db.cache.Store(key, c)
+	return c
+}
+
+// onRoute tells us if lat/lon is somewhere along one of the route's legs
+func (c *cachedRoute) onRoute(lat, lon float64) bool {
+	for _, leg := range c.legs {
+		from, to := leg[0], leg[1]
+		allowed := routeTolerance + routeToleranceRatio*geo.Distance(from.Lat, from.Lon, to.Lat, to.Lon)
+		if geo.DistanceFromLeg(lat, lon, from.Lat, from.Lon, to.Lat, to.Lon) <= allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Enrich fills in the operator of the flight from its call sign, and the route when the aircraft is somewhere along it.
+// Anything already set is kept
+func (db *RouteDb) Enrich(loc *export.PlaneLocation) bool {
+	if nil == db || nil == loc || nil == loc.CallSign {
+		return false
+	}
+	airline, flight, ok := ParseCallSign(*loc.CallSign)
+	if !ok {
+		return false
+	}
+	c := db.lookupCached(airline, flight)
+	changed := fill(&loc.Operator, c.operator)
+	if nil == c.routeCode || !loc.HasLocation || !c.onRoute(loc.Lat, loc.Lon) {
+		return changed
+	}
+	changed = fill(&loc.RouteCode, c.routeCode) || changed
+	if 0 == len(loc.Segments) {
+		loc.Segments = append([]export.Segment{}, c.segments...)
+		changed = true
+	}
+	return changed
+}
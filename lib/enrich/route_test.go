@@ -0,0 +1,197 @@
+package enrich
+
+import (
+	"os"
+	"path/filepath"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/export"
+	"strings"
+	"testing"
+)
+
+const testRouteAirports = `ident,type,name,latitude_deg,longitude_deg,iata_code
+YPPH,large_airport,Perth International Airport,-31.9403,115.967,PER
+YSSY,large_airport,Sydney Kingsford Smith International Airport,-33.9461,151.177,SYD
+YMML,large_airport,Melbourne International Airport,-37.6733,144.843,MEL
+`
+
+const testRoutes = `Callsign,Code,Number,AirlineCode,AirportCodes
+QFA0012,QF,12,QFA,YSSY-YPPH
+VOZ,VA,681,VOZ,MEL-PER
+JST,JQ,9,JST,YMML-YXXX
+`
+
+const testAirlines = `Code,Name,ICAO,IATA,PositioningFlightPattern,CharterFlightPattern
+QFA,Qantas,QFA,QF,,
+VOZ,Virgin Australia,VOZ,VA,,
+`
+
+func loadTestRouteDb(t *testing.T) *RouteDb {
+	airportsDb, err := airports.Load(strings.NewReader(testRouteAirports), nil)
+	if nil != err {
+		t.Fatalf("Failed to load airports: %s", err)
+	}
+	routes, err := ReadRoutes(strings.NewReader(testRoutes))
+	if nil != err {
+		t.Fatalf("Failed to read routes: %s", err)
+	}
+	airlines, err := ReadAirlines(strings.NewReader(testAirlines))
+	if nil != err {
+		t.Fatalf("Failed to read airlines: %s", err)
+	}
+	return NewRouteDbWith(airportsDb, routes, airlines)
+}
+
+func TestParseCallSign(t *testing.T) {
+	tests := []struct {
+		callSign string
+		airline  string
+		flight   string
+		ok       bool
+	}{
+		{callSign: "QFA12", airline: "QFA", flight: "12", ok: true},
+		{callSign: " qfa0012 ", airline: "QFA", flight: "12", ok: true},
+		{callSign: "QFA0", airline: "QFA", flight: "0", ok: true},
+		{callSign: "JST100A", airline: "JST", flight: "100A", ok: true},
+		{callSign: "QFA0A", airline: "QFA", flight: "0A", ok: true},
+		{callSign: "VHOQA"},
+		{callSign: "N123AB"},
+		{callSign: "QFA"},
+		{callSign: "QFA12345"},
+		{callSign: "QF-12"},
+		{callSign: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.callSign, func(t *testing.T) {
+			airline, flight, ok := ParseCallSign(tt.callSign)
+			if airline != tt.airline || flight != tt.flight || ok != tt.ok {
+				t.Errorf("ParseCallSign(%q) = %q, %q, %t, want %q, %q, %t", tt.callSign, airline, flight, ok, tt.airline, tt.flight, tt.ok)
+			}
+		})
+	}
+}
+
+func TestReadRoutes(t *testing.T) {
+	routes, err := ReadRoutes(strings.NewReader(testRoutes))
+	if nil != err {
+		t.Fatalf("Failed to read routes: %s", err)
+	}
+	if 3 != len(routes) {
+		t.Fatalf("Expected 3 routes, got %d", len(routes))
+	}
+	if "QFA12" != routes[0].CallSign || "YSSY-YPPH" != strings.Join(routes[0].Airports, "-") {
+		t.Errorf("Unexpected route %+v", routes[0])
+	}
+	// no call sign, so from the airline and number
+	if "VOZ681" != routes[1].CallSign {
+		t.Errorf("Expected VOZ681, got %+v", routes[1])
+	}
+
+	routes, err = ReadRoutes(strings.NewReader("flight,from,to,operator\nQFA12,YSSY,YPPH,Qantas\nQFA13,YPPH,\n"))
+	if nil != err || 1 != len(routes) || "YSSY-YPPH" != strings.Join(routes[0].Airports, "-") || "Qantas" != routes[0].Operator {
+		t.Errorf("Failed to read a simple route table: %v %+v", err, routes)
+	}
+
+	if _, err = ReadRoutes(strings.NewReader("registration,typecode\nVH-VXB,B738\n")); nil == err {
+		t.Error("Expected a CSV without routes to fail")
+	}
+}
+
+func TestRouteDb_Enrich(t *testing.T) {
+	db := loadTestRouteDb(t)
+	tests := []struct {
+		name      string
+		callSign  string
+		lat, lon  float64
+		noLoc     bool
+		operator  string
+		routeCode string
+	}{
+		{name: "Leaving Sydney", callSign: "QFA12", lat: -33.9, lon: 151.1, operator: "Qantas", routeCode: "YSSY-YPPH"},
+		{name: "Over the Nullarbor", callSign: "QFA12", lat: -31.5, lon: 129.0, operator: "Qantas", routeCode: "YSSY-YPPH"},
+		{name: "Arriving Perth", callSign: "QFA0012", lat: -31.95, lon: 116.0, operator: "Qantas", routeCode: "YSSY-YPPH"},
+		{name: "Nowhere near the route", callSign: "QFA12", lat: -12.4, lon: 130.9, operator: "Qantas"},
+		{name: "No location", callSign: "QFA12", noLoc: true, operator: "Qantas"},
+		{name: "IATA airports", callSign: "VOZ681", lat: -35.0, lon: 130.0, operator: "Virgin Australia", routeCode: "MEL-PER"},
+		{name: "Unknown airport", callSign: "JST9", lat: -37.6, lon: 144.8},
+		{name: "No route", callSign: "QFA1", lat: -33.9, lon: 151.1, operator: "Qantas"},
+		{name: "Not an airline", callSign: "VHOQA", lat: -33.9, lon: 151.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// twice, so the second one comes from the cache
+			for i := 0; i < 2; i++ {
+				loc := export.PlaneLocation{CallSign: str(tt.callSign), Lat: tt.lat, Lon: tt.lon, HasLocation: !tt.noLoc}
+				changed := db.Enrich(&loc)
+				if changed != ("" != tt.operator || "" != tt.routeCode) {
+					t.Errorf("Enrich() = %t", changed)
+				}
+				if got := deref(loc.Operator); got != tt.operator {
+					t.Errorf("Operator = %q, want %q", got, tt.operator)
+				}
+				if got := deref(loc.RouteCode); got != tt.routeCode {
+					t.Errorf("RouteCode = %q, want %q", got, tt.routeCode)
+				}
+				if "" != tt.routeCode && 2 != len(loc.Segments) {
+					t.Errorf("Expected 2 segments, got %+v", loc.Segments)
+				}
+				if "" == tt.routeCode && 0 != len(loc.Segments) {
+					t.Errorf("Did not expect segments, got %+v", loc.Segments)
+				}
+			}
+		})
+	}
+
+	loc := export.PlaneLocation{CallSign: str("QFA12"), Lat: -33.9, Lon: 151.1, HasLocation: true}
+	db.Enrich(&loc)
+	if "Sydney Kingsford Smith International Airport" != loc.Segments[0].Name || "YPPH" != loc.Segments[1].ICAOCode {
+		t.Errorf("Unexpected segments %+v", loc.Segments)
+	}
+}
+
+func TestRouteDb_Reload(t *testing.T) {
+	airportsDb, err := airports.Load(strings.NewReader(testRouteAirports), nil)
+	if nil != err {
+		t.Fatalf("Failed to load airports: %s", err)
+	}
+	dir := t.TempDir()
+	routesFile, airlinesFile := filepath.Join(dir, "routes.csv"), filepath.Join(dir, "airlines.csv")
+	if err = os.WriteFile(routesFile, []byte(testRoutes), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(airlinesFile, []byte(testAirlines), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if _, err = NewRouteDb(nil, []string{routesFile}, nil); nil == err {
+		t.Error("Expected to need an airports database")
+	}
+	db, err := NewRouteDb(airportsDb, []string{routesFile}, []string{airlinesFile})
+	if nil != err {
+		t.Fatalf("Failed to load routes: %s", err)
+	}
+	if r, ok := db.Route("QFA12"); !ok || "YSSY-YPPH" != strings.Join(r.Airports, "-") {
+		t.Errorf("Expected QFA12 to go YSSY-YPPH, got %+v", r)
+	}
+
+	if err = os.WriteFile(routesFile, []byte("callsign,route\nQFA12,YSSY-YMML\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err = db.Reload(); nil != err {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	loc := export.PlaneLocation{CallSign: str("QFA12"), Lat: -35.5, Lon: 148.0, HasLocation: true}
+	if !db.Enrich(&loc) || "YSSY-YMML" != deref(loc.RouteCode) {
+		t.Errorf("Expected the reloaded route YSSY-YMML, got %q", deref(loc.RouteCode))
+	}
+
+	if err = os.WriteFile(airlinesFile, []byte("icao\nQFA\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err = db.Reload(); nil == err {
+		t.Error("Expected an airlines file without names to fail to reload")
+	}
+	if a, ok := db.Airline("qfa"); !ok || "Qantas" != a.Name {
+		t.Errorf("Expected to keep our airlines after a failed reload, got %+v", a)
+	}
+	db.Stop()
+}
@@ -133,6 +133,10 @@ func (f *Filter) Handle(frame tracker.Frame, src *tracker.FrameSource) tracker.F
 }
 
 func (f *Filter) IsOk(avr *mode_s.Frame) bool {
+	if nil == avr {
+		// not a mode s frame, e.g. mode a/c
+		return false
+	}
 	if len(f.listDfType) > 0 && !bytes.Contains(f.listDfType, []byte{avr.DownLinkType()}) {
 		return false
 	}
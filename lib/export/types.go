@@ -23,17 +23,48 @@ type (
 		HasVerticalRate   bool
 		HasVelocity       bool
 		SourceTag         string
+		DataSource        string // one of ADS-B, ADS-R, TIS-B, Mode S, MLAT
+		AddressType       string
 		Squawk            string
 		Special           string
+		Emergency         *string `json:",omitempty"` // set while the aircraft is in an emergency
+		FlightPhase       string  `json:",omitempty"` // one of Ground, Takeoff, Climb, Cruise, Descent, Approach, Landing, Go Around
+		Military          bool    `json:",omitempty"` // the ICAO address is in a block used by military aircraft
 		TileLocation      string
 		TrackedSince      time.Time
 		LastMsg           time.Time
+		Estimated         bool `json:",omitempty"` // Lat, Lon and Altitude are predicted, not reported
+		Smoothed          bool `json:",omitempty"` // Lat, Lon, Altitude, Heading, Velocity and VerticalRate are Kalman filtered
 
 		SignalRssi *float64
 
 		AircraftWidth  *float32 `json:",omitempty"`
 		AircraftLength *float32 `json:",omitempty"`
 
+		// Mode S Enhanced Surveillance data
+		SelectedAltitudeMcp  *int32   `json:",omitempty"`
+		SelectedAltitudeFms  *int32   `json:",omitempty"`
+		BaroSetting          *float64 `json:",omitempty"`
+		RollAngle            *float64 `json:",omitempty"`
+		TrueTrack            *float64 `json:",omitempty"`
+		TrackRate            *float64 `json:",omitempty"`
+		GroundSpeed          *int     `json:",omitempty"`
+		TrueAirSpeed         *int     `json:",omitempty"`
+		MagneticHeading      *float64 `json:",omitempty"`
+		IndicatedAirSpeed    *int     `json:",omitempty"`
+		Mach                 *float64 `json:",omitempty"`
+		BaroVerticalRate     *int     `json:",omitempty"`
+		InertialVerticalRate *int     `json:",omitempty"`
+
+		// ADS-B Target State and Status data
+		SelectedHeading     *float64 `json:",omitempty"`
+		AutopilotEngaged    *bool    `json:",omitempty"`
+		VnavEngaged         *bool    `json:",omitempty"`
+		LnavEngaged         *bool    `json:",omitempty"`
+		AltitudeHoldEngaged *bool    `json:",omitempty"`
+		ApproachEngaged     *bool    `json:",omitempty"`
+		TcasOperational     *bool    `json:",omitempty"`
+
 		// Enrichment Plane data
 		IcaoCode        *string `json:",omitempty"`
 		Registration    *string `json:",omitempty"`
@@ -41,7 +72,7 @@ type (
 		Serial          *string `json:",omitempty"`
 		RegisteredOwner *string `json:",omitempty"`
 		COFAOwner       *string `json:",omitempty"`
-		FlagCode        *string `json:",omitempty"`
+		FlagCode        *string `json:",omitempty"` // ISO 3166-1 alpha-2 code of the country the ICAO address was allocated to
 
 		// Enrichment Route Data
 		CallSign  *string   `json:",omitempty"`
@@ -54,6 +85,123 @@ type (
 		Name     string
 		ICAOCode string
 	}
+
+	// WeatherReport is the weather an aircraft has told us about (Mode S BDS 4,4 and 4,5). it encodes to JSON
+	WeatherReport struct {
+		Icao          string
+		CallSign      *string `json:",omitempty"`
+		Bds           string
+		Lat, Lon      float64
+		HasLocation   bool
+		Altitude      int
+		AltitudeUnits string
+		SourceTag     string
+		When          time.Time
+
+		WindSpeed             *int     `json:",omitempty"` // knots
+		WindDirection         *float64 `json:",omitempty"` // degrees
+		StaticAirTemperature  *float64 `json:",omitempty"` // celsius
+		AverageStaticPressure *int     `json:",omitempty"` // hPa
+		Humidity              *float64 `json:",omitempty"` // percent
+		RadioHeight           *int     `json:",omitempty"` // feet
+
+		// Hazards are one of NIL, Light, Moderate, Severe
+		Turbulence *string `json:",omitempty"`
+		WindShear  *string `json:",omitempty"`
+		Microburst *string `json:",omitempty"`
+		Icing      *string `json:",omitempty"`
+		WakeVortex *string `json:",omitempty"`
+	}
+
+	// AcasRa is an ACAS (TCAS) Resolution Advisory an aircraft has told us about. it encodes to JSON
+	AcasRa struct {
+		Icao          string
+		CallSign      *string `json:",omitempty"`
+		Lat, Lon      float64
+		HasLocation   bool
+		Altitude      int
+		AltitudeUnits string
+		TileLocation  string
+		SourceTag     string
+		When          time.Time
+
+		Advisories     []string // the currently active resolution advisories
+		Complements    []string // what the threat has been told not to do
+		Terminated     bool
+		MultipleThreat bool
+
+		// the other aircraft, if we know who or where it is
+		ThreatIcao     *string  `json:",omitempty"`
+		ThreatCallSign *string  `json:",omitempty"`
+		ThreatLat      *float64 `json:",omitempty"`
+		ThreatLon      *float64 `json:",omitempty"`
+		ThreatAltitude *int     `json:",omitempty"` // feet
+		ThreatRange    *float64 `json:",omitempty"` // nautical miles
+		ThreatBearing  *int     `json:",omitempty"` // degrees, relative to the aircraft's heading
+	}
+
+	// Emergency is sent when an aircraft enters, changes or leaves an emergency state. it encodes to JSON
+	Emergency struct {
+		Icao          string
+		CallSign      *string `json:",omitempty"`
+		Squawk        string
+		Emergency     string // what the aircraft's emergency is now, "No Emergency" once it is over
+		Previous      string // what it was before
+		Active        bool   // false when the aircraft has left its emergency
+		Lat, Lon      float64
+		HasLocation   bool
+		Altitude      int
+		AltitudeUnits string
+		TileLocation  string
+		SourceTag     string
+		When          time.Time
+	}
+
+	// FlightPhase is sent when an aircraft moves from one phase of its flight to another. it encodes to JSON
+	FlightPhase struct {
+		Icao          string
+		CallSign      *string `json:",omitempty"`
+		Phase         string
+		Previous      string
+		Departure     bool // the aircraft has just left the ground
+		Arrival       bool // the aircraft has just come down out of the sky
+		Lat, Lon      float64
+		HasLocation   bool
+		Altitude      int
+		AltitudeUnits string
+		TileLocation  string
+		SourceTag     string
+		When          time.Time
+	}
+
+	// Movement is sent when an aircraft takes off from or lands at an airport. it encodes to JSON
+	Movement struct {
+		Icao        string
+		CallSign    *string `json:",omitempty"`
+		Kind        string  // Departure or Arrival
+		Airport     string  // the airport's ident, usually its ICAO code
+		AirportName string
+		IataCode    string `json:",omitempty"`
+		Runway      string `json:",omitempty"` // the runway end used, e.g. 03 or 16R
+		Lat, Lon    float64
+		Heading     *float64 `json:",omitempty"`
+		SourceTag   string
+		When        time.Time
+	}
+
+	// Geofence is sent when an aircraft enters, leaves or has dwelled in a geofence. it encodes to JSON
+	Geofence struct {
+		Icao      string
+		CallSign  *string `json:",omitempty"`
+		Event     string  // enter, exit or dwell
+		FenceId   string
+		FenceName string `json:",omitempty"`
+		Lat, Lon  float64
+		Altitude  *int      `json:",omitempty"` // feet
+		Entered   time.Time // when the aircraft went into the fence
+		SourceTag string    `json:",omitempty"`
+		When      time.Time
+	}
 )
 
 // Plane here gives us something to look at
@@ -0,0 +1,72 @@
+// Package geo is the great circle maths we use to work out where aircraft are, on a spherical earth
+package geo
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in metres
+const EarthRadius = 6371000.0
+
+// Distance is the great circle distance in metres between two points, using the haversine formula
+// http://en.wikipedia.org/wiki/Haversine_formula
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	la1 := lat1 * math.Pi / 180
+	la2 := lat2 * math.Pi / 180
+	dLat := la2 - la1
+	dLon := (lon2 - lon1) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(la1)*math.Cos(la2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Bearing is the initial great circle bearing (degrees true) from one point to another
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	la1 := lat1 * math.Pi / 180
+	la2 := lat2 * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dLon) * math.Cos(la2)
+	x := math.Cos(la1)*math.Sin(la2) - math.Sin(la1)*math.Cos(la2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// Destination is the point we get to travelling metres along a great circle from lat/lon on a bearing
+func Destination(lat, lon, bearing, metres float64) (float64, float64) {
+	la1 := lat * math.Pi / 180
+	lo1 := lon * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := metres / EarthRadius
+
+	la2 := math.Asin(math.Sin(la1)*math.Cos(delta) + math.Cos(la1)*math.Sin(delta)*math.Cos(theta))
+	lo2 := lo1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(la1), math.Cos(delta)-math.Sin(la1)*math.Sin(la2))
+
+	lon2 := lo2 * 180 / math.Pi
+	lon2 -= math.Floor((lon2+180.0)/360.0) * 360.0
+	return la2 * 180 / math.Pi, lon2
+}
+
+// HeadingDifference is the smallest angle between two headings
+func HeadingDifference(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// DistanceFromLeg is how far (in metres) a point is from the great circle path between two others,
+// or from the nearest of them if it is not alongside the path
+func DistanceFromLeg(lat, lon, fromLat, fromLon, toLat, toLon float64) float64 {
+	fromPoint := Distance(fromLat, fromLon, lat, lon)
+	legLength := Distance(fromLat, fromLon, toLat, toLon)
+	if 0 == legLength {
+		return fromPoint
+	}
+	toPoint, toEnd := Bearing(fromLat, fromLon, lat, lon), Bearing(fromLat, fromLon, toLat, toLon)
+	angular := fromPoint / EarthRadius
+	crossTrack := math.Asin(math.Sin(angular) * math.Sin((toPoint-toEnd)*math.Pi/180))
+	alongTrack := math.Acos(math.Max(-1, math.Min(1, math.Cos(angular)/math.Cos(crossTrack)))) * EarthRadius
+	if HeadingDifference(toPoint, toEnd) > 90 || alongTrack > legLength {
+		return math.Min(fromPoint, Distance(toLat, toLon, lat, lon))
+	}
+	return math.Abs(crossTrack) * EarthRadius
+}
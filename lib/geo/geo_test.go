@@ -0,0 +1,70 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	// Perth to Sydney
+	if got := Distance(-31.9403, 115.967, -33.9461, 151.177); math.Abs(got-3277000) > 1000 {
+		t.Errorf("Distance() = %0.0f, want about 3277km", got)
+	}
+	if got := Distance(10, 20, 10, 20); 0 != got {
+		t.Errorf("Distance() to the same point = %0.0f", got)
+	}
+}
+
+func TestDestination(t *testing.T) {
+	for _, bearing := range []float64{0, 45, 90, 180, 270} {
+		lat, lon := Destination(-31.9, 115.9, bearing, 10000)
+		if d := Distance(-31.9, 115.9, lat, lon); math.Abs(d-10000) > 1 {
+			t.Errorf("Expected to go 10000m on %0.0f, went %0.1f", bearing, d)
+		}
+		if b := Bearing(-31.9, 115.9, lat, lon); HeadingDifference(b, bearing) > 0.1 {
+			t.Errorf("Expected to go on %0.0f, went on %0.1f", bearing, b)
+		}
+	}
+	// over the date line
+	if _, lon := Destination(0, 179.99, 90, 10000); lon > -179 || lon < -180 {
+		t.Errorf("Expected to wrap around the date line, got %0.2f", lon)
+	}
+}
+
+func TestHeadingDifference(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{a: 10, b: 20, want: 10},
+		{a: 350, b: 10, want: 20},
+		{a: 10, b: 350, want: 20},
+		{a: 0, b: 180, want: 180},
+		{a: 90, b: 450, want: 0},
+	}
+	for _, tt := range tests {
+		if got := HeadingDifference(tt.a, tt.b); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("HeadingDifference(%0.1f, %0.1f) = %0.1f, want %0.1f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDistanceFromLeg(t *testing.T) {
+	// along the equator, where a degree of longitude is ~111km
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     float64
+	}{
+		{name: "On the leg", lat: 0, lon: 1, want: 0},
+		{name: "Beside the leg", lat: 0.5, lon: 1, want: 55597},
+		{name: "Before the start", lat: 0, lon: -1, want: 111195},
+		{name: "Past the end", lat: 0, lon: 3, want: 111195},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DistanceFromLeg(tt.lat, tt.lon, 0, 0, 0, 2); math.Abs(got-tt.want) > 100 {
+				t.Errorf("DistanceFromLeg() = %0.0f, want %0.0f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package geofence
+
+import (
+	"errors"
+	"github.com/rs/zerolog/log"
+	"plane.watch/lib/export"
+	"plane.watch/lib/internal/datafile"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Kind is what an aircraft did with a fence
+type Kind byte
+
+const (
+	KindEnter Kind = iota + 1
+	KindExit
+	KindDwell
+)
+
+type (
+	// Event is sent when an aircraft enters, leaves or has spent the fence's Dwell time inside a fence
+	Event struct {
+		Kind     Kind
+		Fence    *Fence
+		Icao     string
+		Lat, Lon float64
+		Altitude *float64 // feet
+		// Entered is when the aircraft went into the fence
+		Entered time.Time
+		When    time.Time
+	}
+
+	// Engine keeps track of which fences each aircraft is inside. It is safe to use from many goroutines
+	Engine struct {
+		watcher *datafile.Watcher
+		set     atomic.Value // *Set
+
+		planesLock sync.Mutex
+		planes     map[string]map[string]*presence // icao -> fence id -> presence
+
+		reloadLock sync.Mutex
+	}
+
+	presence struct {
+		entered time.Time
+		dwelled bool
+	}
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindEnter:
+		return "enter"
+	case KindExit:
+		return "exit"
+	case KindDwell:
+		return "dwell"
+	}
+	return "unknown"
+}
+
+// Export gives us the event in the form we publish it
+func (e Event) Export(callSign *string, sourceTag string) export.Geofence {
+	g := export.Geofence{
+		Icao:      e.Icao,
+		CallSign:  callSign,
+		Event:     e.Kind.String(),
+		Lat:       e.Lat,
+		Lon:       e.Lon,
+		Entered:   e.Entered.UTC(),
+		SourceTag: sourceTag,
+		When:      e.When.UTC(),
+	}
+	if nil != e.Fence {
+		g.FenceId = e.Fence.Id
+		g.FenceName = e.Fence.Name
+	}
+	if nil != e.Altitude {
+		feet := int(*e.Altitude)
+		g.Altitude = &feet
+	}
+	return g
+}
+
+// NewEngine loads the fences in our GeoJSON files
+func NewEngine(files ...string) (*Engine, error) {
+	if 0 == len(files) {
+		return nil, errors.New("no geofence files given")
+	}
+	e := &Engine{
+		planes: map[string]map[string]*presence{},
+	}
+	e.watcher = datafile.NewWatcher("geofences", files, e.Reload)
+	if err := e.Reload(); nil != err {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewEngineWithFences gives us an engine for fences that did not come from files, Reload does nothing
+func NewEngineWithFences(fences []*Fence) *Engine {
+	e := &Engine{
+		planes: map[string]map[string]*presence{},
+	}
+	e.watcher = datafile.NewWatcher("geofences", nil, e.Reload)
+	e.set.Store(NewSet(fences))
+	return e
+}
+
+// Fences are the fences we are currently checking
+func (e *Engine) Fences() *Set {
+	if nil == e {
+		return nil
+	}
+	s, _ := e.set.Load().(*Set)
+	return s
+}
+
+// Reload reads our files again. If any of them cannot be loaded we keep the fences we have
+func (e *Engine) Reload() error {
+	files := e.watcher.Files()
+	if 0 == len(files) {
+		return nil
+	}
+	e.reloadLock.Lock()
+	defer e.reloadLock.Unlock()
+	e.watcher.Stat()
+	fences := make([]*Fence, 0)
+	for _, fileName := range files {
+		loaded, err := LoadFile(fileName)
+		if nil != err {
+			return err
+		}
+		fences = append(fences, loaded...)
+	}
+	set := NewSet(fences)
+	e.set.Store(set)
+	log.Info().Int("fences", set.Len()).Strs("files", files).Msg("Loaded geofences")
+	return nil
+}
+
+// Watch reloads our fences whenever we get a SIGHUP, and when our files change if interval is more than 0
+func (e *Engine) Watch(interval time.Duration) {
+	e.watcher.Watch(interval)
+}
+
+// Stop stops us Watch()ing
+func (e *Engine) Stop() {
+	e.watcher.Stop()
+}
+
+// Update checks an aircraft's new position against our fences. altitude (feet) can be nil if we do not know it,
+// in which case we cannot tell if the aircraft has gone through a fence's floor or ceiling
+func (e *Engine) Update(icao string, lat, lon float64, altitude *float64, when time.Time) []Event {
+	set := e.Fences()
+	if nil == set {
+		return nil
+	}
+	e.planesLock.Lock()
+	defer e.planesLock.Unlock()
+
+	inside := e.planes[icao]
+	var events []Event
+	newEvent := func(kind Kind, f *Fence, entered time.Time) Event {
+		return Event{Kind: kind, Fence: f, Icao: icao, Lat: lat, Lon: lon, Altitude: altitude, Entered: entered, When: when}
+	}
+
+	// the fences we were inside, did we leave?
+	for id, p := range inside {
+		f, ok := set.Fence(id)
+		if !ok {
+			// the fence went away in a reload
+			delete(inside, id)
+			continue
+		}
+		in, known := f.Contains(lat, lon, altitude)
+		if !known {
+			in = true
+		}
+		if !in {
+			delete(inside, id)
+			events = append(events, newEvent(KindExit, f, p.entered))
+			continue
+		}
+		if f.Dwell > 0 && !p.dwelled && when.Sub(p.entered) >= f.Dwell {
+			p.dwelled = true
+			events = append(events, newEvent(KindDwell, f, p.entered))
+		}
+	}
+
+	// and did we go into any new ones?
+	for _, f := range set.near(lat, lon) {
+		if _, ok := inside[f.Id]; ok {
+			continue
+		}
+		if in, _ := f.Contains(lat, lon, altitude); !in {
+			continue
+		}
+		if nil == inside {
+			inside = map[string]*presence{}
+			e.planes[icao] = inside
+		}
+		inside[f.Id] = &presence{entered: when}
+		events = append(events, newEvent(KindEnter, f, when))
+	}
+
+	if nil != inside && 0 == len(inside) {
+		delete(e.planes, icao)
+	}
+	return events
+}
+
+// Inside gives us the ids of the fences an aircraft is inside
+func (e *Engine) Inside(icao string) []string {
+	e.planesLock.Lock()
+	defer e.planesLock.Unlock()
+	ids := make([]string, 0, len(e.planes[icao]))
+	for id := range e.planes[icao] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Forget drops what we know about an aircraft, e.g. when we lose track of it. We do not send exit events because
+// we do not know where it went
+func (e *Engine) Forget(icao string) {
+	e.planesLock.Lock()
+	defer e.planesLock.Unlock()
+	delete(e.planes, icao)
+}
@@ -0,0 +1,186 @@
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxIndexCells is how many 1 degree cells a fence can cover before we stop indexing it and check it every time
+const maxIndexCells = 400
+
+type (
+	// Fence is an area (and optionally a band of altitudes) we want to know when aircraft go in and out of.
+	// Polygons do not cross the anti-meridian
+	Fence struct {
+		Id   string
+		Name string
+		// Floor and Ceiling (feet) limit the altitudes the fence covers, nil means there is no limit
+		Floor, Ceiling *float64
+		// Dwell is how long an aircraft has to stay inside before we send a dwell event, 0 for never
+		Dwell time.Duration
+
+		polygons []polygon
+		bounds   box
+	}
+
+	point struct {
+		lat, lon float64
+	}
+	// ring is a closed line of points
+	ring []point
+	// polygon is an outer ring followed by the holes in it
+	polygon []ring
+
+	box struct {
+		minLat, minLon, maxLat, maxLon float64
+	}
+
+	cell struct {
+		lat, lon int
+	}
+
+	// Set is a group of fences indexed so that we only have to look at the ones near an aircraft
+	Set struct {
+		fences []*Fence
+		byId   map[string]*Fence
+		grid   map[cell][]*Fence
+		// large fences cover too much to index, we check them all
+		large []*Fence
+	}
+)
+
+func (f *Fence) String() string {
+	if "" != f.Name {
+		return fmt.Sprintf("%s (%s)", f.Name, f.Id)
+	}
+	return f.Id
+}
+
+// Contains tells us if a point is inside the fence. altitude (feet) can be nil when we do not know it, in which
+// case ok is false when the fence has a floor or ceiling
+func (f *Fence) Contains(lat, lon float64, altitude *float64) (inside, ok bool) {
+	if !f.bounds.contains(lat, lon) {
+		return false, true
+	}
+	if nil != f.Floor || nil != f.Ceiling {
+		if nil == altitude {
+			return false, false
+		}
+		if (nil != f.Floor && *altitude < *f.Floor) || (nil != f.Ceiling && *altitude > *f.Ceiling) {
+			return false, true
+		}
+	}
+	for _, p := range f.polygons {
+		if p.contains(lat, lon) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func (f *Fence) boundingBox() box {
+	b := box{minLat: math.MaxFloat64, minLon: math.MaxFloat64, maxLat: -math.MaxFloat64, maxLon: -math.MaxFloat64}
+	for _, p := range f.polygons {
+		for _, pt := range p[0] {
+			b.minLat = math.Min(b.minLat, pt.lat)
+			b.minLon = math.Min(b.minLon, pt.lon)
+			b.maxLat = math.Max(b.maxLat, pt.lat)
+			b.maxLon = math.Max(b.maxLon, pt.lon)
+		}
+	}
+	return b
+}
+
+func (b box) contains(lat, lon float64) bool {
+	return lat >= b.minLat && lat <= b.maxLat && lon >= b.minLon && lon <= b.maxLon
+}
+
+func (p polygon) contains(lat, lon float64) bool {
+	if !p[0].contains(lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains is the even-odd rule, casting a ray east from our point
+func (r ring) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.lat > lat) != (b.lat > lat) && lon < (b.lon-a.lon)*(lat-a.lat)/(b.lat-a.lat)+a.lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// NewSet indexes fences, the last fence with an Id wins
+func NewSet(fences []*Fence) *Set {
+	s := &Set{
+		byId: make(map[string]*Fence, len(fences)),
+		grid: map[cell][]*Fence{},
+	}
+	for _, f := range fences {
+		s.byId[f.Id] = f
+	}
+	for _, f := range fences {
+		if s.byId[f.Id] != f {
+			continue
+		}
+		s.fences = append(s.fences, f)
+		minCell := cellFor(f.bounds.minLat, f.bounds.minLon)
+		maxCell := cellFor(f.bounds.maxLat, f.bounds.maxLon)
+		if (maxCell.lat-minCell.lat+1)*(maxCell.lon-minCell.lon+1) > maxIndexCells {
+			s.large = append(s.large, f)
+			continue
+		}
+		for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+			for lon := minCell.lon; lon <= maxCell.lon; lon++ {
+				c := cell{lat: lat, lon: lon}
+				s.grid[c] = append(s.grid[c], f)
+			}
+		}
+	}
+	return s
+}
+
+// Len is how many fences we have
+func (s *Set) Len() int {
+	if nil == s {
+		return 0
+	}
+	return len(s.fences)
+}
+
+// Fence finds a fence by its Id
+func (s *Set) Fence(id string) (*Fence, bool) {
+	if nil == s {
+		return nil, false
+	}
+	f, ok := s.byId[id]
+	return f, ok
+}
+
+// near gives us the fences that could contain lat/lon
+func (s *Set) near(lat, lon float64) []*Fence {
+	if nil == s {
+		return nil
+	}
+	indexed := s.grid[cellFor(lat, lon)]
+	if 0 == len(s.large) {
+		return indexed
+	}
+	fences := make([]*Fence, 0, len(indexed)+len(s.large))
+	fences = append(fences, indexed...)
+	return append(fences, s.large...)
+}
+
+func cellFor(lat, lon float64) cell {
+	return cell{lat: int(math.Floor(lat)), lon: int(math.Floor(lon))}
+}
@@ -0,0 +1,233 @@
+package geofence
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFences = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "id": "perth-ctr",
+      "properties": {"name": "Perth Control Zone", "ceiling": 1500},
+      "geometry": {"type": "Polygon", "coordinates": [[[115.8, -32.1], [116.1, -32.1], [116.1, -31.8], [115.8, -31.8], [115.8, -32.1]]]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"id": "wind-farm", "floor": "0", "ceiling": "5000", "dwell": "10m"},
+      "geometry": {"type": "MultiPolygon", "coordinates": [
+        [[[114.0, -29.0], [114.2, -29.0], [114.2, -28.8], [114.0, -28.8], [114.0, -29.0]]],
+        [[[114.5, -29.0], [114.7, -29.0], [114.7, -28.8], [114.5, -28.8], [114.5, -29.0]]]
+      ]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"id": "donut", "dwell": 60},
+      "geometry": {"type": "Polygon", "coordinates": [
+        [[120.0, -30.0], [121.0, -30.0], [121.0, -29.0], [120.0, -29.0], [120.0, -30.0]],
+        [[120.4, -29.6], [120.6, -29.6], [120.6, -29.4], [120.4, -29.4], [120.4, -29.6]]
+      ]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"id": "a-point"},
+      "geometry": {"type": "Point", "coordinates": [115.0, -31.0]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"id": "australia"},
+      "geometry": {"type": "Polygon", "coordinates": [[[110.0, -45.0], [155.0, -45.0], [155.0, -10.0], [110.0, -10.0], [110.0, -45.0]]]}
+    }
+  ]
+}`
+
+func loadTestFences(t *testing.T) []*Fence {
+	fences, err := Load(strings.NewReader(testFences), "test")
+	if nil != err {
+		t.Fatalf("Failed to load test fences: %s", err)
+	}
+	return fences
+}
+
+func feet(f float64) *float64 {
+	return &f
+}
+
+func TestLoad(t *testing.T) {
+	fences := loadTestFences(t)
+	if 4 != len(fences) {
+		t.Fatalf("Expected 4 fences, got %d", len(fences))
+	}
+	ctr := fences[0]
+	if "perth-ctr" != ctr.Id || "Perth Control Zone" != ctr.Name || nil != ctr.Floor || nil == ctr.Ceiling || 1500 != *ctr.Ceiling {
+		t.Errorf("Unexpected fence %+v", ctr)
+	}
+	wind := fences[1]
+	if "wind-farm" != wind.Id || nil == wind.Floor || 0 != *wind.Floor || 5000 != *wind.Ceiling || 10*time.Minute != wind.Dwell || 2 != len(wind.polygons) {
+		t.Errorf("Unexpected fence %+v", wind)
+	}
+	if time.Minute != fences[2].Dwell {
+		t.Errorf("Expected a dwell of 60 seconds, got %s", fences[2].Dwell)
+	}
+
+	bad := []string{
+		``,
+		`{"type": "Polygon", "coordinates": [[[1, 1], [2, 2]]]}`,
+		`{"type": "Feature", "properties": {"ceiling": "high"}, "geometry": {"type": "Polygon", "coordinates": [[[1, 1], [2, 2], [2, 1], [1, 1]]]}}`,
+		`{"type": "Feature", "properties": {"dwell": "forever"}, "geometry": {"type": "Polygon", "coordinates": [[[1, 1], [2, 2], [2, 1], [1, 1]]]}}`,
+		`{"coordinates": []}`,
+	}
+	for _, doc := range bad {
+		if _, err := Load(strings.NewReader(doc), "bad"); nil == err {
+			t.Errorf("Expected %q to fail to load", doc)
+		}
+	}
+}
+
+func TestFence_Contains(t *testing.T) {
+	fences := loadTestFences(t)
+	set := NewSet(fences)
+	tests := []struct {
+		name     string
+		fence    string
+		lat, lon float64
+		altitude *float64
+		inside   bool
+		ok       bool
+	}{
+		{name: "In the zone", fence: "perth-ctr", lat: -31.95, lon: 115.96, altitude: feet(1000), inside: true, ok: true},
+		{name: "Above the zone", fence: "perth-ctr", lat: -31.95, lon: 115.96, altitude: feet(3000), inside: false, ok: true},
+		{name: "No altitude", fence: "perth-ctr", lat: -31.95, lon: 115.96, inside: false, ok: false},
+		{name: "No altitude outside", fence: "perth-ctr", lat: -30.0, lon: 115.96, inside: false, ok: true},
+		{name: "First wind farm", fence: "wind-farm", lat: -28.9, lon: 114.1, altitude: feet(2000), inside: true, ok: true},
+		{name: "Between wind farms", fence: "wind-farm", lat: -28.9, lon: 114.35, altitude: feet(2000), inside: false, ok: true},
+		{name: "Second wind farm", fence: "wind-farm", lat: -28.9, lon: 114.6, altitude: feet(2000), inside: true, ok: true},
+		{name: "In the donut", fence: "donut", lat: -29.2, lon: 120.2, inside: true, ok: true},
+		{name: "In the hole", fence: "donut", lat: -29.5, lon: 120.5, inside: false, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, found := set.Fence(tt.fence)
+			if !found {
+				t.Fatalf("No fence %s", tt.fence)
+			}
+			inside, ok := f.Contains(tt.lat, tt.lon, tt.altitude)
+			if inside != tt.inside || ok != tt.ok {
+				t.Errorf("Contains() = %t, %t, want %t, %t", inside, ok, tt.inside, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSet_near(t *testing.T) {
+	set := NewSet(loadTestFences(t))
+	if 1 != len(set.large) {
+		t.Errorf("Expected the australia fence to be too big to index, got %d large fences", len(set.large))
+	}
+	ids := func(fences []*Fence) string {
+		var s []string
+		for _, f := range fences {
+			s = append(s, f.Id)
+		}
+		return strings.Join(s, ",")
+	}
+	if got := ids(set.near(-31.95, 115.96)); "perth-ctr,australia" != got {
+		t.Errorf("Expected perth-ctr and australia near Perth, got %s", got)
+	}
+	if got := ids(set.near(-25.0, 130.0)); "australia" != got {
+		t.Errorf("Expected only australia in the middle of nowhere, got %s", got)
+	}
+}
+
+func TestEngine_Update(t *testing.T) {
+	engine := NewEngineWithFences(loadTestFences(t))
+	now := time.Now()
+	type step struct {
+		lat, lon float64
+		altitude *float64
+		want     []string
+	}
+	steps := []step{
+		{lat: -33.0, lon: 114.0, altitude: feet(3000), want: []string{"enter australia"}},
+		{lat: -31.95, lon: 115.96, altitude: feet(3000), want: nil},
+		{lat: -31.95, lon: 115.96, altitude: feet(1200), want: []string{"enter perth-ctr"}},
+		{lat: -31.95, lon: 115.97, want: nil}, // no altitude, so we stay where we were
+		{lat: -31.95, lon: 116.5, altitude: feet(1200), want: []string{"exit perth-ctr"}},
+		{lat: -29.2, lon: 120.2, altitude: feet(1200), want: []string{"enter donut"}},
+		{lat: -29.3, lon: 120.2, altitude: feet(1200), want: []string{"dwell donut"}},
+		{lat: -29.2, lon: 120.3, altitude: feet(1200), want: nil},
+		{lat: -29.5, lon: 120.5, altitude: feet(1200), want: []string{"exit donut"}},
+	}
+	for i, s := range steps {
+		events := engine.Update("7C1234", s.lat, s.lon, s.altitude, now.Add(time.Duration(i)*time.Minute))
+		var got []string
+		for _, e := range events {
+			got = append(got, e.Kind.String()+" "+e.Fence.Id)
+			if "7C1234" != e.Icao || !e.When.Equal(now.Add(time.Duration(i)*time.Minute)) {
+				t.Errorf("step %d: unexpected event %+v", i, e)
+			}
+		}
+		if strings.Join(got, ";") != strings.Join(s.want, ";") {
+			t.Errorf("step %d: expected %v, got %v", i, s.want, got)
+		}
+	}
+	if inside := engine.Inside("7C1234"); 1 != len(inside) || "australia" != inside[0] {
+		t.Errorf("Expected to only be inside australia, got %v", inside)
+	}
+	engine.Forget("7C1234")
+	if inside := engine.Inside("7C1234"); 0 != len(inside) {
+		t.Errorf("Expected to have forgotten the aircraft, got %v", inside)
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "fences.geojson")
+	if err := os.WriteFile(file, []byte(testFences), 0644); nil != err {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(file)
+	if nil != err {
+		t.Fatalf("Failed to load the engine: %s", err)
+	}
+	if 4 != engine.Fences().Len() {
+		t.Fatalf("Expected 4 fences, got %d", engine.Fences().Len())
+	}
+	if 2 != len(engine.Update("7C1234", -28.9, 114.1, feet(2000), time.Now())) {
+		t.Fatal("Expected to enter the wind farm and australia")
+	}
+
+	if err = os.WriteFile(file, []byte(`{"type": "Feature", "id": "just-one", "geometry": {"type": "Polygon", "coordinates": [[[1, 1], [2, 2], [2, 1], [1, 1]]]}}`), 0644); nil != err {
+		t.Fatal(err)
+	}
+	// make sure the change is noticed on file systems with coarse timestamps
+	_ = os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if !engine.watcher.Changed() {
+		t.Error("Expected the engine to notice the file changed")
+	}
+	if err = engine.Reload(); nil != err {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	if 1 != engine.Fences().Len() {
+		t.Errorf("Expected 1 fence after reloading, got %d", engine.Fences().Len())
+	}
+	// the fence we were in has gone, so there is nothing to exit
+	if events := engine.Update("7C1234", -25.0, 130.0, feet(2000), time.Now()); 0 != len(events) {
+		t.Errorf("Did not expect events for fences that went away, got %v", events)
+	}
+
+	if err = os.WriteFile(file, []byte(`not json`), 0644); nil != err {
+		t.Fatal(err)
+	}
+	if err = engine.Reload(); nil == err {
+		t.Error("Expected a bad file to fail to reload")
+	}
+	if 1 != engine.Fences().Len() {
+		t.Errorf("Expected to keep our fence after a failed reload, got %d", engine.Fences().Len())
+	}
+}
@@ -0,0 +1,181 @@
+package geofence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+type (
+	// geoJson is enough of a GeoJSON object to find our Polygons and MultiPolygons, whether they are in a
+	// FeatureCollection, a Feature or on their own
+	geoJson struct {
+		Type        string                 `json:"type"`
+		Id          interface{}            `json:"id"`
+		Features    []geoJson              `json:"features"`
+		Geometry    *geoJson               `json:"geometry"`
+		Geometries  []geoJson              `json:"geometries"`
+		Coordinates json.RawMessage        `json:"coordinates"`
+		Properties  map[string]interface{} `json:"properties"`
+	}
+)
+
+// LoadFile reads the fences in a GeoJSON file
+func LoadFile(fileName string) ([]*Fence, error) {
+	f, err := os.Open(fileName)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	fences, err := Load(f, fileName)
+	if nil != err {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return fences, nil
+}
+
+// Load reads the fences in a GeoJSON document. Each Polygon or MultiPolygon feature is a fence, its properties can
+// have an id, a name, a floor and ceiling (feet) and dwell (seconds, or a duration like "5m"). Fences without an id
+// are named after source and where they are in the document
+func Load(r io.Reader, source string) ([]*Fence, error) {
+	var doc geoJson
+	if err := json.NewDecoder(r).Decode(&doc); nil != err {
+		return nil, err
+	}
+	fences := make([]*Fence, 0)
+	if err := doc.fences(source, nil, nil, &fences); nil != err {
+		return nil, err
+	}
+	return fences, nil
+}
+
+func (g *geoJson) fences(source string, id interface{}, properties map[string]interface{}, fences *[]*Fence) error {
+	switch g.Type {
+	case "FeatureCollection":
+		for i := range g.Features {
+			if err := g.Features[i].fences(source, nil, nil, fences); nil != err {
+				return err
+			}
+		}
+	case "Feature":
+		if nil == g.Geometry {
+			return nil
+		}
+		return g.Geometry.fences(source, g.Id, g.Properties, fences)
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			if err := g.Geometries[i].fences(source, id, properties, fences); nil != err {
+				return err
+			}
+		}
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); nil != err {
+			return fmt.Errorf("bad polygon: %w", err)
+		}
+		return addFence(source, id, properties, [][][][]float64{polygon}, fences)
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygons); nil != err {
+			return fmt.Errorf("bad multipolygon: %w", err)
+		}
+		return addFence(source, id, properties, polygons, fences)
+	case "":
+		return errors.New("not a GeoJSON object")
+	}
+	// points and lines cannot contain anything
+	return nil
+}
+
+func addFence(source string, id interface{}, properties map[string]interface{}, coordinates [][][][]float64, fences *[]*Fence) error {
+	f := &Fence{}
+	if v, ok := properties["id"]; ok {
+		id = v
+	}
+	if nil != id {
+		f.Id = fmt.Sprint(id)
+	} else {
+		f.Id = fmt.Sprintf("%s#%d", source, len(*fences))
+	}
+	if name, ok := properties["name"].(string); ok {
+		f.Name = name
+	}
+	var err error
+	if f.Floor, err = feetProperty(properties, "floor"); nil != err {
+		return fmt.Errorf("fence %s: %w", f.Id, err)
+	}
+	if f.Ceiling, err = feetProperty(properties, "ceiling"); nil != err {
+		return fmt.Errorf("fence %s: %w", f.Id, err)
+	}
+	if f.Dwell, err = dwellProperty(properties); nil != err {
+		return fmt.Errorf("fence %s: %w", f.Id, err)
+	}
+
+	for _, rings := range coordinates {
+		var p polygon
+		for _, coords := range rings {
+			if len(coords) < 3 {
+				return fmt.Errorf("fence %s has a ring with only %d points", f.Id, len(coords))
+			}
+			r := make(ring, len(coords))
+			for i, c := range coords {
+				if len(c) < 2 {
+					return fmt.Errorf("fence %s has a position without a lon and lat", f.Id)
+				}
+				r[i] = point{lon: c[0], lat: c[1]}
+			}
+			p = append(p, r)
+		}
+		if len(p) > 0 {
+			f.polygons = append(f.polygons, p)
+		}
+	}
+	if 0 == len(f.polygons) {
+		return fmt.Errorf("fence %s has no polygons", f.Id)
+	}
+	f.bounds = f.boundingBox()
+	*fences = append(*fences, f)
+	return nil
+}
+
+func feetProperty(properties map[string]interface{}, name string) (*float64, error) {
+	v, ok := properties[name]
+	if !ok || nil == v {
+		return nil, nil
+	}
+	switch value := v.(type) {
+	case float64:
+		return &value, nil
+	case string:
+		feet, err := strconv.ParseFloat(value, 64)
+		if nil != err {
+			return nil, fmt.Errorf("bad %s %q", name, value)
+		}
+		return &feet, nil
+	}
+	return nil, fmt.Errorf("bad %s %v", name, v)
+}
+
+func dwellProperty(properties map[string]interface{}) (time.Duration, error) {
+	v, ok := properties["dwell"]
+	if !ok || nil == v {
+		return 0, nil
+	}
+	switch value := v.(type) {
+	case float64:
+		return time.Duration(value * float64(time.Second)), nil
+	case string:
+		d, err := time.ParseDuration(value)
+		if nil != err {
+			return 0, fmt.Errorf("bad dwell %q", value)
+		}
+		return d, nil
+	}
+	return 0, fmt.Errorf("bad dwell %v", v)
+}
@@ -0,0 +1,236 @@
+package icao
+
+// allocations are the address blocks ICAO Annex 10 Volume III allocates to each state. China's block is split
+// around Hong Kong's so that no two blocks overlap
+var allocations = []block{
+	{start: 0x004000, end: 0x0043FF, country: Country{Name: "Zimbabwe", FlagCode: "ZW"}},
+	{start: 0x006000, end: 0x006FFF, country: Country{Name: "Mozambique", FlagCode: "MZ"}},
+	{start: 0x008000, end: 0x00FFFF, country: Country{Name: "South Africa", FlagCode: "ZA"}},
+	{start: 0x010000, end: 0x017FFF, country: Country{Name: "Egypt", FlagCode: "EG"}},
+	{start: 0x018000, end: 0x01FFFF, country: Country{Name: "Libya", FlagCode: "LY"}},
+	{start: 0x020000, end: 0x027FFF, country: Country{Name: "Morocco", FlagCode: "MA"}},
+	{start: 0x028000, end: 0x02FFFF, country: Country{Name: "Tunisia", FlagCode: "TN"}},
+	{start: 0x030000, end: 0x0303FF, country: Country{Name: "Botswana", FlagCode: "BW"}},
+	{start: 0x032000, end: 0x032FFF, country: Country{Name: "Burundi", FlagCode: "BI"}},
+	{start: 0x034000, end: 0x034FFF, country: Country{Name: "Cameroon", FlagCode: "CM"}},
+	{start: 0x035000, end: 0x0353FF, country: Country{Name: "Comoros", FlagCode: "KM"}},
+	{start: 0x036000, end: 0x036FFF, country: Country{Name: "Congo", FlagCode: "CG"}},
+	{start: 0x038000, end: 0x038FFF, country: Country{Name: "Cote d'Ivoire", FlagCode: "CI"}},
+	{start: 0x03E000, end: 0x03EFFF, country: Country{Name: "Gabon", FlagCode: "GA"}},
+	{start: 0x040000, end: 0x040FFF, country: Country{Name: "Ethiopia", FlagCode: "ET"}},
+	{start: 0x042000, end: 0x042FFF, country: Country{Name: "Equatorial Guinea", FlagCode: "GQ"}},
+	{start: 0x044000, end: 0x044FFF, country: Country{Name: "Ghana", FlagCode: "GH"}},
+	{start: 0x046000, end: 0x046FFF, country: Country{Name: "Guinea", FlagCode: "GN"}},
+	{start: 0x048000, end: 0x0483FF, country: Country{Name: "Guinea-Bissau", FlagCode: "GW"}},
+	{start: 0x04A000, end: 0x04A3FF, country: Country{Name: "Lesotho", FlagCode: "LS"}},
+	{start: 0x04C000, end: 0x04CFFF, country: Country{Name: "Kenya", FlagCode: "KE"}},
+	{start: 0x050000, end: 0x050FFF, country: Country{Name: "Liberia", FlagCode: "LR"}},
+	{start: 0x054000, end: 0x054FFF, country: Country{Name: "Madagascar", FlagCode: "MG"}},
+	{start: 0x058000, end: 0x058FFF, country: Country{Name: "Malawi", FlagCode: "MW"}},
+	{start: 0x05A000, end: 0x05A3FF, country: Country{Name: "Maldives", FlagCode: "MV"}},
+	{start: 0x05C000, end: 0x05CFFF, country: Country{Name: "Mali", FlagCode: "ML"}},
+	{start: 0x05E000, end: 0x05E3FF, country: Country{Name: "Mauritania", FlagCode: "MR"}},
+	{start: 0x060000, end: 0x0603FF, country: Country{Name: "Mauritius", FlagCode: "MU"}},
+	{start: 0x062000, end: 0x062FFF, country: Country{Name: "Niger", FlagCode: "NE"}},
+	{start: 0x064000, end: 0x064FFF, country: Country{Name: "Nigeria", FlagCode: "NG"}},
+	{start: 0x068000, end: 0x068FFF, country: Country{Name: "Uganda", FlagCode: "UG"}},
+	{start: 0x06A000, end: 0x06A3FF, country: Country{Name: "Qatar", FlagCode: "QA"}},
+	{start: 0x06C000, end: 0x06CFFF, country: Country{Name: "Central African Republic", FlagCode: "CF"}},
+	{start: 0x06E000, end: 0x06EFFF, country: Country{Name: "Rwanda", FlagCode: "RW"}},
+	{start: 0x070000, end: 0x070FFF, country: Country{Name: "Senegal", FlagCode: "SN"}},
+	{start: 0x074000, end: 0x0743FF, country: Country{Name: "Seychelles", FlagCode: "SC"}},
+	{start: 0x076000, end: 0x0763FF, country: Country{Name: "Sierra Leone", FlagCode: "SL"}},
+	{start: 0x078000, end: 0x078FFF, country: Country{Name: "Somalia", FlagCode: "SO"}},
+	{start: 0x07A000, end: 0x07A3FF, country: Country{Name: "Eswatini", FlagCode: "SZ"}},
+	{start: 0x07C000, end: 0x07CFFF, country: Country{Name: "Sudan", FlagCode: "SD"}},
+	{start: 0x080000, end: 0x080FFF, country: Country{Name: "Tanzania", FlagCode: "TZ"}},
+	{start: 0x084000, end: 0x084FFF, country: Country{Name: "Chad", FlagCode: "TD"}},
+	{start: 0x088000, end: 0x088FFF, country: Country{Name: "Togo", FlagCode: "TG"}},
+	{start: 0x08A000, end: 0x08AFFF, country: Country{Name: "Zambia", FlagCode: "ZM"}},
+	{start: 0x08C000, end: 0x08CFFF, country: Country{Name: "DR Congo", FlagCode: "CD"}},
+	{start: 0x090000, end: 0x090FFF, country: Country{Name: "Angola", FlagCode: "AO"}},
+	{start: 0x094000, end: 0x0943FF, country: Country{Name: "Benin", FlagCode: "BJ"}},
+	{start: 0x096000, end: 0x0963FF, country: Country{Name: "Cape Verde", FlagCode: "CV"}},
+	{start: 0x098000, end: 0x0983FF, country: Country{Name: "Djibouti", FlagCode: "DJ"}},
+	{start: 0x09A000, end: 0x09AFFF, country: Country{Name: "Gambia", FlagCode: "GM"}},
+	{start: 0x09C000, end: 0x09CFFF, country: Country{Name: "Burkina Faso", FlagCode: "BF"}},
+	{start: 0x09E000, end: 0x09E3FF, country: Country{Name: "Sao Tome and Principe", FlagCode: "ST"}},
+	{start: 0x0A0000, end: 0x0A7FFF, country: Country{Name: "Algeria", FlagCode: "DZ"}},
+	{start: 0x0A8000, end: 0x0A8FFF, country: Country{Name: "Bahamas", FlagCode: "BS"}},
+	{start: 0x0AA000, end: 0x0AA3FF, country: Country{Name: "Barbados", FlagCode: "BB"}},
+	{start: 0x0AB000, end: 0x0AB3FF, country: Country{Name: "Belize", FlagCode: "BZ"}},
+	{start: 0x0AC000, end: 0x0ACFFF, country: Country{Name: "Colombia", FlagCode: "CO"}},
+	{start: 0x0AE000, end: 0x0AEFFF, country: Country{Name: "Costa Rica", FlagCode: "CR"}},
+	{start: 0x0B0000, end: 0x0B0FFF, country: Country{Name: "Cuba", FlagCode: "CU"}},
+	{start: 0x0B2000, end: 0x0B2FFF, country: Country{Name: "El Salvador", FlagCode: "SV"}},
+	{start: 0x0B4000, end: 0x0B4FFF, country: Country{Name: "Guatemala", FlagCode: "GT"}},
+	{start: 0x0B6000, end: 0x0B6FFF, country: Country{Name: "Guyana", FlagCode: "GY"}},
+	{start: 0x0B8000, end: 0x0B8FFF, country: Country{Name: "Haiti", FlagCode: "HT"}},
+	{start: 0x0BA000, end: 0x0BAFFF, country: Country{Name: "Honduras", FlagCode: "HN"}},
+	{start: 0x0BC000, end: 0x0BC3FF, country: Country{Name: "Saint Vincent and the Grenadines", FlagCode: "VC"}},
+	{start: 0x0BE000, end: 0x0BEFFF, country: Country{Name: "Jamaica", FlagCode: "JM"}},
+	{start: 0x0C0000, end: 0x0C0FFF, country: Country{Name: "Nicaragua", FlagCode: "NI"}},
+	{start: 0x0C2000, end: 0x0C2FFF, country: Country{Name: "Panama", FlagCode: "PA"}},
+	{start: 0x0C4000, end: 0x0C4FFF, country: Country{Name: "Dominican Republic", FlagCode: "DO"}},
+	{start: 0x0C6000, end: 0x0C6FFF, country: Country{Name: "Trinidad and Tobago", FlagCode: "TT"}},
+	{start: 0x0C8000, end: 0x0C8FFF, country: Country{Name: "Suriname", FlagCode: "SR"}},
+	{start: 0x0CA000, end: 0x0CA3FF, country: Country{Name: "Antigua and Barbuda", FlagCode: "AG"}},
+	{start: 0x0CC000, end: 0x0CC3FF, country: Country{Name: "Grenada", FlagCode: "GD"}},
+	{start: 0x0D0000, end: 0x0D7FFF, country: Country{Name: "Mexico", FlagCode: "MX"}},
+	{start: 0x0D8000, end: 0x0DFFFF, country: Country{Name: "Venezuela", FlagCode: "VE"}},
+	{start: 0x100000, end: 0x1FFFFF, country: Country{Name: "Russia", FlagCode: "RU"}},
+	{start: 0x201000, end: 0x2013FF, country: Country{Name: "Namibia", FlagCode: "NA"}},
+	{start: 0x202000, end: 0x2023FF, country: Country{Name: "Eritrea", FlagCode: "ER"}},
+	{start: 0x300000, end: 0x33FFFF, country: Country{Name: "Italy", FlagCode: "IT"}},
+	{start: 0x340000, end: 0x37FFFF, country: Country{Name: "Spain", FlagCode: "ES"}},
+	{start: 0x380000, end: 0x3BFFFF, country: Country{Name: "France", FlagCode: "FR"}},
+	{start: 0x3C0000, end: 0x3FFFFF, country: Country{Name: "Germany", FlagCode: "DE"}},
+	{start: 0x400000, end: 0x43FFFF, country: Country{Name: "United Kingdom", FlagCode: "GB"}},
+	{start: 0x440000, end: 0x447FFF, country: Country{Name: "Austria", FlagCode: "AT"}},
+	{start: 0x448000, end: 0x44FFFF, country: Country{Name: "Belgium", FlagCode: "BE"}},
+	{start: 0x450000, end: 0x457FFF, country: Country{Name: "Bulgaria", FlagCode: "BG"}},
+	{start: 0x458000, end: 0x45FFFF, country: Country{Name: "Denmark", FlagCode: "DK"}},
+	{start: 0x460000, end: 0x467FFF, country: Country{Name: "Finland", FlagCode: "FI"}},
+	{start: 0x468000, end: 0x46FFFF, country: Country{Name: "Greece", FlagCode: "GR"}},
+	{start: 0x470000, end: 0x477FFF, country: Country{Name: "Hungary", FlagCode: "HU"}},
+	{start: 0x478000, end: 0x47FFFF, country: Country{Name: "Norway", FlagCode: "NO"}},
+	{start: 0x480000, end: 0x487FFF, country: Country{Name: "Netherlands", FlagCode: "NL"}},
+	{start: 0x488000, end: 0x48FFFF, country: Country{Name: "Poland", FlagCode: "PL"}},
+	{start: 0x490000, end: 0x497FFF, country: Country{Name: "Portugal", FlagCode: "PT"}},
+	{start: 0x498000, end: 0x49FFFF, country: Country{Name: "Czechia", FlagCode: "CZ"}},
+	{start: 0x4A0000, end: 0x4A7FFF, country: Country{Name: "Romania", FlagCode: "RO"}},
+	{start: 0x4A8000, end: 0x4AFFFF, country: Country{Name: "Sweden", FlagCode: "SE"}},
+	{start: 0x4B0000, end: 0x4B7FFF, country: Country{Name: "Switzerland", FlagCode: "CH"}},
+	{start: 0x4B8000, end: 0x4BFFFF, country: Country{Name: "Turkey", FlagCode: "TR"}},
+	{start: 0x4C0000, end: 0x4C7FFF, country: Country{Name: "Serbia", FlagCode: "RS"}},
+	{start: 0x4C8000, end: 0x4C83FF, country: Country{Name: "Cyprus", FlagCode: "CY"}},
+	{start: 0x4CA000, end: 0x4CAFFF, country: Country{Name: "Ireland", FlagCode: "IE"}},
+	{start: 0x4CC000, end: 0x4CCFFF, country: Country{Name: "Iceland", FlagCode: "IS"}},
+	{start: 0x4D0000, end: 0x4D03FF, country: Country{Name: "Luxembourg", FlagCode: "LU"}},
+	{start: 0x4D2000, end: 0x4D23FF, country: Country{Name: "Malta", FlagCode: "MT"}},
+	{start: 0x4D4000, end: 0x4D43FF, country: Country{Name: "Monaco", FlagCode: "MC"}},
+	{start: 0x500000, end: 0x5003FF, country: Country{Name: "San Marino", FlagCode: "SM"}},
+	{start: 0x501000, end: 0x5013FF, country: Country{Name: "Albania", FlagCode: "AL"}},
+	{start: 0x501C00, end: 0x501FFF, country: Country{Name: "Croatia", FlagCode: "HR"}},
+	{start: 0x502C00, end: 0x502FFF, country: Country{Name: "Latvia", FlagCode: "LV"}},
+	{start: 0x503C00, end: 0x503FFF, country: Country{Name: "Lithuania", FlagCode: "LT"}},
+	{start: 0x504C00, end: 0x504FFF, country: Country{Name: "Moldova", FlagCode: "MD"}},
+	{start: 0x505C00, end: 0x505FFF, country: Country{Name: "Slovakia", FlagCode: "SK"}},
+	{start: 0x506C00, end: 0x506FFF, country: Country{Name: "Slovenia", FlagCode: "SI"}},
+	{start: 0x507C00, end: 0x507FFF, country: Country{Name: "Uzbekistan", FlagCode: "UZ"}},
+	{start: 0x508000, end: 0x50FFFF, country: Country{Name: "Ukraine", FlagCode: "UA"}},
+	{start: 0x510000, end: 0x5103FF, country: Country{Name: "Belarus", FlagCode: "BY"}},
+	{start: 0x511000, end: 0x5113FF, country: Country{Name: "Estonia", FlagCode: "EE"}},
+	{start: 0x512000, end: 0x5123FF, country: Country{Name: "North Macedonia", FlagCode: "MK"}},
+	{start: 0x513000, end: 0x5133FF, country: Country{Name: "Bosnia and Herzegovina", FlagCode: "BA"}},
+	{start: 0x514000, end: 0x5143FF, country: Country{Name: "Georgia", FlagCode: "GE"}},
+	{start: 0x515000, end: 0x5153FF, country: Country{Name: "Tajikistan", FlagCode: "TJ"}},
+	{start: 0x516000, end: 0x5163FF, country: Country{Name: "Montenegro", FlagCode: "ME"}},
+	{start: 0x600000, end: 0x6003FF, country: Country{Name: "Armenia", FlagCode: "AM"}},
+	{start: 0x600800, end: 0x600BFF, country: Country{Name: "Azerbaijan", FlagCode: "AZ"}},
+	{start: 0x601000, end: 0x6013FF, country: Country{Name: "Kyrgyzstan", FlagCode: "KG"}},
+	{start: 0x601800, end: 0x601BFF, country: Country{Name: "Turkmenistan", FlagCode: "TM"}},
+	{start: 0x680000, end: 0x6803FF, country: Country{Name: "Bhutan", FlagCode: "BT"}},
+	{start: 0x681000, end: 0x6813FF, country: Country{Name: "Micronesia", FlagCode: "FM"}},
+	{start: 0x682000, end: 0x6823FF, country: Country{Name: "Mongolia", FlagCode: "MN"}},
+	{start: 0x683000, end: 0x6833FF, country: Country{Name: "Kazakhstan", FlagCode: "KZ"}},
+	{start: 0x684000, end: 0x6843FF, country: Country{Name: "Palau", FlagCode: "PW"}},
+	{start: 0x700000, end: 0x700FFF, country: Country{Name: "Afghanistan", FlagCode: "AF"}},
+	{start: 0x702000, end: 0x702FFF, country: Country{Name: "Bangladesh", FlagCode: "BD"}},
+	{start: 0x704000, end: 0x704FFF, country: Country{Name: "Myanmar", FlagCode: "MM"}},
+	{start: 0x706000, end: 0x706FFF, country: Country{Name: "Kuwait", FlagCode: "KW"}},
+	{start: 0x708000, end: 0x708FFF, country: Country{Name: "Laos", FlagCode: "LA"}},
+	{start: 0x70A000, end: 0x70AFFF, country: Country{Name: "Nepal", FlagCode: "NP"}},
+	{start: 0x70C000, end: 0x70C3FF, country: Country{Name: "Oman", FlagCode: "OM"}},
+	{start: 0x70E000, end: 0x70EFFF, country: Country{Name: "Cambodia", FlagCode: "KH"}},
+	{start: 0x710000, end: 0x717FFF, country: Country{Name: "Saudi Arabia", FlagCode: "SA"}},
+	{start: 0x718000, end: 0x71FFFF, country: Country{Name: "South Korea", FlagCode: "KR"}},
+	{start: 0x720000, end: 0x727FFF, country: Country{Name: "North Korea", FlagCode: "KP"}},
+	{start: 0x728000, end: 0x72FFFF, country: Country{Name: "Iraq", FlagCode: "IQ"}},
+	{start: 0x730000, end: 0x737FFF, country: Country{Name: "Iran", FlagCode: "IR"}},
+	{start: 0x738000, end: 0x73FFFF, country: Country{Name: "Israel", FlagCode: "IL"}},
+	{start: 0x740000, end: 0x747FFF, country: Country{Name: "Jordan", FlagCode: "JO"}},
+	{start: 0x748000, end: 0x74FFFF, country: Country{Name: "Lebanon", FlagCode: "LB"}},
+	{start: 0x750000, end: 0x757FFF, country: Country{Name: "Malaysia", FlagCode: "MY"}},
+	{start: 0x758000, end: 0x75FFFF, country: Country{Name: "Philippines", FlagCode: "PH"}},
+	{start: 0x760000, end: 0x767FFF, country: Country{Name: "Pakistan", FlagCode: "PK"}},
+	{start: 0x768000, end: 0x76FFFF, country: Country{Name: "Singapore", FlagCode: "SG"}},
+	{start: 0x770000, end: 0x777FFF, country: Country{Name: "Sri Lanka", FlagCode: "LK"}},
+	{start: 0x778000, end: 0x77FFFF, country: Country{Name: "Syria", FlagCode: "SY"}},
+	{start: 0x780000, end: 0x788FFF, country: Country{Name: "China", FlagCode: "CN"}},
+	{start: 0x789000, end: 0x789FFF, country: Country{Name: "Hong Kong", FlagCode: "HK"}},
+	{start: 0x78A000, end: 0x7BFFFF, country: Country{Name: "China", FlagCode: "CN"}},
+	{start: 0x7C0000, end: 0x7FFFFF, country: Country{Name: "Australia", FlagCode: "AU"}},
+	{start: 0x800000, end: 0x83FFFF, country: Country{Name: "India", FlagCode: "IN"}},
+	{start: 0x840000, end: 0x87FFFF, country: Country{Name: "Japan", FlagCode: "JP"}},
+	{start: 0x880000, end: 0x887FFF, country: Country{Name: "Thailand", FlagCode: "TH"}},
+	{start: 0x888000, end: 0x88FFFF, country: Country{Name: "Viet Nam", FlagCode: "VN"}},
+	{start: 0x890000, end: 0x890FFF, country: Country{Name: "Yemen", FlagCode: "YE"}},
+	{start: 0x894000, end: 0x894FFF, country: Country{Name: "Bahrain", FlagCode: "BH"}},
+	{start: 0x895000, end: 0x8953FF, country: Country{Name: "Brunei", FlagCode: "BN"}},
+	{start: 0x896000, end: 0x896FFF, country: Country{Name: "United Arab Emirates", FlagCode: "AE"}},
+	{start: 0x897000, end: 0x8973FF, country: Country{Name: "Solomon Islands", FlagCode: "SB"}},
+	{start: 0x898000, end: 0x898FFF, country: Country{Name: "Papua New Guinea", FlagCode: "PG"}},
+	{start: 0x899000, end: 0x8993FF, country: Country{Name: "Taiwan", FlagCode: "TW"}},
+	{start: 0x8A0000, end: 0x8A7FFF, country: Country{Name: "Indonesia", FlagCode: "ID"}},
+	{start: 0x900000, end: 0x9003FF, country: Country{Name: "Marshall Islands", FlagCode: "MH"}},
+	{start: 0x901000, end: 0x9013FF, country: Country{Name: "Cook Islands", FlagCode: "CK"}},
+	{start: 0x902000, end: 0x9023FF, country: Country{Name: "Samoa", FlagCode: "WS"}},
+	{start: 0xA00000, end: 0xAFFFFF, country: Country{Name: "United States", FlagCode: "US"}},
+	{start: 0xC00000, end: 0xC3FFFF, country: Country{Name: "Canada", FlagCode: "CA"}},
+	{start: 0xC80000, end: 0xC87FFF, country: Country{Name: "New Zealand", FlagCode: "NZ"}},
+	{start: 0xC88000, end: 0xC88FFF, country: Country{Name: "Fiji", FlagCode: "FJ"}},
+	{start: 0xC8A000, end: 0xC8A3FF, country: Country{Name: "Nauru", FlagCode: "NR"}},
+	{start: 0xC8C000, end: 0xC8C3FF, country: Country{Name: "Saint Lucia", FlagCode: "LC"}},
+	{start: 0xC8D000, end: 0xC8D3FF, country: Country{Name: "Tonga", FlagCode: "TO"}},
+	{start: 0xC8E000, end: 0xC8E3FF, country: Country{Name: "Kiribati", FlagCode: "KI"}},
+	{start: 0xC90000, end: 0xC903FF, country: Country{Name: "Vanuatu", FlagCode: "VU"}},
+	{start: 0xE00000, end: 0xE3FFFF, country: Country{Name: "Argentina", FlagCode: "AR"}},
+	{start: 0xE40000, end: 0xE7FFFF, country: Country{Name: "Brazil", FlagCode: "BR"}},
+	{start: 0xE80000, end: 0xE80FFF, country: Country{Name: "Chile", FlagCode: "CL"}},
+	{start: 0xE84000, end: 0xE84FFF, country: Country{Name: "Ecuador", FlagCode: "EC"}},
+	{start: 0xE88000, end: 0xE88FFF, country: Country{Name: "Paraguay", FlagCode: "PY"}},
+	{start: 0xE8C000, end: 0xE8CFFF, country: Country{Name: "Peru", FlagCode: "PE"}},
+	{start: 0xE90000, end: 0xE90FFF, country: Country{Name: "Uruguay", FlagCode: "UY"}},
+	{start: 0xE94000, end: 0xE94FFF, country: Country{Name: "Bolivia", FlagCode: "BO"}},
+	{start: 0xF00000, end: 0xF07FFF, country: Country{Name: "ICAO (temporary)", FlagCode: ""}},
+	{start: 0xF09000, end: 0xF093FF, country: Country{Name: "ICAO (special use)", FlagCode: ""}},
+}
+
+// military are the parts of a state's block we know are used by its military aircraft
+var military = []block{
+	{start: 0x010070, end: 0x01008F, country: Country{Name: "Egypt", FlagCode: "EG"}},
+	{start: 0x0A4000, end: 0x0A4FFF, country: Country{Name: "Algeria", FlagCode: "DZ"}},
+	{start: 0x33FF00, end: 0x33FFFF, country: Country{Name: "Italy", FlagCode: "IT"}},
+	{start: 0x350000, end: 0x37FFFF, country: Country{Name: "Spain", FlagCode: "ES"}},
+	{start: 0x3AA000, end: 0x3AFFFF, country: Country{Name: "France", FlagCode: "FR"}},
+	{start: 0x3B7000, end: 0x3BFFFF, country: Country{Name: "France", FlagCode: "FR"}},
+	{start: 0x3EA000, end: 0x3EBFFF, country: Country{Name: "Germany", FlagCode: "DE"}},
+	{start: 0x3F4000, end: 0x3FBFFF, country: Country{Name: "Germany", FlagCode: "DE"}},
+	{start: 0x400000, end: 0x40003F, country: Country{Name: "United Kingdom", FlagCode: "GB"}},
+	{start: 0x43C000, end: 0x43CFFF, country: Country{Name: "United Kingdom", FlagCode: "GB"}},
+	{start: 0x444000, end: 0x446FFF, country: Country{Name: "Austria", FlagCode: "AT"}},
+	{start: 0x44F000, end: 0x44FFFF, country: Country{Name: "Belgium", FlagCode: "BE"}},
+	{start: 0x457000, end: 0x457FFF, country: Country{Name: "Bulgaria", FlagCode: "BG"}},
+	{start: 0x45F400, end: 0x45F4FF, country: Country{Name: "Denmark", FlagCode: "DK"}},
+	{start: 0x468000, end: 0x4683FF, country: Country{Name: "Greece", FlagCode: "GR"}},
+	{start: 0x473C00, end: 0x473C0F, country: Country{Name: "Hungary", FlagCode: "HU"}},
+	{start: 0x478100, end: 0x4781FF, country: Country{Name: "Norway", FlagCode: "NO"}},
+	{start: 0x480000, end: 0x480FFF, country: Country{Name: "Netherlands", FlagCode: "NL"}},
+	{start: 0x48D800, end: 0x48D87F, country: Country{Name: "Poland", FlagCode: "PL"}},
+	{start: 0x497C00, end: 0x497CFF, country: Country{Name: "Portugal", FlagCode: "PT"}},
+	{start: 0x498420, end: 0x49842F, country: Country{Name: "Czechia", FlagCode: "CZ"}},
+	{start: 0x4B7000, end: 0x4B7FFF, country: Country{Name: "Switzerland", FlagCode: "CH"}},
+	{start: 0x4B8200, end: 0x4B82FF, country: Country{Name: "Turkey", FlagCode: "TR"}},
+	{start: 0x506F00, end: 0x506FFF, country: Country{Name: "Slovenia", FlagCode: "SI"}},
+	{start: 0x70C070, end: 0x70C07F, country: Country{Name: "Oman", FlagCode: "OM"}},
+	{start: 0x710258, end: 0x71028F, country: Country{Name: "Saudi Arabia", FlagCode: "SA"}},
+	{start: 0x710380, end: 0x71039F, country: Country{Name: "Saudi Arabia", FlagCode: "SA"}},
+	{start: 0x738A00, end: 0x738AFF, country: Country{Name: "Israel", FlagCode: "IL"}},
+	{start: 0x7CF800, end: 0x7CFAFF, country: Country{Name: "Australia", FlagCode: "AU"}},
+	{start: 0x800200, end: 0x8002FF, country: Country{Name: "India", FlagCode: "IN"}},
+	{start: 0xADF7C8, end: 0xAFFFFF, country: Country{Name: "United States", FlagCode: "US"}},
+	{start: 0xC20000, end: 0xC3FFFF, country: Country{Name: "Canada", FlagCode: "CA"}},
+	{start: 0xC87F00, end: 0xC87FFF, country: Country{Name: "New Zealand", FlagCode: "NZ"}},
+	{start: 0xE40000, end: 0xE41FFF, country: Country{Name: "Brazil", FlagCode: "BR"}},
+}
@@ -0,0 +1,49 @@
+package icao
+
+import (
+	"sort"
+)
+
+type (
+	// Country is who an ICAO address block was allocated to
+	Country struct {
+		Name     string
+		FlagCode string // ISO 3166-1 alpha-2, empty for blocks not allocated to a country
+	}
+
+	// block is a range of 24 bit addresses, blocks in a table are sorted and do not overlap
+	block struct {
+		start, end uint32
+		country    Country
+	}
+)
+
+// Lookup finds the country an ICAO address was allocated to
+func Lookup(icao uint32) (Country, bool) {
+	if b, ok := find(allocations, icao); ok {
+		return b.country, true
+	}
+	return Country{}, false
+}
+
+// FlagCode is the ISO 3166-1 alpha-2 code of the country an ICAO address was allocated to, "" if we do not know
+func FlagCode(icao uint32) string {
+	country, _ := Lookup(icao)
+	return country.FlagCode
+}
+
+// IsMilitary tells us if an ICAO address is in one of the blocks we know a country uses for military aircraft
+func IsMilitary(icao uint32) bool {
+	_, ok := find(military, icao)
+	return ok
+}
+
+func find(table []block, icao uint32) (block, bool) {
+	i := sort.Search(len(table), func(i int) bool {
+		return table[i].end >= icao
+	})
+	if i < len(table) && table[i].start <= icao {
+		return table[i], true
+	}
+	return block{}, false
+}
@@ -0,0 +1,61 @@
+package icao
+
+import "testing"
+
+func TestTablesAreSorted(t *testing.T) {
+	for name, table := range map[string][]block{"allocations": allocations, "military": military} {
+		for i, b := range table {
+			if b.end < b.start {
+				t.Errorf("%s block %06X-%06X ends before it starts", name, b.start, b.end)
+			}
+			if i > 0 && b.start <= table[i-1].end {
+				t.Errorf("%s block %06X-%06X overlaps or is before %06X-%06X", name, b.start, b.end, table[i-1].start, table[i-1].end)
+			}
+		}
+	}
+	// every military block is inside its country's allocation
+	for _, b := range military {
+		for _, icao := range []uint32{b.start, b.end} {
+			if country, _ := Lookup(icao); country != b.country {
+				t.Errorf("military block %06X-%06X for %s is allocated to %s", b.start, b.end, b.country.Name, country.Name)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		icao     uint32
+		want     string
+		flag     string
+		military bool
+	}{
+		{name: "Australian airliner", icao: 0x7C1BE8, want: "Australia", flag: "AU"},
+		{name: "RAAF", icao: 0x7CF86B, want: "Australia", flag: "AU", military: true},
+		{name: "First of the US", icao: 0xA00000, want: "United States", flag: "US"},
+		{name: "USAF", icao: 0xAE1234, want: "United States", flag: "US", military: true},
+		{name: "Hong Kong", icao: 0x789123, want: "Hong Kong", flag: "HK"},
+		{name: "China before Hong Kong", icao: 0x780A11, want: "China", flag: "CN"},
+		{name: "China after Hong Kong", icao: 0x78A0FF, want: "China", flag: "CN"},
+		{name: "Royal Air Force", icao: 0x43C6F1, want: "United Kingdom", flag: "GB", military: true},
+		{name: "ICAO temporary", icao: 0xF00001, want: "ICAO (temporary)", flag: ""},
+		{name: "Unallocated", icao: 0x000001},
+		{name: "Between blocks", icao: 0x201800},
+		{name: "Past the end", icao: 0xFFFFFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			country, ok := Lookup(tt.icao)
+			if ok != ("" != tt.want) || country.Name != tt.want {
+				t.Errorf("Lookup(%06X) = %s, %t, want %s", tt.icao, country.Name, ok, tt.want)
+			}
+			if got := FlagCode(tt.icao); got != tt.flag {
+				t.Errorf("FlagCode(%06X) = %q, want %q", tt.icao, got, tt.flag)
+			}
+			if got := IsMilitary(tt.icao); got != tt.military {
+				t.Errorf("IsMilitary(%06X) = %t, want %t", tt.icao, got, tt.military)
+			}
+		})
+	}
+}
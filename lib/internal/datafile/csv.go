@@ -0,0 +1,113 @@
+// Package datafile has what our packages that load data from local files share, reading CSVs with a header row
+// and reloading files when they change or we get a SIGHUP
+package datafile
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Row is one row of a CSV file, with its columns found by the names we use for them
+type Row struct {
+	columns map[string]int
+	values  []string
+}
+
+// Str is the value in column, empty if there is no such column
+func (r Row) Str(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// Float is the value in column as a number, false if it is empty or not a number
+func (r Row) Float(column string) (float64, bool) {
+	s := r.Str(column)
+	if "" == s {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, nil == err
+}
+
+// NormaliseColumn lets us match column names regardless of case, spaces and punctuation
+func NormaliseColumn(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// ReadFile opens a file and hands it to read
+func ReadFile(fileName string, read func(in io.Reader) error) error {
+	f, err := os.Open(fileName)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return read(f)
+}
+
+// ReadCsv calls handle with each row of a CSV that has a header row. aliases maps the names we use for columns to
+// the (normalised) names datasets use for them, the first one found is used. Without aliases, columns are found by
+// the name in the header. required columns must be in the header
+func ReadCsv(in io.Reader, aliases map[string][]string, required []string, handle func(row Row) error) error {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if nil != err {
+		return err
+	}
+	var columns map[string]int
+	if nil == aliases {
+		columns = make(map[string]int, len(header))
+		for i, name := range header {
+			columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+		}
+	} else {
+		found := make(map[string]int, len(header))
+		for i, name := range header {
+			found[NormaliseColumn(name)] = i
+		}
+		columns = make(map[string]int, len(aliases))
+		for column, names := range aliases {
+			for _, name := range names {
+				if i, ok := found[name]; ok {
+					columns[column] = i
+					break
+				}
+			}
+		}
+	}
+	for _, column := range required {
+		if _, ok := columns[column]; !ok {
+			return errors.New("no " + column + " column, expected one of " + strings.Join(aliases[column], ", "))
+		}
+	}
+
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if nil != err {
+			return err
+		}
+		if err = handle(Row{columns: columns, values: values}); nil != err {
+			return err
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package datafile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadCsv(t *testing.T) {
+	in := "\ufeffICAO 24,Reg,latitude_deg\n7C6DB2,VH-VXB,-31.9\n7C1234\n"
+	aliases := map[string][]string{"icao": {"icao24"}, "registration": {"registration", "reg"}}
+
+	var rows []string
+	err := ReadCsv(strings.NewReader(in), aliases, []string{"icao"}, func(row Row) error {
+		rows = append(rows, row.Str("icao")+"/"+row.Str("registration")+"/"+row.Str("latitude_deg"))
+		return nil
+	})
+	if nil != err || "7C6DB2/VH-VXB/,7C1234//" != strings.Join(rows, ",") {
+		t.Errorf("Unexpected rows with aliases %v: %v", rows, err)
+	}
+
+	rows = rows[:0]
+	var lats []float64
+	err = ReadCsv(strings.NewReader(in), nil, nil, func(row Row) error {
+		rows = append(rows, row.Str("ICAO 24")+"/"+row.Str("Reg"))
+		if lat, ok := row.Float("latitude_deg"); ok {
+			lats = append(lats, lat)
+		}
+		return nil
+	})
+	if nil != err || "7C6DB2/VH-VXB,7C1234/" != strings.Join(rows, ",") || 1 != len(lats) || -31.9 != lats[0] {
+		t.Errorf("Unexpected rows by header name %v %v: %v", rows, lats, err)
+	}
+
+	if err = ReadCsv(strings.NewReader(in), map[string][]string{"icao": {"hex"}}, []string{"icao"}, func(Row) error { return nil }); nil == err {
+		t.Error("Expected a missing required column to fail")
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(file, []byte("a\n1\n"), 0644); nil != err {
+		t.Fatal(err)
+	}
+	reloads := make(chan string, 10)
+	newTestWatcher := func(name string) *Watcher {
+		w := NewWatcher(name, []string{file}, func() error {
+			reloads <- name
+			return nil
+		})
+		w.Stat()
+		return w
+	}
+	first, second := newTestWatcher("first"), newTestWatcher("second")
+	if first.Changed() {
+		t.Error("Did not expect a change before we touched the file")
+	}
+	// make sure the change is noticed on file systems with coarse timestamps
+	_ = os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if !first.Changed() {
+		t.Error("Expected the watcher to notice the file changed")
+	}
+
+	// both watchers reload from the one SIGHUP
+	hup := hangUp.listen()
+	go first.Watch(0)
+	go second.Watch(0)
+	defer first.Stop()
+	defer second.Stop()
+	for deadline := time.Now().Add(time.Second); 3 != hangUp.numListeners(); {
+		if time.Now().After(deadline) {
+			t.Fatal("Watchers did not start listening")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); nil != err {
+		t.Fatal(err)
+	}
+	<-hup
+	hangUp.forget(hup)
+	got := map[string]bool{}
+	for len(got) < 2 {
+		select {
+		case name := <-reloads:
+			got[name] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Expected both watchers to reload, got %v", got)
+		}
+	}
+}
+
+func (h *hangUps) numListeners() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.listeners)
+}
@@ -0,0 +1,153 @@
+package datafile
+
+import (
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// Watcher reloads files whenever we get a SIGHUP, or they change
+	Watcher struct {
+		name   string // what we are loading, for logging
+		files  []string
+		reload func() error
+
+		lock     sync.Mutex
+		modTimes map[string]time.Time
+		stopOnce sync.Once
+		stop     chan bool
+	}
+
+	// hangUps shares our one SIGHUP registration with every Watcher
+	hangUps struct {
+		once      sync.Once
+		lock      sync.Mutex
+		listeners map[chan bool]bool
+	}
+)
+
+var hangUp = &hangUps{listeners: map[chan bool]bool{}}
+
+// NewWatcher is ready to call reload when files change. name is what we are loading, for logging
+func NewWatcher(name string, files []string, reload func() error) *Watcher {
+	return &Watcher{
+		name:     name,
+		files:    files,
+		reload:   reload,
+		modTimes: map[string]time.Time{},
+		stop:     make(chan bool),
+	}
+}
+
+// Files are the files we are watching
+func (w *Watcher) Files() []string {
+	if nil == w {
+		return nil
+	}
+	return w.files
+}
+
+// Stat remembers when our files were last modified, call it before loading them
+func (w *Watcher) Stat() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, fileName := range w.files {
+		if info, err := os.Stat(fileName); nil == err {
+			w.modTimes[fileName] = info.ModTime()
+		}
+	}
+}
+
+// Changed tells us if any of our files have been modified since we last loaded them
+func (w *Watcher) Changed() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, fileName := range w.files {
+		info, err := os.Stat(fileName)
+		if nil != err {
+			continue
+		}
+		if !info.ModTime().Equal(w.modTimes[fileName]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads our files whenever we get a SIGHUP, and when they change if interval is more than 0
+func (w *Watcher) Watch(interval time.Duration) {
+	hup := hangUp.listen()
+	defer hangUp.forget(hup)
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-hup:
+			log.Info().Str("what", w.name).Msg("Reloading")
+		case <-tick:
+			if !w.Changed() {
+				continue
+			}
+			log.Info().Str("what", w.name).Strs("files", w.files).Msg("Files have changed, reloading")
+		}
+		if err := w.reload(); nil != err {
+			log.Error().Err(err).Str("what", w.name).Msg("Failed to reload, keeping what we have")
+		}
+	}
+}
+
+// Stop stops watching our files
+func (w *Watcher) Stop() {
+	if nil == w {
+		return
+	}
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+// listen gives us a channel that is sent to on every SIGHUP, registering for the signal the first time
+func (h *hangUps) listen() chan bool {
+	h.once.Do(func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGHUP)
+		go func() {
+			for range sig {
+				h.send()
+			}
+		}()
+	})
+	c := make(chan bool, 1)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.listeners[c] = true
+	return c
+}
+
+func (h *hangUps) forget(c chan bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.listeners, c)
+}
+
+// send tells everyone listening, without waiting for anyone still busy with the last one
+func (h *hangUps) send() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for c := range h.listeners {
+		select {
+		case c <- true:
+		default:
+		}
+	}
+}
@@ -2,14 +2,26 @@ package producer
 
 import (
 	"bufio"
+	"plane.watch/lib/tracker/beast"
 	"plane.watch/lib/tracker/mode_s"
 	"time"
 )
 
 func (p *producer) avrScanner(scan *bufio.Scanner) error {
+	// frames with an @ timestamp carry the beast mlat counter
+	clock := beast.NewClock(!p.fromFiles)
 	for scan.Scan() {
 		line := scan.Text()
-		p.addFrame(mode_s.NewFrame(line, time.Now()), &p.FrameSource)
+		frame := mode_s.NewFrame(line, time.Now())
+		var recorded time.Time
+		if nil != frame && frame.BeastTicks() > 0 {
+			frame.SetTimeStamp(clock.Ticks(frame.BeastTicks(), frame.TimeStamp()))
+			recorded = frame.TimeStamp()
+		}
+		if !p.pace(recorded) {
+			continue
+		}
+		p.addFrame(frame, &p.FrameSource)
 		p.addDebug("AVR Frame: %s", line)
 		if nil != p.stats.avr {
 			p.stats.avr.Inc()
@@ -9,21 +9,18 @@ import (
 )
 
 func (p *producer) beastScanner(scan *bufio.Scanner) error {
-	lastTimeStamp := time.Duration(0)
+	// each connection (or file) gets its own clock, the mlat counter is only meaningful for the receiver that sent it
+	clock := beast.NewClock(!p.fromFiles)
 	for scan.Scan() {
 		msg := scan.Bytes()
-		p.addFrame(beast.NewFrame(msg, false), &p.FrameSource)
-
+		// a new frame for every message, the tracker decodes them on other goroutines so they cannot be reused
 		frame := beast.NewFrame(msg, false)
 		if nil == frame {
 			continue
 		}
-		if p.beastDelay {
-			currentTs := frame.BeastTicksNs()
-			if lastTimeStamp > 0 && lastTimeStamp < currentTs {
-				time.Sleep(currentTs - lastTimeStamp)
-			}
-			lastTimeStamp = currentTs
+		frame.SetTimeStamp(clock.TimeStamp(frame, time.Now()))
+		if !p.pace(frame.TimeStamp()) {
+			continue
 		}
 
 		p.AddEvent(tracker.NewFrameEvent(frame, &p.FrameSource))
@@ -1,6 +1,9 @@
 package producer
 
 import (
+	"bufio"
+	"bytes"
+	"plane.watch/lib/tracker"
 	"reflect"
 	"testing"
 )
@@ -125,3 +128,27 @@ func TestScanBeast(t *testing.T) {
 		})
 	}
 }
+
+func TestProducer_beastScanner(t *testing.T) {
+	stream := append(append(append([]byte{}, beastModeAc...), beastModeSShort...), beastModeSLong...)
+	scan := bufio.NewScanner(bytes.NewReader(stream))
+	scan.Split(ScanBeast)
+
+	p := New(WithType(Beast))
+	if err := p.beastScanner(scan); nil != err {
+		t.Fatalf("Failed to scan: %s", err)
+	}
+	p.Cleanup()
+
+	// each message is sent on exactly once
+	var raw [][]byte
+	for e := range p.out {
+		if fe, ok := e.(*tracker.FrameEvent); ok {
+			raw = append(raw, fe.Frame().Raw())
+		}
+	}
+	want := [][]byte{beastModeAc, beastModeSShort, beastModeSLong}
+	if !reflect.DeepEqual(want, raw) {
+		t.Errorf("Expected the frames %X, got %X", want, raw)
+	}
+}
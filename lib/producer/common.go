@@ -24,6 +24,7 @@ const (
 	Avr = iota
 	Beast
 	Sbs1
+	Uat
 )
 
 type (
@@ -37,13 +38,14 @@ type (
 
 		cmdChan chan int
 
-		splitter   bufio.SplitFunc
-		beastDelay bool
+		splitter  bufio.SplitFunc
+		fromFiles bool
+		replay    *replay
 
 		run func()
 
 		stats struct {
-			avr, beast, sbs1 prometheus.Counter
+			avr, beast, sbs1, uat prometheus.Counter
 		}
 
 		hasFetcher, fetcherConnected bool
@@ -64,6 +66,7 @@ func New(opts ...Option) *producer {
 		out:       make(chan tracker.Event, 100),
 		outClosed: false,
 		cmdChan:   make(chan int),
+		replay:    newReplay(),
 		run: func() {
 			println("You did not specify any sources")
 			os.Exit(1)
@@ -95,6 +98,8 @@ func producerType(in int) string {
 		return "Beast"
 	case Sbs1:
 		return "SBS1"
+	case Uat:
+		return "UAT"
 	default:
 		return "Unknown"
 	}
@@ -161,6 +166,7 @@ func WithOriginName(name string) Option {
 
 func WithFiles(filePaths []string) Option {
 	return func(p *producer) {
+		p.fromFiles = true
 		p.run = func() {
 			p.readFiles(filePaths, func(reader io.Reader, fileName string) error {
 				scanner := bufio.NewScanner(reader)
@@ -171,16 +177,19 @@ func WithFiles(filePaths []string) Option {
 	}
 }
 
+// WithBeastDelay plays files back in real time, it is the same as WithReplaySpeed(1)
 func WithBeastDelay(beastDelay bool) Option {
 	return func(p *producer) {
-		p.beastDelay = beastDelay
+		if beastDelay {
+			p.replay.speed = 1
+		}
 	}
 }
 
 func WithType(producerType int) Option {
 	return func(p *producer) {
 		switch producerType {
-		case Avr, Sbs1:
+		case Avr, Sbs1, Uat:
 			p.producerType = producerType
 			p.splitter = bufio.ScanLines
 		case Beast:
@@ -192,11 +201,12 @@ func WithType(producerType int) Option {
 	}
 }
 
-func WithPrometheusCounters(avr, beast, sbs1 prometheus.Counter) Option {
+func WithPrometheusCounters(avr, beast, sbs1, uat prometheus.Counter) Option {
 	return func(p *producer) {
 		p.stats.avr = avr
 		p.stats.beast = beast
 		p.stats.sbs1 = sbs1
+		p.stats.uat = uat
 	}
 }
 
@@ -210,6 +220,8 @@ func (p *producer) readFromScanner(scan *bufio.Scanner) error {
 		return p.sbsScanner(scan)
 	case Beast:
 		return p.beastScanner(scan)
+	case Uat:
+		return p.uatScanner(scan)
 	default:
 		return errors.New("unknown producer type")
 	}
@@ -286,36 +298,46 @@ func (p *producer) readFiles(dataFiles []string, read func(io.Reader, string) er
 	var inFile *os.File
 	var gzipFile *gzip.Reader
 	go func() {
-		for _, inFileName := range dataFiles {
-			p.addDebug("Loading lines from %s", inFileName)
-			p.FrameSource.OriginIdentifier = "file://" + inFileName
-			inFile, err = os.Open(inFileName)
-			if err != nil {
-				p.addError(fmt.Errorf("failed to open file {%s}: %s", inFileName, err))
-				continue
-			}
+		for {
+			for _, inFileName := range dataFiles {
+				if p.replay.isStopped() {
+					break
+				}
+				p.addDebug("Loading lines from %s", inFileName)
+				p.FrameSource.OriginIdentifier = "file://" + inFileName
+				inFile, err = os.Open(inFileName)
+				if err != nil {
+					p.addError(fmt.Errorf("failed to open file {%s}: %s", inFileName, err))
+					continue
+				}
+				p.replay.rewind()
 
-			isGzip := strings.ToLower(inFileName[len(inFileName)-2:]) == "gz"
-			isBzip2 := strings.ToLower(inFileName[len(inFileName)-3:]) == "bz2"
-			p.addDebug("Is Gzip? %t Is Bzip2? %t", isGzip, isBzip2)
+				isGzip := strings.ToLower(inFileName[len(inFileName)-2:]) == "gz"
+				isBzip2 := strings.ToLower(inFileName[len(inFileName)-3:]) == "bz2"
+				p.addDebug("Is Gzip? %t Is Bzip2? %t", isGzip, isBzip2)
 
-			if isGzip {
-				gzipFile, err = gzip.NewReader(inFile)
-				if nil != err {
+				if isGzip {
+					gzipFile, err = gzip.NewReader(inFile)
+					if nil != err {
+						err = read(gzipFile, inFileName)
+					}
 					err = read(gzipFile, inFileName)
+				} else if isBzip2 {
+					bzip2File := bzip2.NewReader(inFile)
+					err = read(bzip2File, inFileName)
+				} else {
+					err = read(inFile, inFileName)
+				}
+				if nil != err {
+					p.addError(err)
 				}
-				err = read(gzipFile, inFileName)
-			} else if isBzip2 {
-				bzip2File := bzip2.NewReader(inFile)
-				err = read(bzip2File, inFileName)
-			} else {
-				err = read(inFile, inFileName)
+				_ = inFile.Close()
+				p.addDebug("Finished with file %s", inFileName)
 			}
-			if nil != err {
-				p.addError(err)
+			if !p.replay.loop || p.replay.isStopped() {
+				break
 			}
-			_ = inFile.Close()
-			p.addDebug("Finished with file %s", inFileName)
+			p.addDebug("Looping back to the start")
 		}
 		p.addDebug("Done loading lines")
 		p.Cleanup()
@@ -325,6 +347,7 @@ func (p *producer) readFiles(dataFiles []string, read func(io.Reader, string) er
 		for cmd := range p.cmdChan {
 			switch cmd {
 			case cmdExit:
+				p.replay.stop()
 				return
 			}
 		}
@@ -0,0 +1,153 @@
+package producer
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// replay paces the frames we read from files by the time they were recorded
+	replay struct {
+		// speed is how many times faster than real time we play back, 0 is as fast as we can read
+		speed float64
+		// start and end limit us to the frames recorded between these times
+		start, end time.Time
+		// startOffset and endOffset limit us to the frames recorded this long after the first frame in the file
+		startOffset, endOffset time.Duration
+		// loop plays our files over and over until we are stopped
+		loop bool
+
+		// first is when the first frame in the file was recorded, paceFrom is the first frame in our window and
+		// paceWall is when we sent it
+		first, paceFrom, paceWall time.Time
+
+		stopOnce sync.Once
+		stopped  chan bool
+	}
+)
+
+func newReplay() *replay {
+	return &replay{stopped: make(chan bool)}
+}
+
+// WithReplaySpeed plays files back at speed times real time, 0 plays them as fast as we can
+func WithReplaySpeed(speed float64) Option {
+	return func(p *producer) {
+		if speed < 0 {
+			speed = 0
+		}
+		p.replay.speed = speed
+	}
+}
+
+// WithReplayWindow only plays the frames recorded between start and end. Either can be zero to leave it open.
+// Only SBS1 files record the time of day, beast and AVR timestamps are relative to when we start reading so
+// they need WithReplayOffsets
+func WithReplayWindow(start, end time.Time) Option {
+	return func(p *producer) {
+		p.replay.start = start
+		p.replay.end = end
+	}
+}
+
+// WithReplayOffsets only plays the frames recorded between start and end after the first frame in the file.
+// Either can be zero to leave it open
+func WithReplayOffsets(start, end time.Duration) Option {
+	return func(p *producer) {
+		p.replay.startOffset = start
+		p.replay.endOffset = end
+	}
+}
+
+// WithReplayLoop plays our files over and over until we are stopped
+func WithReplayLoop(loop bool) Option {
+	return func(p *producer) {
+		p.replay.loop = loop
+	}
+}
+
+// pace holds on to a frame until it is time to send it, false means we should not send it at all.
+// Frames we do not have a recorded time for (ts is zero) are sent straight away
+func (p *producer) pace(ts time.Time) bool {
+	if !p.fromFiles {
+		return true
+	}
+	return p.replay.pace(ts, time.Now())
+}
+
+// rewind gets us ready to play a file from the start
+func (r *replay) rewind() {
+	r.first = time.Time{}
+	r.paceFrom = time.Time{}
+	r.paceWall = time.Time{}
+}
+
+func (r *replay) stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopped)
+	})
+}
+
+func (r *replay) isStopped() bool {
+	select {
+	case <-r.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// inWindow tells us if a frame recorded at ts is one we want to play
+func (r *replay) inWindow(ts time.Time) bool {
+	if !r.start.IsZero() && ts.Before(r.start) {
+		return false
+	}
+	if !r.end.IsZero() && ts.After(r.end) {
+		return false
+	}
+	offset := ts.Sub(r.first)
+	if r.startOffset > 0 && offset < r.startOffset {
+		return false
+	}
+	if r.endOffset > 0 && offset > r.endOffset {
+		return false
+	}
+	return true
+}
+
+// pace waits until it is time to play a frame recorded at ts, now is the current time
+func (r *replay) pace(ts, now time.Time) bool {
+	if r.isStopped() {
+		return false
+	}
+	if ts.IsZero() {
+		return true
+	}
+	if r.first.IsZero() {
+		r.first = ts
+	}
+	if !r.inWindow(ts) {
+		return false
+	}
+	if 0 == r.speed {
+		return true
+	}
+	if r.paceFrom.IsZero() {
+		r.paceFrom = ts
+		r.paceWall = now
+		return true
+	}
+
+	wait := r.paceWall.Add(time.Duration(float64(ts.Sub(r.paceFrom)) / r.speed)).Sub(now)
+	if wait <= 0 {
+		return true
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.stopped:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package producer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplay_paceWindow(t *testing.T) {
+	first := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := New(WithReplayOffsets(time.Minute, 2*time.Minute), WithReplayWindow(time.Time{}, first.Add(90*time.Second)))
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   bool
+	}{
+		{name: "first frame", offset: 0, want: false},
+		{name: "before start", offset: 59 * time.Second, want: false},
+		{name: "start", offset: time.Minute, want: true},
+		{name: "middle", offset: 80 * time.Second, want: true},
+		{name: "after end time", offset: 100 * time.Second, want: false},
+		{name: "after end offset", offset: 3 * time.Minute, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.replay.pace(first.Add(tt.offset), now); got != tt.want {
+				t.Errorf("pace() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplay_paceSpeed(t *testing.T) {
+	first := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := New(WithReplaySpeed(10))
+	now := time.Now()
+	if !p.replay.pace(first, now) {
+		t.Fatal("Expected our first frame to be sent")
+	}
+	if !p.replay.paceWall.Equal(now) || !p.replay.paceFrom.Equal(first) {
+		t.Error("Expected our first frame to anchor our pace")
+	}
+
+	// 10 seconds of recording at 10x is a second of playing, we are already past that so there is no waiting
+	start := time.Now()
+	if !p.replay.pace(first.Add(10*time.Second), now.Add(time.Second)) {
+		t.Error("Expected our frame to be sent")
+	}
+	// 1 second of recording at 10x is 100ms
+	if !p.replay.pace(first.Add(time.Second), now) {
+		t.Error("Expected our frame to be sent")
+	}
+	if waited := time.Since(start); waited < 90*time.Millisecond || waited > time.Second {
+		t.Errorf("Expected to wait about 100ms, waited %s", waited)
+	}
+
+	// frames without a recorded time go straight through
+	if !p.replay.pace(time.Time{}, now) {
+		t.Error("Expected a frame without a time to be sent")
+	}
+
+	p.replay.stop()
+	if p.replay.pace(first.Add(time.Hour), now) {
+		t.Error("Should not send frames once we have stopped")
+	}
+	p.replay.rewind()
+	if !p.replay.first.IsZero() || !p.replay.paceFrom.IsZero() {
+		t.Error("Expected rewind to reset our pace")
+	}
+}
+
+func TestReplay_paceNotFiles(t *testing.T) {
+	p := New(WithReplaySpeed(0.001), WithReplayOffsets(time.Hour, 0))
+	if !p.pace(time.Now()) {
+		t.Error("Only frames from files are paced")
+	}
+}
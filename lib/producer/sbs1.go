@@ -8,7 +8,15 @@ import (
 func (p *producer) sbsScanner(scan *bufio.Scanner) error {
 	for scan.Scan() {
 		line := scan.Text()
-		p.addFrame(sbs1.NewFrame(scan.Text()), &p.FrameSource)
+		frame := sbs1.NewFrame(line)
+		if p.fromFiles {
+			// lines we cannot get a time from are left for the tracker to complain about
+			generated, _ := frame.GeneratedTime()
+			if !p.pace(generated) {
+				continue
+			}
+		}
+		p.addFrame(frame, &p.FrameSource)
 		p.addDebug("SBS Frame: %s", line)
 		if nil != p.stats.sbs1 {
 			p.stats.sbs1.Inc()
@@ -0,0 +1,19 @@
+package producer
+
+import (
+	"bufio"
+	"plane.watch/lib/tracker/uat"
+	"time"
+)
+
+func (p *producer) uatScanner(scan *bufio.Scanner) error {
+	for scan.Scan() {
+		line := scan.Text()
+		p.addFrame(uat.NewFrame(line, time.Now()), &p.FrameSource)
+		p.addDebug("UAT Frame: %s", line)
+		if nil != p.stats.uat {
+			p.stats.uat.Inc()
+		}
+	}
+	return scan.Err()
+}
@@ -0,0 +1,36 @@
+package setup
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+	"plane.watch/lib/airports"
+)
+
+func IncludeAirportFlags(app *cli.App) {
+	app.Flags = append(app.Flags, []cli.Flag{
+		&cli.StringFlag{
+			Name:    "airports",
+			Usage:   "An OurAirports style airports.csv. Takeoffs and landings at them are published to the movements queue, and aircraft are checked against their --routes",
+			EnvVars: []string{"AIRPORTS_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "runways",
+			Usage:   "An OurAirports style runways.csv for --airports, so we can tell which runway was used",
+			EnvVars: []string{"RUNWAYS_FILE"},
+		},
+	}...)
+}
+
+// HandleAirportFlags loads our airports, nil if we were not given any
+func HandleAirportFlags(c *cli.Context) (*airports.Database, error) {
+	if "" == c.String("airports") {
+		return nil, nil
+	}
+	db, err := airports.LoadFiles(c.String("airports"), c.String("runways"))
+	if nil != err {
+		return nil, fmt.Errorf("unable to load airports: %w", err)
+	}
+	log.Info().Int("airports", db.Len()).Msg("Loaded airports")
+	return db, nil
+}
@@ -0,0 +1,59 @@
+package setup
+
+import (
+	"github.com/urfave/cli/v2"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/enrich"
+	"time"
+)
+
+func IncludeEnrichFlags(app *cli.App) {
+	app.Flags = append(app.Flags, []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "aircraft-db",
+			Usage:   "A CSV of aircraft (icao24, registration, typecode, serialnumber, owner, operator) to fill in registration, type and owner from. Later files override earlier ones. Send a SIGHUP to reload",
+			EnvVars: []string{"AIRCRAFT_DB"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "routes",
+			Usage:   "A CSV of routes (callsign, airportcodes) to fill in where flights are from and going to, when the aircraft is along the route. Needs --airports",
+			EnvVars: []string{"ROUTES_FILE"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "airlines",
+			Usage:   "A CSV of airlines (icao, name) to fill in the operator of flights from their call sign",
+			EnvVars: []string{"AIRLINES_FILE"},
+		},
+		&cli.DurationFlag{
+			Name:    "enrich-reload",
+			Usage:   "How often to check the enrichment files for changes and reload them, 0 to only reload on SIGHUP",
+			Value:   time.Minute,
+			EnvVars: []string{"ENRICH_RELOAD"},
+		},
+	}...)
+}
+
+// HandleEnrichFlags loads our enrichment files and starts watching them for changes, empty if we do not have any.
+// airportsDb is needed for routes
+func HandleEnrichFlags(c *cli.Context, airportsDb *airports.Database) (enrich.Enrichers, error) {
+	enrichers := enrich.Enrichers{}
+	if files := c.StringSlice("aircraft-db"); 0 != len(files) {
+		db, err := enrich.NewAircraftDb(files...)
+		if nil != err {
+			return nil, err
+		}
+		go db.Watch(c.Duration("enrich-reload"))
+		enrichers = append(enrichers, db)
+	}
+	routeFiles, airlineFiles := c.StringSlice("routes"), c.StringSlice("airlines")
+	if 0 != len(routeFiles) || 0 != len(airlineFiles) {
+		db, err := enrich.NewRouteDb(airportsDb, routeFiles, airlineFiles)
+		if nil != err {
+			enrichers.Stop()
+			return nil, err
+		}
+		go db.Watch(c.Duration("enrich-reload"))
+		enrichers = append(enrichers, db)
+	}
+	return enrichers, nil
+}
@@ -0,0 +1,37 @@
+package setup
+
+import (
+	"github.com/urfave/cli/v2"
+	"plane.watch/lib/geofence"
+	"time"
+)
+
+func IncludeGeofenceFlags(app *cli.App) {
+	app.Flags = append(app.Flags, []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "geofence",
+			Usage:   "A GeoJSON file of Polygons to send enter/exit/dwell events for. Feature properties: id, name, floor, ceiling (feet), dwell. Send a SIGHUP to reload",
+			EnvVars: []string{"GEOFENCE"},
+		},
+		&cli.DurationFlag{
+			Name:    "geofence-reload",
+			Usage:   "How often to check the geofence files for changes and reload them, 0 to only reload on SIGHUP",
+			Value:   30 * time.Second,
+			EnvVars: []string{"GEOFENCE_RELOAD"},
+		},
+	}...)
+}
+
+// HandleGeofenceFlags loads our geofences and starts watching them for changes, nil if we do not have any
+func HandleGeofenceFlags(c *cli.Context) (*geofence.Engine, error) {
+	files := c.StringSlice("geofence")
+	if 0 == len(files) {
+		return nil, nil
+	}
+	engine, err := geofence.NewEngine(files...)
+	if nil != err {
+		return nil, err
+	}
+	go engine.Watch(c.Duration("geofence-reload"))
+	return engine, nil
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/urfave/cli/v2"
 	"math"
 	"net/url"
+	"plane.watch/lib/enrich"
 	"plane.watch/lib/sink"
 	"plane.watch/lib/tracker"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -33,7 +35,7 @@ func IncludeSinkFlags(app *cli.App) {
 	app.Flags = append(app.Flags, []cli.Flag{
 		&cli.StringSliceFlag{
 			Name:    "sink",
-			Usage:   "The place to send decoded JSON in URL Form. [redis|amqp]://user:pass@host:port/vhost?ttl=60",
+			Usage:   "The place to send decoded JSON in URL Form. [redis|amqp]://user:pass@host:port/vhost?ttl=60&estimate=2s",
 			EnvVars: []string{"SINK"},
 		},
 		&cli.StringSliceFlag{
@@ -54,7 +56,8 @@ func IncludeSinkFlags(app *cli.App) {
 	}...)
 }
 
-func HandleSinkFlags(c *cli.Context) ([]tracker.Sink, error) {
+// HandleSinkFlags sets up our sinks, every location update they publish is run through enrichers first
+func HandleSinkFlags(c *cli.Context, enrichers ...enrich.Enricher) ([]tracker.Sink, error) {
 	defaultTTl := c.Int("sink-message-ttl")
 	defaultTag := c.String("tag")
 	defaultQueues := c.StringSlice("publish-types")
@@ -62,7 +65,7 @@ func HandleSinkFlags(c *cli.Context) ([]tracker.Sink, error) {
 
 	for _, sinkUrl := range c.StringSlice("sink") {
 		log.Debug().Str("sink-url", sinkUrl).Msg("With Sink")
-		s, err := handleSink(sinkUrl, defaultTag, defaultTTl, defaultQueues, c.Bool("rabbitmq-test-queues"))
+		s, err := handleSink(sinkUrl, defaultTag, defaultTTl, defaultQueues, c.Bool("rabbitmq-test-queues"), enrichers)
 		if nil != err {
 			log.Error().Err(err).Str("url", sinkUrl).Str("what", "sink").Msg("Failed setup sink")
 			return nil, err
@@ -73,7 +76,7 @@ func HandleSinkFlags(c *cli.Context) ([]tracker.Sink, error) {
 	return sinks, nil
 }
 
-func handleSink(urlSink, defaultTag string, defaultTtl int, defaultQueues []string, rabbitmqTestQueues bool) (tracker.Sink, error) {
+func handleSink(urlSink, defaultTag string, defaultTtl int, defaultQueues []string, rabbitmqTestQueues bool, enrichers []enrich.Enricher) (tracker.Sink, error) {
 	parsedUrl, err := url.Parse(urlSink)
 	if nil != err {
 		return nil, err
@@ -95,6 +98,15 @@ func handleSink(urlSink, defaultTag string, defaultTtl int, defaultQueues []stri
 		sink.WithSourceTag(getTag(parsedUrl, defaultTag)),
 		sink.WithMessageTtl(messageTtl),
 		sink.WithPrometheusCounters(prometheusOutputFrame, prometheusOutputFrameDedupe, prometheusOutputPlaneLocation),
+		sink.WithEnrichers(enrichers...),
+	}
+	if parsedUrl.Query().Has("estimate") {
+		// publish predicted positions for planes we have not heard from in this long
+		estimate, err := time.ParseDuration(parsedUrl.Query().Get("estimate"))
+		if nil != err {
+			return nil, fmt.Errorf("invalid estimate interval: %w", err)
+		}
+		commonOpts = append(commonOpts, sink.WithEstimatedPositions(estimate))
 	}
 
 	switch strings.ToLower(parsedUrl.Scheme) {
@@ -9,8 +9,10 @@ import (
 	"net/url"
 	"plane.watch/lib/producer"
 	"plane.watch/lib/tracker"
+	"plane.watch/lib/tracker/mode_s"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -26,23 +28,27 @@ var (
 		Name: "pw_ingest_input_sbs1_total",
 		Help: "The total number of SBS1 frames processed.",
 	})
+	prometheusInputUatFrames = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pw_ingest_input_uat_total",
+		Help: "The total number of UAT (dump978 raw) frames processed.",
+	})
 )
 
 func IncludeSourceFlags(app *cli.App) {
 	sourceFlags := []cli.Flag{
 		&cli.StringSliceFlag{
 			Name:    "fetch",
-			Usage:   "The Source in URL Form. [avr|beast|sbs1]://host:port?tag=MYTAG&refLat=-31.0&refLon=115.0",
+			Usage:   "The Source in URL Form. [avr|beast|sbs1|uat]://host:port?tag=MYTAG&refLat=-31.0&refLon=115.0",
 			EnvVars: []string{"SOURCE"},
 		},
 		&cli.StringSliceFlag{
 			Name:    "listen",
-			Usage:   "The Source in URL Form. [avr|beast|sbs1]://host:port?tag=MYTAG&refLat=-31.0&refLon=115.0",
+			Usage:   "The Source in URL Form. [avr|beast|sbs1|uat]://host:port?tag=MYTAG&refLat=-31.0&refLon=115.0",
 			EnvVars: []string{"LISTEN"},
 		},
 		&cli.StringSliceFlag{
 			Name:    "file",
-			Usage:   "The Source in URL Form. [avr|beast|sbs1]:///path/to/file?tag=MYTAG&refLat=-31.0&refLon=115.0",
+			Usage:   "The Source in URL Form. [avr|beast|sbs1|uat]:///path/to/file?tag=MYTAG&refLat=-31.0&refLon=115.0&speed=1x&start=5m&end=10m&loop=true",
 			EnvVars: []string{"FILE"},
 		},
 
@@ -60,11 +66,27 @@ func IncludeSourceFlags(app *cli.App) {
 			Usage:   "A value that is included in the payloads output to the Sinks. Useful for knowing where something came from",
 			EnvVars: []string{"TAG"},
 		},
+
+		&cli.StringFlag{
+			Name:    "crc-fix",
+			Usage:   "Fix bit errors in DF11 and DF17/18 Mode S frames. [off|1-bit|2-bit]",
+			Value:   "off",
+			EnvVars: []string{"CRC_FIX"},
+		},
 	}
 
 	app.Flags = append(app.Flags, sourceFlags...)
 }
 
+// HandleErrorCorrectionFlag gives us the tracker option for how many bit errors --crc-fix fixes
+func HandleErrorCorrectionFlag(c *cli.Context) (tracker.Option, error) {
+	level, err := mode_s.ParseErrorCorrection(c.String("crc-fix"))
+	if nil != err {
+		return nil, err
+	}
+	return tracker.WithErrorCorrection(level), nil
+}
+
 func HandleSourceFlags(c *cli.Context) ([]tracker.Producer, error) {
 	refLat := c.Float64("refLat")
 	refLon := c.Float64("refLon")
@@ -136,10 +158,12 @@ func handleSource(urlSource, defaultTag string, defaultRefLat, defaultRefLon flo
 		producerOpts[1] = producer.WithType(producer.Beast)
 	case "sbs1":
 		producerOpts[1] = producer.WithType(producer.Sbs1)
+	case "uat":
+		producerOpts[1] = producer.WithType(producer.Uat)
 	default:
-		return nil, fmt.Errorf("unknown scheme: %s, expected one of [avr|beast|sbs1]", parsedUrl.Scheme)
+		return nil, fmt.Errorf("unknown scheme: %s, expected one of [avr|beast|sbs1|uat]", parsedUrl.Scheme)
 	}
-	producerOpts[2] = producer.WithPrometheusCounters(prometheusInputAvrFrames, prometheusInputBeastFrames, prometheusInputSbs1Frames)
+	producerOpts[2] = producer.WithPrometheusCounters(prometheusInputAvrFrames, prometheusInputBeastFrames, prometheusInputSbs1Frames, prometheusInputUatFrames)
 
 	refLat := getRef(parsedUrl, "refLat", defaultRefLat)
 	refLon := getRef(parsedUrl, "refLon", defaultRefLon)
@@ -169,6 +193,8 @@ func handleFileSource(urlFile, defaultTag string, defaultRefLat, defaultRefLon f
 		producerOpts[0] = producer.WithType(producer.Beast)
 	case "sbs1":
 		producerOpts[0] = producer.WithType(producer.Sbs1)
+	case "uat":
+		producerOpts[0] = producer.WithType(producer.Uat)
 	default:
 		return nil, fmt.Errorf("unknown file Type: %s", parsedUrl.Scheme)
 	}
@@ -178,6 +204,12 @@ func handleFileSource(urlFile, defaultTag string, defaultRefLat, defaultRefLon f
 		producerOpts = append(producerOpts, producer.WithReferenceLatLon(refLat, refLon))
 	}
 
+	replayOpts, err := getReplayOptions(parsedUrl, strings.ToLower(parsedUrl.Scheme))
+	if nil != err {
+		return nil, err
+	}
+	producerOpts = append(producerOpts, replayOpts...)
+
 	producerOpts = append(
 		producerOpts,
 		producer.WithSourceTag(getTag(parsedUrl, defaultTag)),
@@ -186,3 +218,63 @@ func handleFileSource(urlFile, defaultTag string, defaultRefLat, defaultRefLon f
 
 	return producer.New(producerOpts...), nil
 }
+
+// getReplayOptions works out how we play back a file, e.g. ?speed=10x&start=5m&end=2021-10-01T12:30:00Z&loop=true
+// speed is a multiple of real time or max (the default). start and end are either a time or how long after
+// the first frame in the file. Only SBS1 records the actual time, beast and AVR timestamps only tell us how far
+// into the file we are, so those can only use offsets
+func getReplayOptions(parsedUrl *url.URL, scheme string) ([]producer.Option, error) {
+	query := parsedUrl.Query()
+	opts := make([]producer.Option, 0)
+
+	if query.Has("speed") {
+		speedStr := strings.ToLower(query.Get("speed"))
+		if "max" != speedStr {
+			speed, err := strconv.ParseFloat(strings.TrimSuffix(speedStr, "x"), 64)
+			if nil != err || speed <= 0 {
+				return nil, fmt.Errorf("invalid replay speed %s, expected something like 0.5x, 1x, 10x or max", query.Get("speed"))
+			}
+			opts = append(opts, producer.WithReplaySpeed(speed))
+		}
+	}
+
+	var startTime, endTime time.Time
+	var startOffset, endOffset time.Duration
+	for _, what := range []string{"start", "end"} {
+		if !query.Has(what) {
+			continue
+		}
+		value := query.Get(what)
+		var err error
+		var offset time.Duration
+		var when time.Time
+		if offset, err = time.ParseDuration(value); nil != err {
+			if when, err = time.Parse(time.RFC3339, value); nil != err {
+				return nil, fmt.Errorf("invalid replay %s %s, expected a duration (5m) or a time (2021-10-01T12:30:00Z)", what, value)
+			}
+			if "sbs1" != scheme {
+				return nil, fmt.Errorf("invalid replay %s %s, %s files do not record the time of day, use how long after the first frame (e.g. 5m) instead", what, value, scheme)
+			}
+		}
+		if "start" == what {
+			startTime, startOffset = when, offset
+		} else {
+			endTime, endOffset = when, offset
+		}
+	}
+	if !startTime.IsZero() || !endTime.IsZero() {
+		opts = append(opts, producer.WithReplayWindow(startTime, endTime))
+	}
+	if 0 != startOffset || 0 != endOffset {
+		opts = append(opts, producer.WithReplayOffsets(startOffset, endOffset))
+	}
+
+	if query.Has("loop") {
+		loop, err := strconv.ParseBool(query.Get("loop"))
+		if nil != err {
+			return nil, fmt.Errorf("invalid replay loop %s, expected true or false", query.Get("loop"))
+		}
+		opts = append(opts, producer.WithReplayLoop(loop))
+	}
+	return opts, nil
+}
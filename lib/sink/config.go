@@ -5,7 +5,9 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
+	"plane.watch/lib/enrich"
 	"sync"
+	"time"
 )
 
 const (
@@ -16,6 +18,12 @@ const (
 	QueueTypeSbs1All     = "sbs1-all"
 	QueueTypeSbs1Reduce  = "sbs1-reduce"
 	QueueTypeDecodedJson = "decoded-json"
+	QueueTypeWeather     = "weather-reports"
+	QueueTypeAcasRa      = "acas-ra"
+	QueueTypeEmergency   = "emergency"
+	QueueTypeFlightPhase = "flight-phase"
+	QueueTypeMovements   = "movements"
+	QueueTypeGeofence    = "geofence"
 	QueueTypeLogs        = "logs"
 	QueueLocationUpdates = "location-updates"
 )
@@ -28,6 +36,12 @@ var AllQueues = [...]string{
 	QueueTypeSbs1All,
 	QueueTypeSbs1Reduce,
 	QueueTypeDecodedJson,
+	QueueTypeWeather,
+	QueueTypeAcasRa,
+	QueueTypeEmergency,
+	QueueTypeFlightPhase,
+	QueueTypeMovements,
+	QueueTypeGeofence,
 	QueueTypeLogs,
 	QueueLocationUpdates,
 }
@@ -49,6 +63,12 @@ type (
 
 		createTestQueues bool
 
+		// enrichers fill in what they know about a plane before we publish it
+		enrichers enrich.Enrichers
+
+		// estimateInterval is how often we publish predicted positions for planes we have not heard from, 0 for never
+		estimateInterval time.Duration
+
 		stats struct {
 			dedupeFrame, frame, planeLoc prometheus.Counter
 		}
@@ -110,6 +130,21 @@ func WithLogFile(file string) Option {
 	}
 }
 
+// WithEstimatedPositions publishes a predicted location every interval for planes that have not sent a position
+// in that time, so maps can keep them moving. These are flagged as Estimated
+func WithEstimatedPositions(interval time.Duration) Option {
+	return func(conf *Config) {
+		conf.estimateInterval = interval
+	}
+}
+
+// WithEnrichers fills in what our enrichers know about a plane (registration, type, owner...) before we publish it
+func WithEnrichers(enrichers ...enrich.Enricher) Option {
+	return func(conf *Config) {
+		conf.enrichers = append(conf.enrichers, enrichers...)
+	}
+}
+
 func WithPrometheusCounters(frame, dedupeFrame, planeLoc prometheus.Counter) Option {
 	return func(conf *Config) {
 		conf.stats.frame = frame
@@ -155,6 +190,12 @@ func WithAllQueues() Option {
 		conf.queue[QueueTypeSbs1All] = QueueTypeSbs1All
 		conf.queue[QueueTypeSbs1Reduce] = QueueTypeSbs1Reduce
 		conf.queue[QueueTypeDecodedJson] = QueueTypeDecodedJson
+		conf.queue[QueueTypeWeather] = QueueTypeWeather
+		conf.queue[QueueTypeAcasRa] = QueueTypeAcasRa
+		conf.queue[QueueTypeEmergency] = QueueTypeEmergency
+		conf.queue[QueueTypeFlightPhase] = QueueTypeFlightPhase
+		conf.queue[QueueTypeMovements] = QueueTypeMovements
+		conf.queue[QueueTypeGeofence] = QueueTypeGeofence
 		conf.queue[QueueTypeLogs] = QueueTypeLogs
 		conf.queue[QueueLocationUpdates] = QueueLocationUpdates
 	}
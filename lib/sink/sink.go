@@ -3,6 +3,7 @@ package sink
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
 	"plane.watch/lib/dedupe"
 	"plane.watch/lib/export"
@@ -17,6 +18,7 @@ import (
 
 	//"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -34,9 +36,21 @@ type (
 		dest   Destination
 		events chan tracker.Event
 
+		// planes we publish estimated positions for, see WithEstimatedPositions
+		planes        sync.Map
+		stopEstimates chan bool
+		estimatesWg   sync.WaitGroup
+
 		sendFrameAll    func(tracker.Frame, *tracker.FrameSource) error
 		sendFrameDedupe func(tracker.Frame, *tracker.FrameSource) error
 	}
+
+	// decodedFrameMsg is what we send to the decoded-json queue, Frame has every field of the decoded frame
+	decodedFrameMsg struct {
+		Type   string
+		Frame  *mode_s.Frame
+		Source *tracker.FrameSource
+	}
 )
 
 const ansi = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
@@ -60,6 +74,11 @@ func NewSink(conf *Config, dest Destination) tracker.Sink {
 
 	s.sendFrameAll = s.sendFrameEvent(QueueTypeAvrAll, QueueTypeBeastAll, QueueTypeSbs1All)
 	s.sendFrameDedupe = s.sendFrameEvent(QueueTypeAvrReduce, QueueTypeBeastReduce, QueueTypeSbs1Reduce)
+	if s.config.estimateInterval > 0 {
+		s.stopEstimates = make(chan bool)
+		s.estimatesWg.Add(1)
+		go s.estimatePositions()
+	}
 	return &s
 }
 
@@ -73,6 +92,11 @@ func (s *Sink) Listen() chan tracker.Event {
 }
 
 func (s *Sink) Stop() {
+	if nil != s.stopEstimates {
+		close(s.stopEstimates)
+		// make sure we are not publishing an estimate while the destination goes away
+		s.estimatesWg.Wait()
+	}
 	close(s.events)
 	s.config.Finish()
 	s.dest.Stop()
@@ -93,16 +117,40 @@ func (s *Sink) sendLocationEvent(routingKey string, le *tracker.PlaneLocationEve
 }
 
 func (s *Sink) trackerMsgJson(le *tracker.PlaneLocationEvent) ([]byte, error) {
-	var err error
 	plane := le.Plane()
 	if nil == plane {
 		return nil, errors.New("no plane")
 	}
 
+	eventStruct := s.planeLocation(plane)
+	eventStruct.New = le.New()
+	eventStruct.Removed = le.Removed()
+	return s.planeLocationJson(&eventStruct)
+}
+
+// estimatedMsgJson is the plane with where we think it is now, rather than where it last told us it was
+func (s *Sink) estimatedMsgJson(plane *tracker.Plane, loc *tracker.PlaneLocation) ([]byte, error) {
+	eventStruct := s.planeLocation(plane)
+	eventStruct.Lat = loc.Lat()
+	eventStruct.Lon = loc.Lon()
+	eventStruct.Altitude = int(loc.Altitude())
+	eventStruct.TileLocation = loc.GridTileLocation()
+	eventStruct.Estimated = true
+	return s.planeLocationJson(&eventStruct)
+}
+
+func (s *Sink) planeLocation(plane *tracker.Plane) export.PlaneLocation {
+	eventStruct := PlaneLocation(plane, s.config.sourceTag)
+	if 0 != len(s.config.enrichers) {
+		s.config.enrichers.Enrich(&eventStruct)
+	}
+	return eventStruct
+}
+
+// PlaneLocation is the plane in the form we publish it, without any enrichment
+func PlaneLocation(plane *tracker.Plane, sourceTag string) export.PlaneLocation {
 	callSign := strings.TrimSpace(plane.FlightNumber())
 	eventStruct := export.PlaneLocation{
-		New:             le.New(),
-		Removed:         le.Removed(),
 		Icao:            plane.IcaoIdentifierStr(),
 		Lat:             plane.Lat(),
 		Lon:             plane.Lon(),
@@ -125,15 +173,64 @@ func (s *Sink) trackerMsgJson(le *tracker.PlaneLocationEvent) ([]byte, error) {
 		HasHeading:      plane.HasHeading(),
 		HasVerticalRate: plane.HasVerticalRate(),
 		HasVelocity:     plane.HasVelocity(),
-		SourceTag:       s.config.sourceTag,
+		SourceTag:       sourceTag,
+		DataSource:      plane.DataSource(),
+		AddressType:     plane.AddressType().String(),
 		TileLocation:    plane.GridTileLocation(),
 		LastMsg:         plane.LastSeen().UTC(),
 		TrackedSince:    plane.TrackedSince().UTC(),
 		SignalRssi:      plane.SignalLevel(),
+
+		SelectedAltitudeMcp:  plane.SelectedAltitudeMcp(),
+		SelectedAltitudeFms:  plane.SelectedAltitudeFms(),
+		BaroSetting:          plane.BaroSetting(),
+		RollAngle:            plane.RollAngle(),
+		TrueTrack:            plane.TrueTrack(),
+		TrackRate:            plane.TrackRate(),
+		GroundSpeed:          plane.GroundSpeed(),
+		TrueAirSpeed:         plane.TrueAirSpeed(),
+		MagneticHeading:      plane.MagneticHeading(),
+		IndicatedAirSpeed:    plane.IndicatedAirSpeed(),
+		Mach:                 plane.Mach(),
+		BaroVerticalRate:     plane.BaroVerticalRate(),
+		InertialVerticalRate: plane.InertialVerticalRate(),
+
+		SelectedHeading:     plane.SelectedHeading(),
+		AutopilotEngaged:    plane.AutopilotEngaged(),
+		VnavEngaged:         plane.VnavEngaged(),
+		LnavEngaged:         plane.LnavEngaged(),
+		AltitudeHoldEngaged: plane.AltitudeHoldEngaged(),
+		ApproachEngaged:     plane.ApproachEngaged(),
+		TcasOperational:     plane.TcasOperational(),
+	}
+	if emergency := plane.Emergency(); emergency.IsEmergency() {
+		emergencyStr := emergency.String()
+		eventStruct.Emergency = &emergencyStr
+	}
+	if phase := plane.FlightPhase(); tracker.FlightPhaseUnknown != phase {
+		eventStruct.FlightPhase = phase.String()
+	}
+	if country, ok := plane.Country(); ok && "" != country.FlagCode {
+		eventStruct.FlagCode = &country.FlagCode
 	}
+	eventStruct.Military = plane.IsMilitary()
+	if plane.PublishSmoothed() {
+		if loc, err := plane.SmoothedLocation(); nil == err {
+			eventStruct.Lat = loc.Lat()
+			eventStruct.Lon = loc.Lon()
+			eventStruct.Altitude = int(loc.Altitude())
+			eventStruct.Heading = loc.Heading()
+			eventStruct.Velocity = loc.Velocity()
+			eventStruct.VerticalRate = loc.VerticalRate()
+			eventStruct.TileLocation = loc.GridTileLocation()
+			eventStruct.Smoothed = true
+		}
+	}
+	return eventStruct
+}
 
-	var jsonBuf []byte
-	jsonBuf, err = json.MarshalIndent(&eventStruct, "", "  ")
+func (s *Sink) planeLocationJson(eventStruct *export.PlaneLocation) ([]byte, error) {
+	jsonBuf, err := json.MarshalIndent(eventStruct, "", "  ")
 	if nil != err {
 		log.Error().Err(err).Msg("could not create json bytes for sending")
 		return nil, err
@@ -142,6 +239,241 @@ func (s *Sink) trackerMsgJson(le *tracker.PlaneLocationEvent) ([]byte, error) {
 	}
 }
 
+func (s *Sink) weatherReportJson(we *tracker.WeatherReportEvent) ([]byte, error) {
+	frame := we.Frame()
+	plane := we.Plane()
+	if nil == frame || nil == plane {
+		return nil, errors.New("no weather report")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	report := export.WeatherReport{
+		Icao:          plane.IcaoIdentifierStr(),
+		CallSign:      &callSign,
+		Bds:           frame.BdsMessageType(),
+		Lat:           we.Lat(),
+		Lon:           we.Lon(),
+		HasLocation:   we.HasLocation(),
+		Altitude:      int(we.Altitude()),
+		AltitudeUnits: we.AltitudeUnits(),
+		SourceTag:     s.config.sourceTag,
+		When:          frame.TimeStamp().UTC(),
+	}
+
+	if speed, direction, err := frame.Wind(); nil == err {
+		report.WindSpeed = &speed
+		report.WindDirection = &direction
+	}
+	if temp, err := frame.StaticAirTemperature(); nil == err {
+		report.StaticAirTemperature = &temp
+	}
+	if pressure, err := frame.AverageStaticPressure(); nil == err {
+		report.AverageStaticPressure = &pressure
+	}
+	if humidity, err := frame.Humidity(); nil == err {
+		report.Humidity = &humidity
+	}
+	if height, err := frame.RadioHeight(); nil == err {
+		report.RadioHeight = &height
+	}
+	hazard := func(level byte, err error) *string {
+		if nil != err {
+			return nil
+		}
+		str := mode_s.HazardLevelString(level)
+		return &str
+	}
+	report.Turbulence = hazard(frame.Turbulence())
+	report.WindShear = hazard(frame.WindShear())
+	report.Microburst = hazard(frame.Microburst())
+	report.Icing = hazard(frame.Icing())
+	report.WakeVortex = hazard(frame.WakeVortex())
+
+	jsonBuf, err := json.Marshal(&report)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create weather report json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
+func (s *Sink) emergencyJson(ee *tracker.EmergencyEvent) ([]byte, error) {
+	plane := ee.Plane()
+	if nil == plane {
+		return nil, errors.New("no plane")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	emergency := export.Emergency{
+		Icao:          plane.IcaoIdentifierStr(),
+		CallSign:      &callSign,
+		Squawk:        plane.SquawkIdentityStr(),
+		Emergency:     ee.Emergency().String(),
+		Previous:      ee.Previous().String(),
+		Active:        ee.Entered(),
+		Lat:           ee.Lat(),
+		Lon:           ee.Lon(),
+		HasLocation:   ee.HasLocation(),
+		Altitude:      int(ee.Altitude()),
+		AltitudeUnits: ee.AltitudeUnits(),
+		TileLocation:  plane.GridTileLocation(),
+		SourceTag:     s.config.sourceTag,
+		When:          ee.When().UTC(),
+	}
+
+	jsonBuf, err := json.Marshal(&emergency)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create emergency json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
+func (s *Sink) flightPhaseJson(fe *tracker.FlightPhaseEvent) ([]byte, error) {
+	plane := fe.Plane()
+	if nil == plane {
+		return nil, errors.New("no plane")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	phase := export.FlightPhase{
+		Icao:          plane.IcaoIdentifierStr(),
+		CallSign:      &callSign,
+		Phase:         fe.Phase().String(),
+		Previous:      fe.Previous().String(),
+		Departure:     fe.Departure(),
+		Arrival:       fe.Arrival(),
+		Lat:           fe.Lat(),
+		Lon:           fe.Lon(),
+		HasLocation:   fe.HasLocation(),
+		Altitude:      int(fe.Altitude()),
+		AltitudeUnits: fe.AltitudeUnits(),
+		TileLocation:  plane.GridTileLocation(),
+		SourceTag:     s.config.sourceTag,
+		When:          fe.When().UTC(),
+	}
+
+	jsonBuf, err := json.Marshal(&phase)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create flight phase json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
+func (s *Sink) movementJson(me *tracker.MovementEvent) ([]byte, error) {
+	plane := me.Plane()
+	airport := me.Airport()
+	if nil == plane || nil == airport {
+		return nil, errors.New("no movement")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	movement := export.Movement{
+		Icao:        plane.IcaoIdentifierStr(),
+		CallSign:    &callSign,
+		Kind:        me.Kind().String(),
+		Airport:     airport.Ident,
+		AirportName: airport.Name,
+		IataCode:    airport.IataCode,
+		Lat:         me.Lat(),
+		Lon:         me.Lon(),
+		SourceTag:   s.config.sourceTag,
+		When:        me.When().UTC(),
+	}
+	if runway := me.Runway(); nil != runway {
+		movement.Runway = runway.Ident
+	}
+	if me.HasHeading() {
+		heading := me.Heading()
+		movement.Heading = &heading
+	}
+
+	jsonBuf, err := json.Marshal(&movement)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create movement json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
+func (s *Sink) geofenceJson(ge *tracker.GeofenceEvent) ([]byte, error) {
+	plane := ge.Plane()
+	if nil == plane {
+		return nil, errors.New("no plane")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	fence := ge.Event().Export(&callSign, s.config.sourceTag)
+	jsonBuf, err := json.Marshal(&fence)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create geofence json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
+func (s *Sink) acasRaJson(ae *tracker.AcasRaEvent) ([]byte, error) {
+	frame := ae.Frame()
+	plane := ae.Plane()
+	if nil == frame || nil == plane {
+		return nil, errors.New("no acas resolution advisory")
+	}
+
+	callSign := strings.TrimSpace(plane.FlightNumber())
+	ra := export.AcasRa{
+		Icao:           plane.IcaoIdentifierStr(),
+		CallSign:       &callSign,
+		Lat:            ae.Lat(),
+		Lon:            ae.Lon(),
+		HasLocation:    ae.HasLocation(),
+		Altitude:       int(ae.Altitude()),
+		AltitudeUnits:  ae.AltitudeUnits(),
+		TileLocation:   plane.GridTileLocation(),
+		SourceTag:      s.config.sourceTag,
+		When:           frame.TimeStamp().UTC(),
+		Advisories:     frame.AcasActiveRa(),
+		Complements:    frame.AcasRaComplements(),
+		Terminated:     frame.AcasRaTerminated(),
+		MultipleThreat: frame.AcasMultipleThreat(),
+	}
+
+	if icao, err := frame.AcasThreatIcao(); nil == err {
+		threatIcao := fmt.Sprintf("%06X", icao)
+		ra.ThreatIcao = &threatIcao
+	}
+	if alt, err := frame.AcasThreatAltitude(); nil == err {
+		threatAlt := int(alt)
+		ra.ThreatAltitude = &threatAlt
+	}
+	if rng, err := frame.AcasThreatRange(); nil == err {
+		ra.ThreatRange = &rng
+	}
+	if bearing, err := frame.AcasThreatBearing(); nil == err {
+		ra.ThreatBearing = &bearing
+	}
+	if threat := ae.Threat(); nil != threat {
+		threatCallSign := strings.TrimSpace(threat.FlightNumber())
+		ra.ThreatCallSign = &threatCallSign
+		if threat.HasLocation() {
+			lat, lon := threat.Lat(), threat.Lon()
+			ra.ThreatLat = &lat
+			ra.ThreatLon = &lon
+		}
+		if nil == ra.ThreatAltitude && "" != threat.AltitudeUnits() {
+			threatAlt := int(threat.Altitude())
+			ra.ThreatAltitude = &threatAlt
+		}
+	}
+
+	jsonBuf, err := json.Marshal(&ra)
+	if nil != err {
+		log.Error().Err(err).Msg("could not create acas ra json bytes for sending")
+		return nil, err
+	}
+	return jsonBuf, nil
+}
+
 func (s *Sink) sendFrameEvent(queueAvr, queueBeast, queueSbs1 string) func(tracker.Frame, *tracker.FrameSource) error {
 	return func(ourFrame tracker.Frame, source *tracker.FrameSource) error {
 		var err error
@@ -174,6 +506,34 @@ func (s *Sink) sendFrameEvent(queueAvr, queueBeast, queueSbs1 string) func(track
 	}
 }
 
+// sendDecodedJson publishes the Mode S frame with all of its decoded fields
+func (s *Sink) sendDecodedJson(ourFrame tracker.Frame, source *tracker.FrameSource) error {
+	if _, ok := s.config.queue[QueueTypeDecodedJson]; !ok {
+		return nil
+	}
+	// the tracker may still be decoding its copy of the frame, so we decode our own
+	var frame *mode_s.Frame
+	var err error
+	switch ourFrame.(type) {
+	case *mode_s.Frame:
+		frame, err = mode_s.DecodeString(string(ourFrame.Raw()), ourFrame.TimeStamp())
+	case *beast.Frame:
+		b := beast.NewFrame(ourFrame.Raw(), false)
+		b.SetTimeStamp(ourFrame.TimeStamp())
+		frame = b.AvrFrame()
+		_, err = frame.Decode()
+	}
+	if nil != err || nil == frame {
+		return err
+	}
+
+	body, err := json.Marshal(decodedFrameMsg{Type: "mode_s", Frame: frame, Source: source})
+	if nil != err {
+		return err
+	}
+	return s.dest.PublishJson(QueueTypeDecodedJson, body)
+}
+
 func (s *Sink) OnEvent(e tracker.Event) {
 	var err error
 	switch e.(type) {
@@ -182,6 +542,13 @@ func (s *Sink) OnEvent(e tracker.Event) {
 
 	case *tracker.PlaneLocationEvent:
 		le := e.(*tracker.PlaneLocationEvent)
+		if nil != s.stopEstimates && nil != le.Plane() {
+			if le.Removed() {
+				s.planes.Delete(le.Plane())
+			} else {
+				s.planes.Store(le.Plane(), true)
+			}
+		}
 		var jsonBuf []byte
 		jsonBuf, err = s.trackerMsgJson(le)
 		if nil != jsonBuf && nil == err {
@@ -191,11 +558,68 @@ func (s *Sink) OnEvent(e tracker.Event) {
 			}
 		}
 
+	case *tracker.WeatherReportEvent:
+		if _, ok := s.config.queue[QueueTypeWeather]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.weatherReportJson(e.(*tracker.WeatherReportEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeWeather, jsonBuf)
+			}
+		}
+
+	case *tracker.AcasRaEvent:
+		if _, ok := s.config.queue[QueueTypeAcasRa]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.acasRaJson(e.(*tracker.AcasRaEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeAcasRa, jsonBuf)
+			}
+		}
+
+	case *tracker.EmergencyEvent:
+		if _, ok := s.config.queue[QueueTypeEmergency]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.emergencyJson(e.(*tracker.EmergencyEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeEmergency, jsonBuf)
+			}
+		}
+
+	case *tracker.FlightPhaseEvent:
+		if _, ok := s.config.queue[QueueTypeFlightPhase]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.flightPhaseJson(e.(*tracker.FlightPhaseEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeFlightPhase, jsonBuf)
+			}
+		}
+
+	case *tracker.MovementEvent:
+		if _, ok := s.config.queue[QueueTypeMovements]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.movementJson(e.(*tracker.MovementEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeMovements, jsonBuf)
+			}
+		}
+
+	case *tracker.GeofenceEvent:
+		if _, ok := s.config.queue[QueueTypeGeofence]; ok {
+			var jsonBuf []byte
+			jsonBuf, err = s.geofenceJson(e.(*tracker.GeofenceEvent))
+			if nil != jsonBuf && nil == err {
+				err = s.dest.PublishJson(QueueTypeGeofence, jsonBuf)
+			}
+		}
+
 	case *tracker.FrameEvent:
 		//println("Got a Frame!")
 		ourFrame := e.(*tracker.FrameEvent).Frame()
 		source := e.(*tracker.FrameEvent).Source()
 		err = s.sendFrameAll(ourFrame, source)
+		if nil == err {
+			err = s.sendDecodedJson(ourFrame, source)
+		}
 		if nil != s.config.stats.frame {
 			s.config.stats.frame.Inc()
 		}
@@ -221,6 +645,39 @@ func (s *Sink) OnEvent(e tracker.Event) {
 	}
 }
 
+// estimatePositions publishes predicted positions for the planes we have not had a position from in a while
+func (s *Sink) estimatePositions() {
+	defer s.estimatesWg.Done()
+	ticker := time.NewTicker(s.config.estimateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.planes.Range(func(key, value interface{}) bool {
+				plane := key.(*tracker.Plane)
+				history := plane.LocationHistory()
+				if 0 == len(history) || now.Sub(history[len(history)-1].TimeStamp()) < s.config.estimateInterval {
+					return true
+				}
+				loc, err := plane.PredictLocation(now)
+				if nil != err {
+					return true
+				}
+				jsonBuf, err := s.estimatedMsgJson(plane, loc)
+				if nil == err {
+					err = s.dest.PublishJson(QueueLocationUpdates, jsonBuf)
+				}
+				if nil != err {
+					log.Error().Err(err).Str("icao", plane.IcaoIdentifierStr()).Msg("Unable to publish estimated position")
+				}
+				return true
+			})
+		case <-s.stopEstimates:
+			return
+		}
+	}
+}
+
 func (s *Sink) HealthCheckName() string {
 	return s.dest.HealthCheckName()
 }
@@ -0,0 +1,111 @@
+package beast
+
+import (
+	"time"
+)
+
+const (
+	// TicksPerSecond is how fast the 48 bit beast mlat counter runs, 12MHz
+	TicksPerSecond = 12000000
+
+	tickMask = 1<<48 - 1
+	// mlatMagicTicks is what mlat results put in the timestamp, FF004D4C4154 ("MLAT")
+	mlatMagicTicks = 0xFF004D4C4154
+
+	// maxClockError is how far a live clock can wander from the time we read the frame before we start again
+	maxClockError = 5 * time.Second
+	// driftWindow is roughly how many frames we average the difference between our clock and the wall clock over
+	driftWindow = 1000
+)
+
+// Clock turns the beast mlat timestamps from one source into wall time. The first frame anchors the 12MHz
+// counter to the time we read it, after that the counter decides how far apart our frames are.
+// A live clock slowly follows the wall clock to take out the drift between the receiver's oscillator and ours,
+// a replay clock does not as the frames were all read just now
+type Clock struct {
+	live bool
+
+	anchored    bool
+	anchorTicks uint64
+	anchorTime  time.Time
+	lastTicks   uint64
+
+	// offset is our running correction for drift
+	offset time.Duration
+}
+
+// NewClock gives us a Clock for a single source. Use live for network sources and not for files
+func NewClock(live bool) *Clock {
+	return &Clock{live: live}
+}
+
+// TimeStamp works out when frame f was received, received is when we read it
+func (c *Clock) TimeStamp(f *Frame, received time.Time) time.Time {
+	if nil == c || nil == f || len(f.mlatTimestamp) < 6 {
+		return received
+	}
+	if f.isRadarCape && !f.isMlat() {
+		return radarCapeTime(f.beastTicks(), received)
+	}
+	return c.Ticks(f.beastTicks(), received)
+}
+
+// Ticks works out when a frame with the 12MHz mlat timestamp ticks was received, received is when we read it.
+// This is also what AVR frames with an @ timestamp have
+func (c *Clock) Ticks(ticks uint64, received time.Time) time.Time {
+	if nil == c || 0 == ticks || mlatMagicTicks == ticks {
+		// some sources do not bother with timestamps, and mlat results carry a magic value, not a counter
+		return received
+	}
+
+	elapsed := (ticks - c.anchorTicks) & tickMask
+	if !c.anchored || (ticks-c.lastTicks)&tickMask > tickMask/2 {
+		// first frame, or our counter went backwards (receiver restart, new recording)
+		c.anchor(ticks, received)
+		return received
+	}
+	c.lastTicks = ticks
+
+	ts := c.anchorTime.Add(ticksToDuration(elapsed) + c.offset)
+	if c.live {
+		diff := received.Sub(ts)
+		if diff > maxClockError || diff < -maxClockError {
+			c.anchor(ticks, received)
+			return received
+		}
+		c.offset += diff / driftWindow
+	}
+	return ts
+}
+
+func (c *Clock) anchor(ticks uint64, received time.Time) {
+	c.anchored = true
+	c.anchorTicks = ticks
+	c.lastTicks = ticks
+	c.anchorTime = received
+	c.offset = 0
+}
+
+// ticksToDuration turns a count of 12MHz ticks into a time.Duration
+func ticksToDuration(ticks uint64) time.Duration {
+	seconds := ticks / TicksPerSecond
+	remainder := ticks % TicksPerSecond
+	return time.Duration(seconds)*time.Second + time.Duration(remainder*1000/12)
+}
+
+// radarCapeTime decodes a GPS timestamp, the top 18 bits are seconds since UTC midnight and the bottom 30 are
+// nanoseconds. The day comes from when we received it
+func radarCapeTime(ticks uint64, received time.Time) time.Time {
+	seconds := ticks >> 30
+	nanos := ticks & (1<<30 - 1)
+	utc := received.UTC()
+	midnight := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+	ts := midnight.Add(time.Duration(seconds)*time.Second + time.Duration(nanos))
+	// frames either side of midnight
+	if ts.Sub(utc) > 12*time.Hour {
+		ts = ts.Add(-24 * time.Hour)
+	} else if utc.Sub(ts) > 12*time.Hour {
+		ts = ts.Add(24 * time.Hour)
+	}
+	return ts
+}
@@ -0,0 +1,98 @@
+package beast
+
+import (
+	"testing"
+	"time"
+)
+
+// frameAt gives us a Mode S long frame with the given mlat timestamp
+func frameAt(ticks uint64) *Frame {
+	raw := make([]byte, len(beastModeSLong))
+	copy(raw, beastModeSLong)
+	for i := 0; i < 6; i++ {
+		raw[2+i] = byte(ticks >> (40 - 8*i))
+	}
+	return NewFrame(raw, false)
+}
+
+func TestClock_TimeStamp(t *testing.T) {
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		ticks    uint64
+		received time.Time
+		want     time.Time
+	}{
+		{name: "anchor", ticks: 1000, received: start, want: start},
+		{name: "half a second later", ticks: 1000 + TicksPerSecond/2, received: start, want: start.Add(500 * time.Millisecond)},
+		{name: "one tick", ticks: 1001 + TicksPerSecond/2, received: start, want: start.Add(500*time.Millisecond + 83*time.Nanosecond)},
+		{name: "a minute later", ticks: 1000 + 60*TicksPerSecond, received: start, want: start.Add(time.Minute)},
+		{name: "went backwards", ticks: 500, received: start.Add(time.Second), want: start.Add(time.Second)},
+		{name: "after going backwards", ticks: 500 + 2*TicksPerSecond, received: start, want: start.Add(3 * time.Second)},
+	}
+	c := NewClock(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := frameAt(tt.ticks)
+			if got := c.TimeStamp(f, tt.received); !got.Equal(tt.want) {
+				t.Errorf("TimeStamp() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClock_TimeStampWraps(t *testing.T) {
+	start := time.Now()
+	c := NewClock(false)
+	c.TimeStamp(frameAt(tickMask-TicksPerSecond+1), start)
+	if got := c.TimeStamp(frameAt(TicksPerSecond), start); !got.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("Expected our counter to wrap around, got %s after the anchor", got.Sub(start))
+	}
+}
+
+func TestClock_TimeStampLive(t *testing.T) {
+	start := time.Now()
+	c := NewClock(true)
+	c.TimeStamp(frameAt(1000), start)
+
+	// our receiver's clock is a little slow, we should slowly follow the wall clock
+	ts := start
+	for i := 1; i <= 100; i++ {
+		received := start.Add(time.Duration(i) * 101 * time.Millisecond)
+		ts = c.TimeStamp(frameAt(1000+uint64(i)*TicksPerSecond/10), received)
+	}
+	counter := start.Add(10 * time.Second)
+	if !ts.After(counter) || ts.After(start.Add(10100*time.Millisecond)) {
+		t.Errorf("Expected to be between the counter and the wall clock, got %s", ts.Sub(start))
+	}
+
+	// something happened to our receiver, start again
+	received := start.Add(time.Hour)
+	if got := c.TimeStamp(frameAt(1000+101*TicksPerSecond/10), received); !got.Equal(received) {
+		t.Errorf("Expected to start again at %s, got %s", received, got)
+	}
+}
+
+func TestClock_TimeStampNoCounter(t *testing.T) {
+	now := time.Now()
+	c := NewClock(true)
+	if got := c.TimeStamp(frameAt(0), now); !got.Equal(now) {
+		t.Error("Frames without a timestamp should use the time we received them")
+	}
+	mlat := frameAt(0xFF004D4C4154)
+	if got := c.TimeStamp(mlat, now); !got.Equal(now) {
+		t.Error("MLAT results should use the time we received them")
+	}
+}
+
+func TestFrame_SetTimeStamp(t *testing.T) {
+	ts := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	f := frameAt(1000)
+	f.SetTimeStamp(ts)
+	if !f.TimeStamp().Equal(ts) {
+		t.Errorf("Expected frame time %s, got %s", ts, f.TimeStamp())
+	}
+	if !f.AvrFrame().TimeStamp().Equal(ts) {
+		t.Errorf("Expected the Mode S frame time to be %s, got %s", ts, f.AvrFrame().TimeStamp())
+	}
+}
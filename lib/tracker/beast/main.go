@@ -15,10 +15,12 @@ type (
 		mlatTimestamp []byte
 		signalLevel   byte
 		body          []byte
+		timeStamp     time.Time
 
-		isRadarCape  bool
-		hasDecoded   bool
-		decodedModeS *mode_s.Frame
+		isRadarCape   bool
+		hasDecoded    bool
+		decodedModeS  *mode_s.Frame
+		decodedModeAc *mode_s.ModeAc
 	}
 )
 
@@ -29,6 +31,10 @@ func (f *Frame) Icao() uint32 {
 	if !f.hasDecoded {
 		_, _ = f.Decode()
 	}
+	if nil == f.decodedModeS {
+		// Mode A/C replies do not have an address
+		return 0
+	}
 	return f.decodedModeS.Icao()
 }
 
@@ -39,6 +45,9 @@ func (f *Frame) IcaoStr() string {
 	if !f.hasDecoded {
 		_, _ = f.Decode()
 	}
+	if nil == f.decodedModeS {
+		return ""
+	}
 	return f.decodedModeS.IcaoStr()
 }
 
@@ -47,12 +56,45 @@ func (f *Frame) Decode() (bool, error) {
 		return false, errors.New("nil frame")
 	}
 	f.hasDecoded = true
-	return f.decodedModeS.Decode()
+	if f.IsModeAc() {
+		return nil != f.decodedModeAc, nil
+	}
+	ok, err := f.decodedModeS.Decode()
+	if nil == err && f.decodedModeS.Corrected() {
+		// keep our raw bytes in step, so anything passing them on sends the fixed message
+		copy(f.body, f.decodedModeS.Message())
+	}
+	return ok, err
 }
 
+// SetErrorCorrection sets how hard we try to fix the Mode S reply in this frame, call it before Decode
+func (f *Frame) SetErrorCorrection(level mode_s.ErrorCorrection) {
+	if nil == f {
+		return
+	}
+	f.decodedModeS.SetErrorCorrection(level)
+}
+
+// TimeStamp is when we received this frame, use a Clock and SetTimeStamp to work it out from the mlat timestamp
 func (f *Frame) TimeStamp() time.Time {
-	// todo: calculate this off the mlat timestamp
-	return time.Now()
+	if nil == f {
+		return time.Time{}
+	}
+	return f.timeStamp
+}
+
+// SetTimeStamp sets when we received this frame, along with the Mode S or Mode A/C reply inside it
+func (f *Frame) SetTimeStamp(t time.Time) {
+	if nil == f {
+		return
+	}
+	f.timeStamp = t
+	if nil != f.decodedModeS {
+		f.decodedModeS.SetTimeStamp(t)
+	}
+	if nil != f.decodedModeAc {
+		f.decodedModeAc.SetTimeStamp(t)
+	}
 }
 
 func (f *Frame) Raw() []byte {
@@ -82,6 +124,7 @@ func newBeastMsg(rawBytes []byte) *Frame {
 		mlatTimestamp: rawBytes[2:8],
 		signalLevel:   rawBytes[8],
 		body:          rawBytes[9:],
+		timeStamp:     time.Now(),
 	}
 }
 
@@ -93,7 +136,7 @@ func NewFrame(rawBytes []byte, isRadarCape bool) *Frame {
 		switch f.msgType {
 		case 0x31:
 			// mode-ac 10 bytes (2+8)
-			f.decodeModeAc()
+			f.decodedModeAc = f.decodeModeAc()
 		case 0x32:
 			// mode-s short 15 bytes
 			f.decodedModeS = f.decodeModeSShort()
@@ -112,45 +155,53 @@ func NewFrame(rawBytes []byte, isRadarCape bool) *Frame {
 	return nil
 }
 
-func (f *Frame) decodeModeAc() {
-	// TODO: Decode ModeAC
-}
-
-func (f *Frame) decodeModeSShort() *mode_s.Frame {
-	if nil == f {
+func (f *Frame) decodeModeAc() *mode_s.ModeAc {
+	if nil == f || len(f.body) < 2 {
 		return nil
 	}
+	return mode_s.NewModeAc(uint16(f.body[0])<<8|uint16(f.body[1]), f.timeStamp)
+}
 
-	return mode_s.NewFrame(f.avr(), time.Now())
+func (f *Frame) decodeModeSShort() *mode_s.Frame {
+	return f.decodeModeS()
 }
 
 func (f *Frame) decodeModeSLong() *mode_s.Frame {
+	return f.decodeModeS()
+}
+
+// decodeModeS hands the body straight to the mode_s decoder, there is no need to go via an AVR hex string
+func (f *Frame) decodeModeS() *mode_s.Frame {
 	if nil == f {
 		return nil
 	}
-	return mode_s.NewFrame(f.avr(), time.Now())
+	fr := mode_s.NewFrameFromBytes(f.body, f.timeStamp)
+	if nil == fr {
+		return nil
+	}
+	fr.SetBeastTicks(f.beastTicks())
+	fr.SetMlat(f.isMlat())
+	return fr
 }
 
 func (f *Frame) decodeConfig() {
 	// TODO: Decode RadarCape Config Info
 }
 
-func (f *Frame) avr() string {
-	if nil == f {
-		return ""
-	}
-	return fmt.Sprintf("@%X%X;", f.mlatTimestamp, f.body)
-}
-
 // BeastTicksNs returns the number of nanoseconds the beast has been on for (the mlat timestamp is calculated from power on)
 func (f *Frame) BeastTicksNs() time.Duration {
+	return ticksToDuration(f.beastTicks())
+}
+
+// beastTicks is the raw 48 bit mlat timestamp
+func (f *Frame) beastTicks() uint64 {
 	var t uint64
 	inc := 40
 	for i := 0; i < 6; i++ {
 		t = t | uint64(f.mlatTimestamp[i])<<inc
 		inc -= 8
 	}
-	return time.Duration(t * 500)
+	return t
 }
 
 func (f *Frame) String() string {
@@ -194,6 +245,21 @@ func (f *Frame) AvrFrame() *mode_s.Frame {
 	return f.decodedModeS
 }
 
+// IsModeAc tells us if this is a Mode A/C reply, use ModeAcFrame() to get at it
+func (f *Frame) IsModeAc() bool {
+	if nil == f {
+		return false
+	}
+	return 0x31 == f.msgType
+}
+
+func (f *Frame) ModeAcFrame() *mode_s.ModeAc {
+	if nil == f {
+		return nil
+	}
+	return f.decodedModeAc
+}
+
 func (f *Frame) AvrRaw() []byte {
 	if nil == f {
 		return nil
@@ -2,7 +2,9 @@ package beast
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"plane.watch/lib/tracker/mode_s"
 	"reflect"
 	"testing"
 )
@@ -133,3 +135,44 @@ func TestFrame_SignalRssi(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFrameModeAC(t *testing.T) {
+	raw := []byte{0x1A, 0x31, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x77, 0x00}
+	f := NewFrame(raw, false)
+	if !f.IsModeAc() {
+		t.Fatal("Expected a Mode A/C frame")
+	}
+	ok, err := f.Decode()
+	if !ok || nil != err {
+		t.Errorf("Failed to decode Mode A/C frame: %s", err)
+	}
+	if 0 != f.Icao() {
+		t.Errorf("Mode A/C frames do not have an ICAO, got %06X", f.Icao())
+	}
+	if 7700 != f.ModeAcFrame().SquawkIdentity() {
+		t.Errorf("Expected squawk 7700, got %04d", f.ModeAcFrame().SquawkIdentity())
+	}
+}
+
+func TestFrame_ErrorCorrection(t *testing.T) {
+	good, _ := hex.DecodeString("8DA05629EA21485CBF3F8CADAEEB")
+	bad, _ := hex.DecodeString("8DA05629EA21485CBF3F8DADAEEB") // 1 bit error
+	raw := append([]byte{0x1a, 0x33, 0x22, 0x1b, 0x54, 0xac, 0xc2, 0xe9, 0x28}, bad...)
+
+	f := NewFrame(append([]byte{}, raw...), false)
+	if _, err := f.Decode(); nil == err {
+		t.Error("Expected a bad checksum without error correction")
+	}
+
+	f = NewFrame(append([]byte{}, raw...), false)
+	f.SetErrorCorrection(mode_s.ErrorCorrectionSingleBit)
+	if _, err := f.Decode(); nil != err {
+		t.Fatalf("Expected the bit error to be fixed, got %s", err)
+	}
+	if !f.AvrFrame().Corrected() {
+		t.Error("Expected the frame to be marked as corrected")
+	}
+	if !bytes.Equal(good, f.AvrRaw()) || !bytes.Equal(good, f.Raw()[9:]) {
+		t.Errorf("Expected the raw bytes to be corrected to %X, got %X", good, f.Raw())
+	}
+}
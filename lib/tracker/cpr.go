@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"plane.watch/lib/geo"
 	"sync"
 	"time"
 )
@@ -451,12 +452,70 @@ func cprModFunction(a, b int32) float64 {
 	return res
 }
 
+// decodeLocal decodes our latest CPR frame on its own, relative to a reference position that we know is nearby.
+// The aircraft has to be within half a zone of the reference (180nm airborne, 45nm on the surface) for the result
+// to be unambiguous. maxRange (metres) is how far from the reference the aircraft can be, 0 for half a zone.
+// A maxRange of more than half a zone cannot be decoded, as we would not know which zone the aircraft is in
+func (cpr *CprLocation) decodeLocal(refLat, refLon float64, onGround bool, maxRange float64) (*PlaneLocation, error) {
+	cpr.rwLock.RLock()
+	defer cpr.rwLock.RUnlock()
+
+	unambiguous := float64(maxLocalCprRange * metresPerNauticalMile)
+	if onGround {
+		unambiguous = float64(maxLocalSurfaceCprRange * metresPerNauticalMile)
+	}
+	if 0 == maxRange {
+		maxRange = unambiguous
+	}
+	if maxRange > unambiguous {
+		return nil, fmt.Errorf("cannot locally decode CPR for aircraft up to %0.0fm from the reference, only %0.0fm is unambiguous", maxRange, unambiguous)
+	}
+
+	var cprLat, cprLon float64
+	var isOdd int32
+	if cpr.oddFrame && (!cpr.evenFrame || cpr.time1.After(cpr.time0)) {
+		cprLat, cprLon, isOdd = cpr.oddLat, cpr.oddLon, 1
+	} else if cpr.evenFrame {
+		cprLat, cprLon = cpr.evenLat, cpr.evenLon
+	} else {
+		return nil, nil
+	}
+
+	zoneRange := 360.0
+	if onGround {
+		zoneRange = 90.0
+	}
+	fractionalLat := cprLat / 131072
+	fractionalLon := cprLon / 131072
+
+	dLat := zoneRange / float64(60-isOdd)
+	j := math.Floor(refLat/dLat) + math.Floor(0.5+cprModFloatFunction(refLat, dLat)/dLat-fractionalLat)
+	lat := dLat * (j + fractionalLat)
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("failed to locally decode CPR, lat %0.6f is out of range", lat)
+	}
+
+	dLon := zoneRange / float64(cprNFunction(lat, isOdd))
+	m := math.Floor(refLon/dLon) + math.Floor(0.5+cprModFloatFunction(refLon, dLon)/dLon-fractionalLon)
+	lon := dLon * (m + fractionalLon)
+	lon -= math.Floor((lon+180.0)/360.0) * 360.0
+
+	if d := geo.Distance(lat, lon, refLat, refLon); d > maxRange {
+		return nil, fmt.Errorf("locally decoded CPR {%0.4f,%0.4f} is %0.0fm from the reference, more than %0.0fm", lat, lon, d, maxRange)
+	}
+	return &PlaneLocation{latitude: lat, longitude: lon, onGround: onGround}, nil
+}
+
+/* Always positive MOD operation for floats, used for local CPR decoding. */
+func cprModFloatFunction(a, b float64) float64 {
+	res := math.Mod(a, b)
+	if res < 0 {
+		res += b
+	}
+	return res
+}
+
 func (pl *PlaneLocation) SetDirection(heading float64, speed int32) {
 	pl.heading = heading
 	pl.velocity = float64(speed)
 }
-
-// haversin(θ) function
-func hsin(theta float64) float64 {
-	return math.Pow(math.Sin(theta/2), 2)
-}
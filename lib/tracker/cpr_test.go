@@ -346,3 +346,146 @@ func TestCprDecode2(t *testing.T) {
 	}
 
 }
+
+func TestCprDecodeLocal(t *testing.T) {
+	tests := []struct {
+		name           string
+		avr            string
+		onGround       bool
+		refLat, refLon float64
+		maxRange       float64
+		lat, lon       string
+		wantErr        bool
+	}{
+		// examples taken from https://mode-s.org/decode/content/ads-b/3-airborne-position.html
+		{name: "Airborne Even", avr: "*8D40621D58C382D690C8AC2863A7;", refLat: 52.258, refLon: 3.918, lat: "+52.257202", lon: "+3.919373"},
+		{name: "Airborne Odd", avr: "*8D40621D58C386435CC412692AD6;", refLat: 52.258, refLon: 3.918, lat: "+52.265780", lon: "+3.938913"},
+		{name: "Airborne In Range", avr: "*8D40621D58C382D690C8AC2863A7;", refLat: 51.5, refLon: 3, maxRange: 100 * metresPerNauticalMile, lat: "+52.257202", lon: "+3.919373"},
+		{name: "Airborne Out Of Range", avr: "*8D40621D58C382D690C8AC2863A7;", refLat: 51.5, refLon: 3, maxRange: 10 * metresPerNauticalMile, wantErr: true},
+		// and https://mode-s.org/decode/content/ads-b/4-surface-position.html
+		{name: "Surface Even", avr: "*8C4841753AAB238733C8CD4020B1;", onGround: true, refLat: 51.990, refLon: 4.375, lat: "+52.323040", lon: "+4.730473"},
+		{name: "Surface Odd", avr: "*8C4841753A8A35323FAEBDAC702D;", onGround: true, refLat: 51.990, refLon: 4.375, lat: "+52.320607", lon: "+4.734735"},
+		{name: "Surface In Range", avr: "*8C4841753AAB238733C8CD4020B1;", onGround: true, refLat: 51.990, refLon: 4.375, maxRange: 45 * metresPerNauticalMile, lat: "+52.323040", lon: "+4.730473"},
+		// the aircraft is ~80nm from the reference, which would put it in the wrong zone
+		{name: "Surface Range Is Ambiguous", avr: "*8C4841753AAB238733C8CD4020B1;", onGround: true, refLat: 51.0, refLon: 4.7, maxRange: 180 * metresPerNauticalMile, wantErr: true},
+		{name: "Airborne Range Is Ambiguous", avr: "*8D40621D58C382D690C8AC2863A7;", refLat: 51.5, refLon: 3, maxRange: 200 * metresPerNauticalMile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := mode_s.DecodeString(tt.avr, time.Now())
+			if nil != err {
+				t.Fatal(err)
+			}
+			cpr := CprLocation{}
+			if frame.IsEven() {
+				err = cpr.SetEvenLocation(float64(frame.Latitude()), float64(frame.Longitude()), frame.TimeStamp())
+			} else {
+				err = cpr.SetOddLocation(float64(frame.Latitude()), float64(frame.Longitude()), frame.TimeStamp())
+			}
+			if nil != err {
+				t.Fatal(err)
+			}
+
+			loc, err := cpr.decodeLocal(tt.refLat, tt.refLon, tt.onGround, tt.maxRange)
+			if tt.wantErr {
+				if nil == err || nil != loc {
+					t.Errorf("Expected the local decode to fail, got %v", loc)
+				}
+				return
+			}
+			if nil != err || nil == loc {
+				t.Fatalf("Failed to locally decode: %v", err)
+			}
+			if lat := fmt.Sprintf("%+0.6f", loc.latitude); lat != tt.lat {
+				t.Errorf("Incorrect latitude, want %s, got %s", tt.lat, lat)
+			}
+			if lon := fmt.Sprintf("%+0.6f", loc.longitude); lon != tt.lon {
+				t.Errorf("Incorrect longitude, want %s, got %s", tt.lon, lon)
+			}
+			if loc.onGround != tt.onGround {
+				t.Errorf("Incorrect on ground, want %t", tt.onGround)
+			}
+		})
+	}
+}
+
+func TestPlane_decodeCprSingleFrame(t *testing.T) {
+	now := time.Now()
+	trk := NewTracker()
+	plane := trk.GetPlane(0x40621D)
+
+	// a single frame is not enough to know where we are
+	odd, _ := mode_s.DecodeString("*8D40621D58C386435CC412692AD6;", now)
+	plane.HandleModeSFrame(odd, nil, nil)
+	if plane.HasLocation() {
+		t.Fatal("Should not have a location from a single frame")
+	}
+
+	// once we have a global decode, every frame after that gives us a new location
+	even, _ := mode_s.DecodeString("*8D40621D58C382D690C8AC2863A7;", now.Add(time.Second))
+	plane.HandleModeSFrame(even, nil, nil)
+	if "+52.265780,+3.938913" != fmt.Sprintf("%+0.6f,%+0.6f", plane.Lat(), plane.Lon()) {
+		t.Errorf("Incorrect global decode, got %0.6f,%0.6f", plane.Lat(), plane.Lon())
+	}
+
+	even, _ = mode_s.DecodeString("*8D40621D58C382D690C8AC2863A7;", now.Add(10*time.Second))
+	plane.HandleModeSFrame(even, nil, nil)
+	if "+52.257202,+3.919373" != fmt.Sprintf("%+0.6f,%+0.6f", plane.Lat(), plane.Lon()) {
+		t.Errorf("Incorrect local decode, got %0.6f,%0.6f", plane.Lat(), plane.Lon())
+	}
+	if 2 != len(plane.LocationHistory()) {
+		t.Errorf("Expected 2 locations, got %d", len(plane.LocationHistory()))
+	}
+}
+
+func TestPlane_decodeCprReceiverRange(t *testing.T) {
+	refLat, refLon := 52.0, 4.0
+	for _, tt := range []struct {
+		name        string
+		opts        []Option
+		hasLocation bool
+	}{
+		{name: "Without Receiver Range"},
+		{name: "With Receiver Range", opts: []Option{WithReceiverRange(100)}, hasLocation: true},
+		{name: "Too Far From Receiver", opts: []Option{WithReceiverRange(5)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			trk := NewTracker(tt.opts...)
+			plane := trk.GetPlane(0x40621D)
+			frame, _ := mode_s.DecodeString("*8D40621D58C382D690C8AC2863A7;", time.Now())
+			plane.HandleModeSFrame(frame, &refLat, &refLon)
+			if tt.hasLocation != plane.HasLocation() {
+				t.Errorf("Expected has location %t", tt.hasLocation)
+			}
+		})
+	}
+}
+
+func TestPlane_decodeCprReceiverRangeSurface(t *testing.T) {
+	// the aircraft is at {52.3230,4.7305}
+	for _, tt := range []struct {
+		name           string
+		receiverRange  float64
+		refLat, refLon float64
+		hasLocation    bool
+	}{
+		{name: "Close To The Receiver", receiverRange: 40, refLat: 51.990, refLon: 4.375, hasLocation: true},
+		// the aircraft could be more than half a surface zone away, so we cannot tell which zone it is in
+		{name: "Receiver Range Too Big", receiverRange: 100, refLat: 51.990, refLon: 4.375},
+		// ~80nm away, which would decode to {50.8230,4.7305} if we did not check
+		{name: "More Than 45nm From The Receiver", receiverRange: 100, refLat: 51.0, refLon: 4.7},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			trk := NewTracker(WithReceiverRange(tt.receiverRange))
+			plane := trk.GetPlane(0x484175)
+			frame, _ := mode_s.DecodeString("*8C4841753AAB238733C8CD4020B1;", time.Now())
+			plane.HandleModeSFrame(frame, &tt.refLat, &tt.refLon)
+			if tt.hasLocation != plane.HasLocation() {
+				t.Fatalf("Expected has location %t, got {%0.4f,%0.4f}", tt.hasLocation, plane.Lat(), plane.Lon())
+			}
+			if tt.hasLocation && "+52.3230,+4.7305" != fmt.Sprintf("%+0.4f,%+0.4f", plane.Lat(), plane.Lon()) {
+				t.Errorf("Incorrect location {%0.4f,%0.4f}", plane.Lat(), plane.Lon())
+			}
+		})
+	}
+}
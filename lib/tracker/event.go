@@ -2,12 +2,21 @@ package tracker
 
 import (
 	"fmt"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/geofence"
+	"plane.watch/lib/tracker/mode_s"
 	"time"
 )
 
 const LogEventType = "log-event"
 const PlaneLocationEventType = "plane-location-event"
 const InfoEventType = "info-event"
+const WeatherReportEventType = "weather-report-event"
+const AcasRaEventType = "acas-ra-event"
+const EmergencyEventType = "emergency-event"
+const FlightPhaseEventType = "flight-phase-event"
+const MovementEventType = "movement-event"
+const GeofenceEventType = "geofence-event"
 
 type (
 	// Event is something that we want to know about. This is the base of our sending of data
@@ -45,6 +54,76 @@ type (
 		RefLat, RefLon   *float64
 	}
 
+	// WeatherReportEvent is sent when a plane tells us about the weather it is flying through (BDS 4,4 and 4,5)
+	WeatherReportEvent struct {
+		p     *Plane
+		frame *mode_s.Frame
+
+		// where the plane was when it made the report
+		lat, lon      float64
+		hasLocation   bool
+		altitude      int32
+		altitudeUnits string
+	}
+
+	// AcasRaEvent is sent when a plane reports an ACAS (TCAS) Resolution Advisory (BDS 3,0, DF16 or DF17 Type 28)
+	AcasRaEvent struct {
+		p      *Plane
+		threat *Plane // nil if the threat is not identified by ICAO or is not one we are tracking
+		frame  *mode_s.Frame
+
+		// where the plane was when it reported the RA
+		lat, lon      float64
+		hasLocation   bool
+		altitude      int32
+		altitudeUnits string
+	}
+
+	// EmergencyEvent is sent when a plane enters, changes or leaves an emergency state
+	EmergencyEvent struct {
+		p                   *Plane
+		previous, emergency mode_s.EmergencyState
+		when                time.Time
+
+		// where the plane was when the emergency state changed
+		lat, lon      float64
+		hasLocation   bool
+		altitude      int32
+		altitudeUnits string
+	}
+
+	// FlightPhaseEvent is sent when a plane moves from one phase of its flight to another
+	FlightPhaseEvent struct {
+		p               *Plane
+		previous, phase FlightPhase
+		when            time.Time
+
+		// where the plane was when it changed phase
+		lat, lon      float64
+		hasLocation   bool
+		altitude      int32
+		altitudeUnits string
+	}
+
+	// MovementEvent is sent when a plane takes off from or lands at an airport we know about
+	MovementEvent struct {
+		p       *Plane
+		kind    MovementKind
+		airport *airports.Airport
+		runway  *airports.RunwayEnd
+		when    time.Time
+
+		lat, lon   float64
+		heading    float64
+		hasHeading bool
+	}
+
+	// GeofenceEvent is sent when a plane goes into, comes out of or has dwelled in one of our geofences
+	GeofenceEvent struct {
+		p *Plane
+		e geofence.Event
+	}
+
 	// InfoEvent periodically sends out some interesting stats
 	InfoEvent struct {
 		receivedFrames uint64
@@ -67,6 +146,12 @@ func (t *Tracker) processEvents() {
 		for _, sink := range t.sinks {
 			sink.OnEvent(e)
 		}
+		// geofence events go straight to our sinks, we are the only reader of t.events so cannot add to it
+		for _, fe := range t.checkGeofences(e) {
+			for _, sink := range t.sinks {
+				sink.OnEvent(fe)
+			}
+		}
 	}
 	t.eventsWaiter.Done()
 }
@@ -126,6 +211,337 @@ func (f *FrameEvent) Source() *FrameSource {
 	return f.source
 }
 
+func newWeatherReportEvent(p *Plane, frame *mode_s.Frame) *WeatherReportEvent {
+	e := &WeatherReportEvent{
+		p:             p,
+		frame:         frame,
+		lat:           p.Lat(),
+		lon:           p.Lon(),
+		hasLocation:   p.HasLocation(),
+		altitude:      p.Altitude(),
+		altitudeUnits: p.AltitudeUnits(),
+	}
+	// the reply itself has a more recent altitude than what we have been tracking
+	if frame.AltitudeValid() {
+		e.altitude, _ = frame.Altitude()
+		e.altitudeUnits = frame.AltitudeUnits()
+	}
+	return e
+}
+
+func (w *WeatherReportEvent) Type() string {
+	return WeatherReportEventType
+}
+func (w *WeatherReportEvent) String() string {
+	return fmt.Sprintf("Weather Report (BDS %s) from %s", w.frame.BdsMessageType(), w.p.IcaoIdentifierStr())
+}
+func (w *WeatherReportEvent) Plane() *Plane {
+	return w.p
+}
+
+// Frame is the Comm-B reply containing the weather data
+func (w *WeatherReportEvent) Frame() *mode_s.Frame {
+	return w.frame
+}
+func (w *WeatherReportEvent) HasLocation() bool {
+	return w.hasLocation
+}
+func (w *WeatherReportEvent) Lat() float64 {
+	return w.lat
+}
+func (w *WeatherReportEvent) Lon() float64 {
+	return w.lon
+}
+func (w *WeatherReportEvent) Altitude() int32 {
+	return w.altitude
+}
+func (w *WeatherReportEvent) AltitudeUnits() string {
+	return w.altitudeUnits
+}
+
+func newAcasRaEvent(p, threat *Plane, frame *mode_s.Frame) *AcasRaEvent {
+	e := &AcasRaEvent{
+		p:             p,
+		threat:        threat,
+		frame:         frame,
+		lat:           p.Lat(),
+		lon:           p.Lon(),
+		hasLocation:   p.HasLocation(),
+		altitude:      p.Altitude(),
+		altitudeUnits: p.AltitudeUnits(),
+	}
+	if frame.AltitudeValid() {
+		e.altitude, _ = frame.Altitude()
+		e.altitudeUnits = frame.AltitudeUnits()
+	}
+	return e
+}
+
+func (a *AcasRaEvent) Type() string {
+	return AcasRaEventType
+}
+func (a *AcasRaEvent) String() string {
+	if nil != a.threat {
+		return fmt.Sprintf("ACAS RA from %s against %s", a.p.IcaoIdentifierStr(), a.threat.IcaoIdentifierStr())
+	}
+	return fmt.Sprintf("ACAS RA from %s", a.p.IcaoIdentifierStr())
+}
+func (a *AcasRaEvent) Plane() *Plane {
+	return a.p
+}
+
+// Threat is the other aircraft involved in the RA, nil if we do not know who it is
+func (a *AcasRaEvent) Threat() *Plane {
+	return a.threat
+}
+func (a *AcasRaEvent) Frame() *mode_s.Frame {
+	return a.frame
+}
+func (a *AcasRaEvent) HasLocation() bool {
+	return a.hasLocation
+}
+func (a *AcasRaEvent) Lat() float64 {
+	return a.lat
+}
+func (a *AcasRaEvent) Lon() float64 {
+	return a.lon
+}
+func (a *AcasRaEvent) Altitude() int32 {
+	return a.altitude
+}
+func (a *AcasRaEvent) AltitudeUnits() string {
+	return a.altitudeUnits
+}
+
+func newEmergencyEvent(p *Plane, previous, emergency mode_s.EmergencyState, when time.Time) *EmergencyEvent {
+	return &EmergencyEvent{
+		p:             p,
+		previous:      previous,
+		emergency:     emergency,
+		when:          when,
+		lat:           p.Lat(),
+		lon:           p.Lon(),
+		hasLocation:   p.HasLocation(),
+		altitude:      p.Altitude(),
+		altitudeUnits: p.AltitudeUnits(),
+	}
+}
+
+func (e *EmergencyEvent) Type() string {
+	return EmergencyEventType
+}
+func (e *EmergencyEvent) String() string {
+	if e.Exited() {
+		return fmt.Sprintf("%s is no longer in an emergency (was %s)", e.p.IcaoIdentifierStr(), e.previous)
+	}
+	return fmt.Sprintf("%s is in an emergency: %s", e.p.IcaoIdentifierStr(), e.emergency)
+}
+func (e *EmergencyEvent) Plane() *Plane {
+	return e.p
+}
+
+// Emergency is the state the plane is now in, EmergencyNone if it has left its emergency
+func (e *EmergencyEvent) Emergency() mode_s.EmergencyState {
+	return e.emergency
+}
+
+// Previous is the state the plane was in before this event
+func (e *EmergencyEvent) Previous() mode_s.EmergencyState {
+	return e.previous
+}
+
+// Entered is true when the plane is now in an emergency
+func (e *EmergencyEvent) Entered() bool {
+	return e.emergency.IsEmergency()
+}
+
+// Exited is true when the plane has left its emergency
+func (e *EmergencyEvent) Exited() bool {
+	return !e.emergency.IsEmergency()
+}
+func (e *EmergencyEvent) When() time.Time {
+	return e.when
+}
+func (e *EmergencyEvent) HasLocation() bool {
+	return e.hasLocation
+}
+func (e *EmergencyEvent) Lat() float64 {
+	return e.lat
+}
+func (e *EmergencyEvent) Lon() float64 {
+	return e.lon
+}
+func (e *EmergencyEvent) Altitude() int32 {
+	return e.altitude
+}
+func (e *EmergencyEvent) AltitudeUnits() string {
+	return e.altitudeUnits
+}
+
+func newFlightPhaseEvent(p *Plane, previous, phase FlightPhase, when time.Time) *FlightPhaseEvent {
+	return &FlightPhaseEvent{
+		p:             p,
+		previous:      previous,
+		phase:         phase,
+		when:          when,
+		lat:           p.Lat(),
+		lon:           p.Lon(),
+		hasLocation:   p.HasLocation(),
+		altitude:      p.Altitude(),
+		altitudeUnits: p.AltitudeUnits(),
+	}
+}
+
+func (f *FlightPhaseEvent) Type() string {
+	return FlightPhaseEventType
+}
+func (f *FlightPhaseEvent) String() string {
+	return fmt.Sprintf("%s has gone from %s to %s", f.p.IcaoIdentifierStr(), f.previous, f.phase)
+}
+func (f *FlightPhaseEvent) Plane() *Plane {
+	return f.p
+}
+
+// Phase is the phase the plane is now in
+func (f *FlightPhaseEvent) Phase() FlightPhase {
+	return f.phase
+}
+
+// Previous is the phase the plane was in before this event
+func (f *FlightPhaseEvent) Previous() FlightPhase {
+	return f.previous
+}
+
+// Departure is true when the plane has just left the ground
+func (f *FlightPhaseEvent) Departure() bool {
+	return f.previous.OnGround() && f.phase.Airborne()
+}
+
+// Arrival is true when the plane has just come down out of the sky
+func (f *FlightPhaseEvent) Arrival() bool {
+	return f.previous.Airborne() && f.phase.OnGround()
+}
+func (f *FlightPhaseEvent) When() time.Time {
+	return f.when
+}
+func (f *FlightPhaseEvent) HasLocation() bool {
+	return f.hasLocation
+}
+func (f *FlightPhaseEvent) Lat() float64 {
+	return f.lat
+}
+func (f *FlightPhaseEvent) Lon() float64 {
+	return f.lon
+}
+func (f *FlightPhaseEvent) Altitude() int32 {
+	return f.altitude
+}
+func (f *FlightPhaseEvent) AltitudeUnits() string {
+	return f.altitudeUnits
+}
+
+func newMovementEvent(p *Plane, kind MovementKind, airport *airports.Airport, runway *airports.RunwayEnd, when time.Time) *MovementEvent {
+	return &MovementEvent{
+		p:          p,
+		kind:       kind,
+		airport:    airport,
+		runway:     runway,
+		when:       when,
+		lat:        p.Lat(),
+		lon:        p.Lon(),
+		heading:    p.Heading(),
+		hasHeading: p.HasHeading(),
+	}
+}
+
+func (m *MovementEvent) Type() string {
+	return MovementEventType
+}
+func (m *MovementEvent) String() string {
+	runway := "an unknown runway"
+	if nil != m.runway {
+		runway = "runway " + m.runway.Ident
+	}
+	return fmt.Sprintf("%s %s %s (%s) on %s", m.p.IcaoIdentifierStr(), m.kind.verb(), m.airport.Ident, m.airport.Name, runway)
+}
+func (m *MovementEvent) Plane() *Plane {
+	return m.p
+}
+
+// Kind tells us if the plane took off or landed
+func (m *MovementEvent) Kind() MovementKind {
+	return m.kind
+}
+func (m *MovementEvent) Airport() *airports.Airport {
+	return m.airport
+}
+
+// Runway is the runway end the plane used, nil if we could not work it out
+func (m *MovementEvent) Runway() *airports.RunwayEnd {
+	return m.runway
+}
+func (m *MovementEvent) When() time.Time {
+	return m.when
+}
+func (m *MovementEvent) Lat() float64 {
+	return m.lat
+}
+func (m *MovementEvent) Lon() float64 {
+	return m.lon
+}
+func (m *MovementEvent) Heading() float64 {
+	return m.heading
+}
+func (m *MovementEvent) HasHeading() bool {
+	return m.hasHeading
+}
+
+func newGeofenceEvent(p *Plane, e geofence.Event) *GeofenceEvent {
+	return &GeofenceEvent{p: p, e: e}
+}
+
+func (g *GeofenceEvent) Type() string {
+	return GeofenceEventType
+}
+func (g *GeofenceEvent) String() string {
+	return fmt.Sprintf("%s geofence %s: %s", g.p.IcaoIdentifierStr(), g.e.Kind, g.e.Fence)
+}
+func (g *GeofenceEvent) Plane() *Plane {
+	return g.p
+}
+
+// Kind tells us if the plane went in, came out or has been in the fence for its dwell time
+func (g *GeofenceEvent) Kind() geofence.Kind {
+	return g.e.Kind
+}
+func (g *GeofenceEvent) Fence() *geofence.Fence {
+	return g.e.Fence
+}
+
+// Event is the geofence engine's view of what happened
+func (g *GeofenceEvent) Event() geofence.Event {
+	return g.e
+}
+
+// Entered is when the plane went into the fence
+func (g *GeofenceEvent) Entered() time.Time {
+	return g.e.Entered
+}
+func (g *GeofenceEvent) When() time.Time {
+	return g.e.When
+}
+func (g *GeofenceEvent) Lat() float64 {
+	return g.e.Lat
+}
+func (g *GeofenceEvent) Lon() float64 {
+	return g.e.Lon
+}
+
+// Altitude is in feet, nil when we did not know it
+func (g *GeofenceEvent) Altitude() *float64 {
+	return g.e.Altitude
+}
+
 func (i *InfoEvent) Type() string {
 	return InfoEventType
 }
@@ -0,0 +1,43 @@
+package tracker
+
+// checkGeofences runs a plane location event past our geofences, giving us a GeofenceEvent for every fence the
+// plane has gone in or out of
+func (t *Tracker) checkGeofences(e Event) []Event {
+	if nil == t.geofences {
+		return nil
+	}
+	le, ok := e.(*PlaneLocationEvent)
+	if !ok {
+		return nil
+	}
+	p := le.Plane()
+	if le.Removed() {
+		t.geofences.Forget(p.IcaoIdentifierStr())
+		return nil
+	}
+	if !p.HasLocation() {
+		return nil
+	}
+
+	p.rwLock.RLock()
+	icao, lat, lon, when := p.icao, p.location.latitude, p.location.longitude, p.lastSeen
+	var altitude *float64
+	if "" != p.location.altitudeUnits {
+		feet := float64(p.location.altitude)
+		if "metres" == p.location.altitudeUnits {
+			feet *= feetPerMetre
+		}
+		altitude = &feet
+	}
+	p.rwLock.RUnlock()
+
+	fenceEvents := t.geofences.Update(icao, lat, lon, altitude, when)
+	if 0 == len(fenceEvents) {
+		return nil
+	}
+	events := make([]Event, len(fenceEvents))
+	for i := range fenceEvents {
+		events[i] = newGeofenceEvent(p, fenceEvents[i])
+	}
+	return events
+}
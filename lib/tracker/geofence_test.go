@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"plane.watch/lib/geofence"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracker_checkGeofences(t *testing.T) {
+	fences, err := geofence.Load(strings.NewReader(`{"type": "Feature", "id": "perth-ctr", "properties": {"ceiling": 1500},
+		"geometry": {"type": "Polygon", "coordinates": [[[115.8, -32.1], [116.1, -32.1], [116.1, -31.8], [115.8, -31.8], [115.8, -32.1]]]}}`), "test")
+	if nil != err {
+		t.Fatalf("Failed to load fences: %s", err)
+	}
+	engine := geofence.NewEngineWithFences(fences)
+	trk := NewTracker(WithGeofences(engine))
+	p := trk.GetPlane(0x7C1234)
+	now := time.Now()
+
+	steps := []struct {
+		lat, lon float64
+		altitude int32
+	}{
+		{lat: -31.95, lon: 115.96, altitude: 3000}, // above the zone
+		{lat: -31.96, lon: 115.96, altitude: 1000},
+		{lat: -31.97, lon: 115.96, altitude: 900},
+		{lat: -31.98, lon: 116.15, altitude: 900},
+	}
+	var got []string
+	for i, step := range steps {
+		when := now.Add(time.Duration(i) * time.Minute)
+		p.setLastSeen(when)
+		if err = p.addLatLong(step.lat, step.lon, when); nil != err {
+			t.Fatalf("Failed to add location: %s", err)
+		}
+		p.setAltitude(step.altitude, "feet")
+		for _, e := range trk.checkGeofences(newPlaneLocationEvent(p)) {
+			ge := e.(*GeofenceEvent)
+			got = append(got, ge.Kind().String()+" "+ge.Fence().Id)
+			if ge.Plane() != p || !when.Equal(ge.When()) {
+				t.Errorf("Unexpected geofence event %s", ge)
+			}
+		}
+	}
+	if "enter perth-ctr;exit perth-ctr" != strings.Join(got, ";") {
+		t.Errorf("Expected to enter and exit perth-ctr, got %v", got)
+	}
+
+	// back in, then we lose the plane
+	_ = p.addLatLong(-31.97, 115.96, now.Add(5*time.Minute))
+	if 1 != len(trk.checkGeofences(newPlaneLocationEvent(p))) {
+		t.Error("Expected to go back into perth-ctr")
+	}
+	trk.checkGeofences(newPlaneActionEvent(p, false, true))
+	if inside := engine.Inside(p.IcaoIdentifierStr()); 0 != len(inside) {
+		t.Errorf("Expected the removed plane to be forgotten, it is still in %v", inside)
+	}
+	trk.Stop()
+}
@@ -7,10 +7,13 @@ import (
 	"github.com/rs/zerolog/log"
 	"os"
 	"os/signal"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/geofence"
 	"plane.watch/lib/monitoring"
 	"plane.watch/lib/tracker/beast"
 	"plane.watch/lib/tracker/mode_s"
 	"plane.watch/lib/tracker/sbs1"
+	"plane.watch/lib/tracker/uat"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -40,6 +43,11 @@ type (
 		Raw() []byte
 	}
 
+	// errorCorrector is a Frame we can fix Mode S bit errors in
+	errorCorrector interface {
+		SetErrorCorrection(mode_s.ErrorCorrection)
+	}
+
 	// A Producer can listen for or generate Frames, it provides the output via a channel that the handler can then
 	// processes further.
 	// A Producer can send *LogEvent and  *FrameEvent events
@@ -75,12 +83,91 @@ func WithPruneTiming(pruneTick, pruneAfter time.Duration) Option {
 		t.pruneAfter = pruneAfter
 	}
 }
+
+// WithReceiverRange lets us decode single CPR positions relative to the receiver for aircraft within nm of it.
+// Anything over 180nm is ambiguous and is capped. Surface positions are only decoded this way when nm is 45 or less,
+// as surface zones are a quarter of the size and we could not tell which one the aircraft is in
+func WithReceiverRange(nm float64) Option {
+	return func(t *Tracker) {
+		if nm > maxLocalCprRange {
+			nm = maxLocalCprRange
+		}
+		t.receiverRange = nm * metresPerNauticalMile
+	}
+}
+
+// WithMaxSpeed sets how fast (in knots) we believe any aircraft can go, positions needing more are implausible
+func WithMaxSpeed(knots float64) Option {
+	return func(t *Tracker) {
+		t.maxSpeed = knots * metresPerNauticalMile / 3600
+	}
+}
+
+// WithMaxPrediction sets how far past a plane's last position we will predict where it is
+func WithMaxPrediction(maxPrediction time.Duration) Option {
+	return func(t *Tracker) {
+		t.maxPrediction = maxPrediction
+	}
+}
+
+// WithTrackSmoothing runs a Kalman filter over each plane's position, velocity and altitude reports. With
+// publishSmoothed our sinks send the smoothed values instead of the raw ones
+func WithTrackSmoothing(publishSmoothed bool) Option {
+	return func(t *Tracker) {
+		t.smoothTracks = true
+		t.publishSmoothed = publishSmoothed
+	}
+}
+
+// WithSnapshotFile saves our planes to file when we Finish(), RestoreFile() loads them back in
+func WithSnapshotFile(file string) Option {
+	return func(t *Tracker) {
+		t.snapshotPath = file
+	}
+}
+
+// WithAirports sends a MovementEvent whenever a plane takes off from or lands at one of the airports in db
+func WithAirports(db *airports.Database) Option {
+	return func(t *Tracker) {
+		t.airports = db
+	}
+}
+
+// WithGeofences sends a GeofenceEvent whenever a plane goes into or out of (or dwells in) one of engine's fences
+func WithGeofences(engine *geofence.Engine) Option {
+	return func(t *Tracker) {
+		t.geofences = engine
+	}
+}
+
+// WithPositionRejectCounter counts the implausible positions we throw away, the counter needs an "icao" label
+func WithPositionRejectCounter(rejectedPositions *prometheus.CounterVec) Option {
+	return func(t *Tracker) {
+		t.stats.rejectedPositions = rejectedPositions
+	}
+}
+
 func WithPrometheusCounters(currentPlanes prometheus.Gauge) Option {
 	return func(t *Tracker) {
 		t.stats.currentPlanes = currentPlanes
 	}
 }
 
+// WithErrorCorrection fixes up to this many bit errors in DF11 and DF17/18 frames with a bad checksum
+func WithErrorCorrection(level mode_s.ErrorCorrection) Option {
+	return func(t *Tracker) {
+		t.errorCorrection = level
+	}
+}
+
+// WithCrcCounters counts the Mode S frames we fixed bit errors in and the ones we had to throw away
+func WithCrcCounters(correctedFrames, rejectedFrames prometheus.Counter) Option {
+	return func(t *Tracker) {
+		t.stats.correctedFrames = correctedFrames
+		t.stats.rejectedFrames = rejectedFrames
+	}
+}
+
 // Finish begins the ending of the tracking by closing our decoding queue
 func (t *Tracker) Finish() {
 	if t.finishDone {
@@ -99,6 +186,14 @@ func (t *Tracker) Finish() {
 	log.Debug().Msg("Closing Decoding Queue")
 	close(t.decodingQueue)
 	t.pruneExitChan <- true
+	if "" != t.snapshotPath {
+		// let the decoders finish with what they have so our snapshot is complete
+		t.decodingQueueWaiter.Wait()
+		log.Debug().Str("file", t.snapshotPath).Msg("Saving Snapshot")
+		if err := t.snapshotFile(); nil != err {
+			t.errorMessage("Unable to save snapshot %s: %s", t.snapshotPath, err)
+		}
+	}
 	log.Debug().Msg("Stopping Events")
 	t.eventSync.Lock()
 	t.eventsOpen = false
@@ -224,8 +319,14 @@ func (t *Tracker) decodeQueue() {
 		}
 		atomic.AddUint64(&t.numFrames, 1)
 		frame := f.Frame()
+		if c, isCorrectable := frame.(errorCorrector); isCorrectable {
+			c.SetErrorCorrection(t.errorCorrection)
+		}
 		ok, err := frame.Decode()
 		if nil != err {
+			if nil != t.stats.rejectedFrames && errors.Is(err, mode_s.ErrInvalidChecksum) {
+				t.stats.rejectedFrames.Inc()
+			}
 			// the decode operation failed to produce valid output, and we tell someone about it
 			t.handleError(err)
 			continue
@@ -235,6 +336,18 @@ func (t *Tracker) decodeQueue() {
 			// example: NoOp heartbeat
 			continue
 		}
+		if nil != t.stats.correctedFrames {
+			switch ft := frame.(type) {
+			case *beast.Frame:
+				if ft.AvrFrame().Corrected() {
+					t.stats.correctedFrames.Inc()
+				}
+			case *mode_s.Frame:
+				if ft.Corrected() {
+					t.stats.correctedFrames.Inc()
+				}
+			}
+		}
 
 		for _, m := range t.middlewares {
 			frame = m.Handle(frame, f.source)
@@ -242,11 +355,18 @@ func (t *Tracker) decodeQueue() {
 				break
 			}
 		}
+		if b, isBeast := frame.(*beast.Frame); isBeast && b.IsModeAc() {
+			t.handleModeAc(b.ModeAcFrame())
+			continue
+		}
 		if nil == frame || frame.Icao() == 0 {
 			// invalid frame || unable to determine planes ICAO
 			continue
 		}
-		plane := t.GetPlane(frame.Icao())
+		plane, ok := t.planeForFrame(frame)
+		if !ok {
+			continue
+		}
 
 		switch frame.(type) {
 		case *beast.Frame:
@@ -257,9 +377,33 @@ func (t *Tracker) decodeQueue() {
 			plane.HandleModeSFrame(frame.(*mode_s.Frame), f.Source().RefLat, f.Source().RefLon)
 		case *sbs1.Frame:
 			plane.HandleSbs1Frame(frame.(*sbs1.Frame))
+		case *uat.Frame:
+			plane.HandleUatFrame(frame.(*uat.Frame))
 		default:
 			t.handleError(errors.New("unknown frame type, cannot track"))
 		}
 	}
 	t.decodingQueueWaiter.Done()
 }
+
+// planeForFrame gives us the plane this frame is from.
+// Replies with the ICAO in the Address/Parity field only get a plane if we are already tracking it, as any bit
+// error gives us a valid looking but bogus address
+func (t *Tracker) planeForFrame(frame Frame) (*Plane, bool) {
+	var avr *mode_s.Frame
+	switch ft := frame.(type) {
+	case *beast.Frame:
+		avr = ft.AvrFrame()
+	case *mode_s.Frame:
+		avr = ft
+	case *uat.Frame:
+		return t.GetPlaneByAddress(ft.Icao(), ft.AddressType()), true
+	}
+	if nil == avr {
+		return t.GetPlane(frame.Icao()), true
+	}
+	if avr.IcaoFromParity() {
+		return t.findPlane(avr.Icao())
+	}
+	return t.GetPlaneByAddress(avr.Icao(), avr.AddressType()), true
+}
@@ -0,0 +1,230 @@
+package tracker
+
+import (
+	"math"
+	"plane.watch/lib/geo"
+	"plane.watch/lib/tracker/mode_s"
+	"time"
+)
+
+// Our smoothing filter is a constant velocity Kalman filter. The east, north and vertical axes are filtered on
+// their own (value and rate), which is exact for a constant velocity model with independent measurement noise.
+// Turns are followed by allowing for a fair bit of acceleration in the process noise.
+
+const (
+	// how much we allow the plane to accelerate (process noise)
+	horizontalAcceleration = 2.0 // m/s²
+	surfaceAcceleration    = 1.0 // m/s²
+	verticalAcceleration   = 5.0 // ft/s²
+
+	// how much we trust what the plane tells us (measurement noise, 1 sigma)
+	adsbPositionError    = 25.0  // metres
+	mlatPositionError    = 100.0 // metres
+	surfacePositionError = 10.0  // metres
+	velocityError        = 2.0   // m/s
+	altitudeError        = 25.0  // feet
+	verticalRateError    = 1.0   // ft/s (64 fpm)
+
+	// if we have not heard from a plane for this long we start again
+	maxSmoothingGap = time.Minute
+	// how far we let the plane get from our local origin before we move it, keeps our flat earth flat
+	maxOriginDistance = 50000.0 // metres
+
+	metresPerKnot = metresPerNauticalMile / 3600.0
+	feetPerMetre  = 3.28084
+)
+
+type (
+	// kalmanAxis is a 2 state (value, rate) Kalman filter for one axis of movement
+	kalmanAxis struct {
+		x [2]float64
+		p [2][2]float64
+	}
+
+	// trackFilter smooths a plane's position, velocity and altitude
+	trackFilter struct {
+		// our east/north positions are metres from here
+		originLat, originLon float64
+
+		east, north kalmanAxis // metres, m/s
+		altitude    kalmanAxis // feet, ft/s
+
+		when, altitudeWhen       time.Time
+		hasPosition, hasAltitude bool
+		onGround                 bool
+	}
+
+	// TrackCovariance is how sure the smoothing filter is of a plane's smoothed track
+	TrackCovariance struct {
+		// in the order east (m), north (m), east velocity (m/s), north velocity (m/s)
+		Horizontal [4][4]float64
+		// altitude (ft) and vertical rate (ft/s)
+		Vertical [2][2]float64
+	}
+)
+
+func (k *kalmanAxis) reset(value, valueVariance, rate, rateVariance float64) {
+	k.x = [2]float64{value, rate}
+	k.p = [2][2]float64{{valueVariance, 0}, {0, rateVariance}}
+}
+
+// predict moves the filter forward dt seconds, accel is the 1 sigma acceleration we allow for
+func (k *kalmanAxis) predict(dt, accel float64) {
+	if dt <= 0 {
+		return
+	}
+	k.x[0] += dt * k.x[1]
+
+	p := k.p
+	q := accel * accel
+	k.p[0][0] = p[0][0] + dt*(p[1][0]+p[0][1]) + dt*dt*p[1][1] + q*dt*dt*dt*dt/4
+	k.p[0][1] = p[0][1] + dt*p[1][1] + q*dt*dt*dt/2
+	k.p[1][0] = p[1][0] + dt*p[1][1] + q*dt*dt*dt/2
+	k.p[1][1] = p[1][1] + q*dt*dt
+}
+
+// updateValue folds in a measurement of the value with variance r
+func (k *kalmanAxis) updateValue(z, r float64) {
+	p := k.p
+	s := p[0][0] + r
+	k0, k1 := p[0][0]/s, p[1][0]/s
+	y := z - k.x[0]
+	k.x[0] += k0 * y
+	k.x[1] += k1 * y
+	k.p[0][0] = (1 - k0) * p[0][0]
+	k.p[0][1] = (1 - k0) * p[0][1]
+	k.p[1][0] = p[1][0] - k1*p[0][0]
+	k.p[1][1] = p[1][1] - k1*p[0][1]
+}
+
+// updateRate folds in a measurement of the rate with variance r
+func (k *kalmanAxis) updateRate(z, r float64) {
+	p := k.p
+	s := p[1][1] + r
+	k0, k1 := p[0][1]/s, p[1][1]/s
+	y := z - k.x[1]
+	k.x[0] += k0 * y
+	k.x[1] += k1 * y
+	k.p[0][0] = p[0][0] - k0*p[1][0]
+	k.p[0][1] = p[0][1] - k0*p[1][1]
+	k.p[1][0] = (1 - k1) * p[1][0]
+	k.p[1][1] = (1 - k1) * p[1][1]
+}
+
+// toLocal gives us the metres east and north of our origin
+func (tf *trackFilter) toLocal(lat, lon float64) (east, north float64) {
+	east = (lon - tf.originLon) * math.Pi / 180 * geo.EarthRadius * math.Cos(tf.originLat*math.Pi/180)
+	north = (lat - tf.originLat) * math.Pi / 180 * geo.EarthRadius
+	return
+}
+
+// toLatLon turns metres east and north of our origin back into a lat/lon
+func (tf *trackFilter) toLatLon(east, north float64) (lat, lon float64) {
+	lat = tf.originLat + north/geo.EarthRadius*180/math.Pi
+	lon = tf.originLon + east/(geo.EarthRadius*math.Cos(tf.originLat*math.Pi/180))*180/math.Pi
+	lon -= math.Floor((lon+180.0)/360.0) * 360.0
+	return
+}
+
+// predictTo moves our horizontal state forward to ts, returns false if we are too far out of date to be useful
+func (tf *trackFilter) predictTo(ts time.Time) bool {
+	dt := ts.Sub(tf.when)
+	if dt > maxSmoothingGap {
+		return false
+	}
+	accel := horizontalAcceleration
+	if tf.onGround {
+		accel = surfaceAcceleration
+	}
+	tf.east.predict(dt.Seconds(), accel)
+	tf.north.predict(dt.Seconds(), accel)
+	if ts.After(tf.when) {
+		tf.when = ts
+	}
+	return true
+}
+
+// updatePosition folds in a position report
+func (tf *trackFilter) updatePosition(lat, lon float64, onGround bool, dataSource string, ts time.Time) {
+	r := adsbPositionError
+	if onGround {
+		r = surfacePositionError
+	} else if mode_s.DataSourceMlat == dataSource {
+		r = mlatPositionError
+	}
+	tf.onGround = onGround
+
+	if !tf.hasPosition || !tf.predictTo(ts) {
+		tf.originLat, tf.originLon = lat, lon
+		// we do not know how fast we are going yet, anything up to mach 1 or so
+		tf.east.reset(0, r*r, 0, defaultMaxSpeed*defaultMaxSpeed)
+		tf.north.reset(0, r*r, 0, defaultMaxSpeed*defaultMaxSpeed)
+		tf.when = ts
+		tf.hasPosition = true
+		return
+	}
+
+	east, north := tf.toLocal(lat, lon)
+	tf.east.updateValue(east, r*r)
+	tf.north.updateValue(north, r*r)
+
+	if math.Abs(tf.east.x[0]) > maxOriginDistance || math.Abs(tf.north.x[0]) > maxOriginDistance {
+		tf.originLat, tf.originLon = tf.toLatLon(tf.east.x[0], tf.north.x[0])
+		tf.east.x[0], tf.north.x[0] = 0, 0
+	}
+}
+
+// updateVelocity folds in a track (degrees) and ground speed (knots) report
+func (tf *trackFilter) updateVelocity(heading, knots float64, ts time.Time) {
+	if !tf.hasPosition || !tf.predictTo(ts) {
+		return
+	}
+	speed := knots * metresPerKnot
+	rad := heading * math.Pi / 180
+	tf.east.updateRate(speed*math.Sin(rad), velocityError*velocityError)
+	tf.north.updateRate(speed*math.Cos(rad), velocityError*velocityError)
+}
+
+// updateAltitude folds in an altitude (feet) report
+func (tf *trackFilter) updateAltitude(feet float64, ts time.Time) {
+	dt := ts.Sub(tf.altitudeWhen)
+	if !tf.hasAltitude || dt > maxSmoothingGap {
+		tf.altitude.reset(feet, altitudeError*altitudeError, 0, 100*100)
+		tf.altitudeWhen = ts
+		tf.hasAltitude = true
+		return
+	}
+	tf.altitude.predict(dt.Seconds(), verticalAcceleration)
+	if ts.After(tf.altitudeWhen) {
+		tf.altitudeWhen = ts
+	}
+	tf.altitude.updateValue(feet, altitudeError*altitudeError)
+}
+
+// updateVerticalRate folds in a vertical rate (feet/minute) report
+func (tf *trackFilter) updateVerticalRate(fpm float64, ts time.Time) {
+	dt := ts.Sub(tf.altitudeWhen)
+	if !tf.hasAltitude || dt > maxSmoothingGap {
+		return
+	}
+	tf.altitude.predict(dt.Seconds(), verticalAcceleration)
+	if ts.After(tf.altitudeWhen) {
+		tf.altitudeWhen = ts
+	}
+	tf.altitude.updateRate(fpm/60, verticalRateError*verticalRateError)
+}
+
+// covariance gives us how sure we are of our track
+func (tf *trackFilter) covariance() TrackCovariance {
+	var c TrackCovariance
+	c.Horizontal[0][0] = tf.east.p[0][0]
+	c.Horizontal[0][2] = tf.east.p[0][1]
+	c.Horizontal[2][0] = tf.east.p[1][0]
+	c.Horizontal[2][2] = tf.east.p[1][1]
+	c.Horizontal[1][1] = tf.north.p[0][0]
+	c.Horizontal[1][3] = tf.north.p[0][1]
+	c.Horizontal[3][1] = tf.north.p[1][0]
+	c.Horizontal[3][3] = tf.north.p[1][1]
+	c.Vertical = tf.altitude.p
+	return c
+}
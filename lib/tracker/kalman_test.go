@@ -0,0 +1,120 @@
+package tracker
+
+import (
+	"math"
+	"plane.watch/lib/geo"
+	"testing"
+	"time"
+)
+
+func Test_kalmanAxis(t *testing.T) {
+	k := kalmanAxis{}
+	k.reset(0, 100, 0, 100)
+	for i := 1; i <= 20; i++ {
+		k.predict(1, 0.1)
+		k.updateValue(float64(i)*10, 25)
+	}
+	if math.Abs(k.x[0]-200) > 5 {
+		t.Errorf("Expected to be near 200, got %0.2f", k.x[0])
+	}
+	if math.Abs(k.x[1]-10) > 1 {
+		t.Errorf("Expected a rate near 10, got %0.2f", k.x[1])
+	}
+	if k.p[0][0] >= 25 {
+		t.Errorf("Expected our value variance to be less than the measurement variance, got %0.2f", k.p[0][0])
+	}
+
+	k.updateRate(12, 0.0001)
+	if math.Abs(k.x[1]-12) > 0.1 {
+		t.Errorf("Expected an accurate rate measurement to win, got %0.2f", k.x[1])
+	}
+}
+
+func TestPlane_SmoothedLocation(t *testing.T) {
+	now := time.Now()
+	// heading east at 360 knots, with positions that jump 60 metres either side of the track
+	speed := 360 * metresPerKnot
+	truth := func(i int) (float64, float64) {
+		return geo.Destination(-31.9, 115.9, 90, speed*float64(i))
+	}
+
+	trk := NewTracker(WithTrackSmoothing(true))
+	p := trk.GetPlane(0x7C1234)
+	var lastCovariance TrackCovariance
+	for i := 0; i <= 30; i++ {
+		ts := now.Add(time.Duration(i) * time.Second)
+		p.setLastSeen(ts)
+		p.setHeading(90)
+		p.setVelocity(360)
+		p.setAltitude(int32(10000+25*(i%2)), "feet")
+
+		lat, lon := truth(i)
+		jitter := 60.0
+		if 0 == i%2 {
+			jitter = -60
+		}
+		lat, lon = geo.Destination(lat, lon, 0, jitter)
+		if err := p.addLatLong(lat, lon, ts); nil != err {
+			t.Fatal(err)
+		}
+
+		if 1 == i {
+			lastCovariance, _ = p.SmoothedCovariance()
+		}
+	}
+
+	loc, err := p.SmoothedLocation()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !loc.Smoothed() {
+		t.Error("Expected a smoothed location")
+	}
+	lat, lon := truth(30)
+	if d := geo.Distance(lat, lon, loc.Lat(), loc.Lon()); d > 30 {
+		t.Errorf("Smoothed location is %0.2fm from the truth, raw positions are 60m out", d)
+	}
+	if math.Abs(loc.Heading()-90) > 1 {
+		t.Errorf("Expected a heading of 90, got %0.2f", loc.Heading())
+	}
+	if math.Abs(loc.Velocity()-360) > 1 {
+		t.Errorf("Expected a velocity of 360 knots, got %0.2f", loc.Velocity())
+	}
+	if loc.Altitude() < 10000 || loc.Altitude() > 10025 {
+		t.Errorf("Expected a smoothed altitude between 10000 and 10025 feet, got %d", loc.Altitude())
+	}
+
+	covariance, err := p.SmoothedCovariance()
+	if nil != err {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if covariance.Horizontal[i][i] <= 0 || covariance.Horizontal[i][i] >= lastCovariance.Horizontal[i][i] {
+			t.Errorf("Expected variance %d to shrink as we track, was %0.4f now %0.4f", i, lastCovariance.Horizontal[i][i], covariance.Horizontal[i][i])
+		}
+	}
+	if !p.PublishSmoothed() {
+		t.Error("Expected to publish smoothed tracks")
+	}
+
+	// our raw values are left alone
+	if d := geo.Distance(p.Lat(), p.Lon(), loc.Lat(), loc.Lon()); d < 30 {
+		t.Errorf("Expected the raw position to still have its jitter, only %0.2fm from smoothed", d)
+	}
+}
+
+func TestPlane_SmoothedLocationDisabled(t *testing.T) {
+	p := NewTracker().GetPlane(0x7C1234)
+	if err := p.addLatLong(-31.9, 115.9, time.Now()); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := p.SmoothedLocation(); nil == err {
+		t.Error("Should not have a smoothed location without track smoothing")
+	}
+	if _, err := p.SmoothedCovariance(); nil == err {
+		t.Error("Should not have a smoothed covariance without track smoothing")
+	}
+	if p.PublishSmoothed() {
+		t.Error("Should not publish smoothed tracks")
+	}
+}
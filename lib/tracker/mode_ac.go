@@ -0,0 +1,179 @@
+package tracker
+
+import (
+	"plane.watch/lib/tracker/mode_s"
+	"time"
+)
+
+const (
+	// modeAcMatchAge is how recently a plane needs to have been heard for a Mode A/C reply to be matched to it
+	modeAcMatchAge = 30 * time.Second
+	// modeAcAltitudeTolerance allows for Mode C only having 100ft resolution
+	modeAcAltitudeTolerance = 100
+)
+
+// handleModeAc matches a Mode A/C reply to a plane we are tracking, see correlateModeAc.
+// If there is no match, we track it as a Mode A/C only target keyed by its code. A reply that could be an altitude is
+// dropped instead, it is most likely the Mode C of a plane we have not matched yet or of a Mode A/C only aircraft
+// changing altitude, and each 100ft would otherwise become a target of its own
+func (t *Tracker) handleModeAc(m *mode_s.ModeAc) {
+	if nil == m {
+		return
+	}
+	if p, modeA, ok := t.correlateModeAc(m); ok {
+		p.handleModeAcMatch(m, modeA)
+		return
+	}
+	if m.AltitudeValid() {
+		return
+	}
+	// drop the SPI bit so the address reads as the squawk (~007700)
+	t.GetPlaneByAddress(uint32(m.Code()&0x7777), mode_s.AddressTypeModeAc).HandleModeAcFrame(m)
+}
+
+// correlateModeAc finds the Mode S plane, heard in the last modeAcMatchAge, this Mode A/C reply most likely came from.
+// A reply with the exact squawk of a plane is its Mode A reply (modeA is true). Only planes that have recently had a
+// Mode A reply matched are considered for a Mode C (altitude) match, so that any plane at the right altitude does
+// not get the replies of an aircraft we only see in Mode A/C.
+// When more than one plane matches, the closest altitude wins, then the most recently heard, then the lowest address
+func (t *Tracker) correlateModeAc(m *mode_s.ModeAc) (match *Plane, modeA bool, ok bool) {
+	oldest := m.TimeStamp().Add(-modeAcMatchAge)
+
+	if squawk := m.SquawkIdentity(); 0 != squawk {
+		for _, p := range t.planesSquawking(squawk) {
+			if mode_s.AddressTypeModeAc == p.AddressType() || p.LastSeen().Before(oldest) {
+				continue
+			}
+			if nil == match || modeAcBetter(p, match) {
+				match = p
+			}
+		}
+		if nil != match {
+			return match, true, true
+		}
+	}
+
+	altitude, err := m.Altitude()
+	if nil != err {
+		return nil, false, false
+	}
+	var altDiff int32
+	t.modeAPlanes.Range(func(key, _ interface{}) bool {
+		p := key.(*Plane)
+		if p.modeAcMatchedAt().Before(oldest) {
+			t.modeAPlanes.Delete(p)
+			return true
+		}
+		if p.LastSeen().Before(oldest) || 0 == p.Altitude() {
+			return true
+		}
+		diff := p.Altitude() - altitude
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > modeAcAltitudeTolerance {
+			return true
+		}
+		if nil == match || diff < altDiff || (diff == altDiff && modeAcBetter(p, match)) {
+			match, altDiff = p, diff
+		}
+		return true
+	})
+	return match, false, nil != match
+}
+
+// indexSquawk moves the plane in our squawk index from its old squawk to its new one, 0 is not indexed
+func (t *Tracker) indexSquawk(p *Plane, old, squawk uint32) {
+	t.squawkLock.Lock()
+	defer t.squawkLock.Unlock()
+	if planes, ok := t.squawks[old]; ok {
+		delete(planes, p)
+		if 0 == len(planes) {
+			delete(t.squawks, old)
+		}
+	}
+	if 0 == squawk {
+		return
+	}
+	if nil == t.squawks[squawk] {
+		t.squawks[squawk] = map[*Plane]bool{}
+	}
+	t.squawks[squawk][p] = true
+}
+
+// planesSquawking gives us the planes with this squawk. It is a copy, so we do not hold our lock while looking at them
+func (t *Tracker) planesSquawking(squawk uint32) []*Plane {
+	t.squawkLock.RLock()
+	defer t.squawkLock.RUnlock()
+	planes := make([]*Plane, 0, len(t.squawks[squawk]))
+	for p := range t.squawks[squawk] {
+		planes = append(planes, p)
+	}
+	return planes
+}
+
+// modeAcBetter breaks a tie between two planes that match a Mode A/C reply equally well
+func modeAcBetter(p, than *Plane) bool {
+	if seen, thanSeen := p.LastSeen(), than.LastSeen(); !seen.Equal(thanSeen) {
+		return seen.After(thanSeen)
+	}
+	return p.IcaoIdentifier() < than.IcaoIdentifier()
+}
+
+// handleModeAcMatch applies a Mode A/C reply to the Mode S plane we matched it to. We do not move lastSeen,
+// a plane only stays around (and matchable) while we hear it in Mode S
+func (p *Plane) handleModeAcMatch(m *mode_s.ModeAc, modeA bool) {
+	var hasChanged bool
+	emergency := p.Emergency()
+	p.incMsgCount()
+	if modeA {
+		p.setModeAcMatchedAt(m.TimeStamp())
+		hasChanged = p.setSquawkIdentity(m.SquawkIdentity()) || hasChanged
+	} else if altitude, err := m.Altitude(); nil == err {
+		hasChanged = p.setAltitude(altitude, "feet") || hasChanged
+	}
+	p.tracker.debugMessage("Plane %s matched %s", p.IcaoIdentifierStr(), m)
+
+	p.checkEmergency(emergency, m.TimeStamp())
+	if hasChanged {
+		p.tracker.AddEvent(newPlaneLocationEvent(p))
+	}
+}
+
+// modeAcMatchedAt is when we last matched a Mode A reply to this plane
+func (p *Plane) modeAcMatchedAt() time.Time {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.modeAcMatched
+}
+
+func (p *Plane) setModeAcMatchedAt(t time.Time) {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	if t.After(p.modeAcMatched) {
+		p.modeAcMatched = t
+	}
+	if nil != p.tracker {
+		p.tracker.modeAPlanes.Store(p, true)
+	}
+}
+
+// HandleModeAcFrame updates a Mode A/C only target. We cannot tell Mode A from Mode C, so the code is the squawk
+func (p *Plane) HandleModeAcFrame(m *mode_s.ModeAc) {
+	if nil == m {
+		return
+	}
+	var hasChanged bool
+	emergency := p.Emergency()
+	p.setLastSeen(m.TimeStamp())
+	p.incMsgCount()
+
+	hasChanged = p.setDataSource(mode_s.DataSourceModeAc, m.TimeStamp()) || hasChanged
+	hasChanged = p.setSquawkIdentity(m.SquawkIdentity()) || hasChanged
+	p.tracker.debugMessage("Mode A/C only target %s: %s", p.IcaoIdentifierStr(), m)
+
+	p.checkEmergency(emergency, m.TimeStamp())
+	if hasChanged {
+		p.tracker.AddEvent(newPlaneLocationEvent(p))
+	}
+}
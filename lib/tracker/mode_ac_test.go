@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"plane.watch/lib/tracker/mode_s"
+	"testing"
+	"time"
+)
+
+func TestTracker_handleModeAc(t *testing.T) {
+	now := time.Now()
+	trk := NewTracker()
+	newPlane := func(icao uint32, squawk uint32, lastSeen time.Time) *Plane {
+		p := trk.GetPlane(icao)
+		p.setLastSeen(lastSeen)
+		p.setSquawkIdentity(squawk)
+		p.setAltitude(1225, "feet")
+		return p
+	}
+	modeS := newPlane(0x7C1234, 4321, now)
+	sameSquawk := newPlane(0x7C9999, 4321, now.Add(-5*time.Second))
+	newPlane(0x7C5678, 1000, now)
+	newPlane(0x7CAAAA, 2000, now.Add(-time.Minute))
+
+	tests := []struct {
+		name      string
+		code      uint16
+		numPlanes int
+	}{
+		{name: "altitude before any squawk match", code: 0x0310, numPlanes: 4}, // 1200ft
+		{name: "matching squawk", code: 0x4321, numPlanes: 4},
+		{name: "matching altitude", code: 0x0310, numPlanes: 4},
+		{name: "altitude we cannot match", code: 0x0630, numPlanes: 4}, // 5300ft
+		{name: "no match", code: 0x7700, numPlanes: 5},
+		{name: "no match again", code: 0x7700, numPlanes: 5},
+		{name: "squawk of a plane we have not heard from lately", code: 0x2000, numPlanes: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trk.handleModeAc(mode_s.NewModeAc(tt.code, now))
+			if tt.numPlanes != trk.numPlanes() {
+				t.Errorf("Expected %d planes, have %d", tt.numPlanes, trk.numPlanes())
+			}
+		})
+	}
+
+	if modeS.modeAcMatchedAt().IsZero() || !sameSquawk.modeAcMatchedAt().IsZero() {
+		t.Error("Expected the most recently heard plane squawking 4321 to get the Mode A reply")
+	}
+	if 1200 != modeS.Altitude() {
+		t.Errorf("Expected the matched Mode C altitude to be applied, got %d", modeS.Altitude())
+	}
+	for _, code := range []uint32{0x0310, 0x0630} {
+		if _, ok := trk.planeList.Load(planeKey(code, mode_s.AddressTypeModeAc)); ok {
+			t.Errorf("Did not expect a Mode A/C only target for the altitude %04X", code)
+		}
+	}
+
+	p, ok := trk.planeList.Load(planeKey(0x7700, mode_s.AddressTypeModeAc))
+	if !ok {
+		t.Fatal("Expected a Mode A/C only target for 7700")
+	}
+	modeAc := p.(*Plane)
+	if "~007700" != modeAc.IcaoIdentifierStr() || 7700 != modeAc.SquawkIdentity() || mode_s.DataSourceModeAc != modeAc.DataSource() {
+		t.Errorf("Incorrect Mode A/C target %s squawking %d from %s", modeAc.IcaoIdentifierStr(), modeAc.SquawkIdentity(), modeAc.DataSource())
+	}
+}
+
+func TestTracker_correlateModeAcClosestAltitude(t *testing.T) {
+	now := time.Now()
+	trk := NewTracker()
+	for i, altitude := range []int32{1275, 1200, 1150} {
+		p := trk.GetPlane(uint32(0x7C0001 + i))
+		p.setLastSeen(now)
+		p.setAltitude(altitude, "feet")
+		p.setModeAcMatchedAt(now)
+	}
+	// try a few times, we should get the same answer no matter the order we go through the planes in
+	for i := 0; i < 10; i++ {
+		p, modeA, ok := trk.correlateModeAc(mode_s.NewModeAc(0x0310, now)) // 1200ft
+		if !ok || modeA || 0x7C0002 != p.IcaoIdentifier() {
+			t.Fatalf("Expected 7C0002 to match on altitude, got %v %t %t", p, modeA, ok)
+		}
+	}
+}
+
+func TestTracker_correlateModeAcSquawkChange(t *testing.T) {
+	now := time.Now()
+	trk := NewTracker()
+	p := trk.GetPlane(0x7C1234)
+	p.setLastSeen(now)
+	p.setSquawkIdentity(4321)
+	p.setSquawkIdentity(1234)
+
+	if _, _, ok := trk.correlateModeAc(mode_s.NewModeAc(0x4321, now)); ok {
+		t.Error("Should not match the squawk the plane used to have")
+	}
+	if match, modeA, ok := trk.correlateModeAc(mode_s.NewModeAc(0x1234, now)); !ok || !modeA || p != match {
+		t.Errorf("Expected the plane to match its new squawk, got %v %t %t", match, modeA, ok)
+	}
+	if 0 != len(trk.planesSquawking(4321)) || 1 != len(trk.planesSquawking(1234)) {
+		t.Errorf("Expected the squawk index to follow the plane, got %v", trk.squawks)
+	}
+}
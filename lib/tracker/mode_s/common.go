@@ -28,6 +28,16 @@ func decodeAC12Field(AC12Field int32) int32 {
 	}
 }
 
+// decodeAC13Field decodes a 13 bit altitude code (one that still has the M bit in it)
+// we do not handle metric altitudes, the second return value is false for those
+func decodeAC13Field(AC13Field int32) (int32, bool) {
+	if 0 == AC13Field || AC13Field&0x40 == 0x40 {
+		return 0, false
+	}
+	// remove the M bit at bit 6 to make it an AC12 field
+	return decodeAC12Field(((AC13Field & 0x1F80) >> 1) | (AC13Field & 0x003F)), true
+}
+
 // this code liberally lifted from: http://www.ccsinfo.com/forum/viewtopic.php?p=77544
 func gillhamToAltitude(i16GillhamValue int32) int32 {
 	var i32Result int32
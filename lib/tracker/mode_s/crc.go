@@ -1,11 +1,34 @@
 package mode_s
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCorrection is how many bit errors we will attempt to fix in a frame
+type ErrorCorrection int
+
+const (
+	ErrorCorrectionOff ErrorCorrection = iota
+	ErrorCorrectionSingleBit
+	ErrorCorrectionDoubleBit
+)
 
 var (
 	modesChecksumTable [256]uint32
+
+	// crcSyndromes maps a checksum syndrome to the bit(s) in error, keyed by message length in bytes
+	crcSyndromes = map[int]map[uint32]crcErrorBits{}
+
+	ErrInvalidChecksum = errors.New("invalid checksum")
 )
 
+type crcErrorBits struct {
+	numBits int
+	bits    [2]int
+}
+
 const modesGeneratorPoly uint32 = 0xfff409
 
 func init() {
@@ -25,29 +48,92 @@ func init() {
 
 		modesChecksumTable[i] = c & 0x00ffffff
 	}
+
+	crcSyndromes[modesShortMsgBytes] = buildSyndromeTable(modesShortMsgBytes)
+	crcSyndromes[modesLongMsgBytes] = buildSyndromeTable(modesLongMsgBytes)
 }
 
-func (f *Frame) decodeModeSChecksum() uint32 {
-	var n = f.getMessageLengthBytes()
-	var i, index uint32
+// SetErrorCorrection sets how hard we try to fix this frame if it is a DF11 or DF17/18 with a bad checksum.
+// It needs to be called before the frame is decoded
+func (f *Frame) SetErrorCorrection(level ErrorCorrection) {
+	if nil == f {
+		return
+	}
+	f.errorCorrection = level
+}
+
+// ParseErrorCorrection turns off, 1-bit or 2-bit (or 0, 1, 2) into an ErrorCorrection level
+func ParseErrorCorrection(level string) (ErrorCorrection, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "", "off", "0":
+		return ErrorCorrectionOff, nil
+	case "1-bit", "1":
+		return ErrorCorrectionSingleBit, nil
+	case "2-bit", "2":
+		return ErrorCorrectionDoubleBit, nil
+	}
+	return ErrorCorrectionOff, fmt.Errorf("unknown error correction level %s, use one of off, 1-bit, 2-bit", level)
+}
 
+// modeSChecksum gives the syndrome of the message, 0 means the message is good
+func modeSChecksum(msg []byte) uint32 {
 	var checkSum uint32
-	for i = 0; i < n-3; i++ {
-		index = uint32(f.message[i]) ^ ((f.checkSum & 0xff0000) >> 16)
-		f.checkSum = (f.checkSum << 8) ^ modesChecksumTable[index]
-		f.checkSum = f.checkSum & 0xffffff
+	n := len(msg)
+	for i := 0; i < n-3; i++ {
+		index := uint32(msg[i]) ^ ((checkSum & 0xff0000) >> 16)
+		checkSum = (checkSum << 8) ^ modesChecksumTable[index]
+		checkSum = checkSum & 0xffffff
 	}
+	return checkSum ^ (uint32(msg[n-3]) << 16) ^ (uint32(msg[n-2]) << 8) ^ uint32(msg[n-1])
+}
 
-	f.checkSum = f.checkSum ^ (uint32(f.message[n-3]) << 16) ^ (uint32(f.message[n-2]) << 8) ^ uint32(f.message[n-1])
+// buildSyndromeTable works out the syndrome of every single and double bit error for a message length.
+// The Downlink Format bits are never corrected, a syndrome that more than one error could cause is left out
+func buildSyndromeTable(msgLen int) map[uint32]crcErrorBits {
+	numBits := msgLen * 8
+	single := make([]uint32, numBits)
+	msg := make([]byte, msgLen)
+	for i := 5; i < numBits; i++ {
+		msg[i/8] = 0x80 >> (i % 8)
+		single[i] = modeSChecksum(msg)
+		msg[i/8] = 0
+	}
+
+	table := make(map[uint32]crcErrorBits)
+	ambiguous := make(map[uint32]bool)
+	add := func(syndrome uint32, e crcErrorBits) {
+		if _, ok := table[syndrome]; ok || ambiguous[syndrome] {
+			delete(table, syndrome)
+			ambiguous[syndrome] = true
+			return
+		}
+		table[syndrome] = e
+	}
+	for i := 5; i < numBits; i++ {
+		add(single[i], crcErrorBits{numBits: 1, bits: [2]int{i}})
+	}
+	for i := 5; i < numBits; i++ {
+		for j := i + 1; j < numBits; j++ {
+			syndrome := single[i] ^ single[j]
+			if e, ok := table[syndrome]; ok && 1 == e.numBits {
+				// a single bit error is always more likely
+				continue
+			}
+			add(syndrome, crcErrorBits{numBits: 2, bits: [2]int{i, j}})
+		}
+	}
+	return table
+}
 
-	return checkSum
+func (f *Frame) decodeModeSChecksum() uint32 {
+	return modeSChecksum(f.message[:f.getMessageLengthBytes()])
 }
 func (f *Frame) decodeModeSChecksumAddr() uint32 {
 	var n = f.getMessageLengthBytes()
 	var i, index uint32
 
-	msg := make([]byte, len(f.message))
-	copy(msg, f.message)
+	var msg [modesLongMsgBytes]byte
+	copy(msg[:], f.message)
 	msg[n-3] = 0
 	msg[n-2] = 0
 	msg[n-1] = 0
@@ -66,8 +152,8 @@ func (f *Frame) decodeModeSChecksumAddr() uint32 {
 }
 
 func (f *Frame) checkCrc() error {
-	if "MLAT" == f.mode {
-		// not currently able to checksum beast AVR timestamp format messages
+	if "MLAT" == f.mode && "" != f.full {
+		// not currently able to checksum beast AVR timestamp format messages, binary beast frames are fine
 		return nil
 	}
 	switch f.downLinkFormat {
@@ -76,11 +162,61 @@ func (f *Frame) checkCrc() error {
 		return nil
 	case 11, 17, 18: // Field Type PI
 		f.checkSum = f.decodeModeSChecksum()
-		if 0 == f.checkSum {
+		if f.checkSumOk() || f.correctBitErrors() {
 			return nil
 		}
-		return fmt.Errorf("invalid checksum for DF %d (%s)", f.downLinkFormat, f.raw)
+		return fmt.Errorf("%w for DF %d (%s)", ErrInvalidChecksum, f.downLinkFormat, f.rawString())
 	default:
 		return fmt.Errorf("do not know how to CRC Downlink Format %d", f.downLinkFormat)
 	}
 }
+
+// checkSumOk is true when the parity matches. The DF11 PI field is the parity XOR the interrogator's II/SI code,
+// so the bottom 7 bits can be anything for an all call reply
+func (f *Frame) checkSumOk() bool {
+	if 11 == f.downLinkFormat {
+		return 0 == f.checkSum&0xFFFF80
+	}
+	return 0 == f.checkSum
+}
+
+// correctBitErrors attempts to fix the message using the syndrome table, up to the configured number of bits
+func (f *Frame) correctBitErrors() bool {
+	maxBits := int(f.errorCorrection)
+	if 11 == f.downLinkFormat && maxBits > 1 {
+		// DF11 has too little data for a 2 bit fix to be trusted
+		maxBits = 1
+	}
+	if 0 == maxBits {
+		return false
+	}
+
+	e, ok := crcSyndromes[len(f.message)][f.checkSum]
+	if !ok || e.numBits > maxBits {
+		return false
+	}
+	for i := 0; i < e.numBits; i++ {
+		f.message[e.bits[i]/8] ^= 0x80 >> (e.bits[i] % 8)
+	}
+	f.checkSum = f.decodeModeSChecksum()
+	if !f.checkSumOk() {
+		return false
+	}
+	f.correctedBits = e.numBits
+	// the hex string no longer matches the message, rawString will rebuild it
+	f.raw = ""
+	return true
+}
+
+// CorrectedBits is the number of bit errors that were fixed in this frame
+func (f *Frame) CorrectedBits() int {
+	if nil == f {
+		return 0
+	}
+	return f.correctedBits
+}
+
+// Corrected tells us if this frame had bit errors that we fixed
+func (f *Frame) Corrected() bool {
+	return f.CorrectedBits() > 0
+}
@@ -1,6 +1,7 @@
 package mode_s
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -60,3 +61,67 @@ func TestFrame_decodeModeSChecksumAddr(t *testing.T) {
 		})
 	}
 }
+
+func TestFrame_correctBitErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         ErrorCorrection
+		frame         string
+		wantErr       bool
+		correctedBits int
+		want          string
+	}{
+		{name: "good frame", level: ErrorCorrectionDoubleBit, frame: "8DA05629EA21485CBF3F8CADAEEB", want: "8DA05629EA21485CBF3F8CADAEEB"},
+		{name: "1 bit, off", level: ErrorCorrectionOff, frame: "8DA05629EA21485CBF3F8DADAEEB", wantErr: true},
+		{name: "1 bit", level: ErrorCorrectionSingleBit, frame: "8DA05629EA21485CBF3F8DADAEEB", correctedBits: 1, want: "8DA05629EA21485CBF3F8CADAEEB"},
+		{name: "1 bit in parity", level: ErrorCorrectionSingleBit, frame: "8DA05629EA21485CBF3F8CADAEEA", correctedBits: 1, want: "8DA05629EA21485CBF3F8CADAEEB"},
+		{name: "2 bit, 1 bit correction", level: ErrorCorrectionSingleBit, frame: "8DA05629EA23485CBF3F8DADAEEB", wantErr: true},
+		{name: "2 bit", level: ErrorCorrectionDoubleBit, frame: "8DA05629EA23485CBF3F8DADAEEB", correctedBits: 2, want: "8DA05629EA21485CBF3F8CADAEEB"},
+		{name: "3 bit", level: ErrorCorrectionDoubleBit, frame: "8DA05629EA23495CBF3F8DADAEEB", wantErr: true},
+		{name: "DF bits are not corrected", level: ErrorCorrectionDoubleBit, frame: "95A05629EA21485CBF3F8CADAEEB", wantErr: true},
+		{name: "DF11 1 bit", level: ErrorCorrectionDoubleBit, frame: "5D7C7DABCD3CE9", correctedBits: 1, want: "5D7C7DAACD3CE9"},
+		{name: "DF11 2 bit", level: ErrorCorrectionDoubleBit, frame: "5D7C7DABCD3CE8", wantErr: true},
+		{name: "DF11 interrogator code, off", level: ErrorCorrectionOff, frame: "5D7C7DAACD3CEC", want: "5D7C7DAACD3CEC"},
+		{name: "DF11 interrogator code", level: ErrorCorrectionDoubleBit, frame: "5D7C7DAACD3CE8", want: "5D7C7DAACD3CE8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFrame(tt.frame, time.Now())
+			f.SetErrorCorrection(tt.level)
+			_, err := f.Decode()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidChecksum) {
+					t.Errorf("Expected an invalid checksum error, got %v", err)
+				}
+				return
+			}
+			if nil != err {
+				t.Fatalf("Failed to decode: %s", err)
+			}
+			if tt.correctedBits != f.CorrectedBits() {
+				t.Errorf("Expected %d corrected bits, got %d", tt.correctedBits, f.CorrectedBits())
+			}
+			if (0 != tt.correctedBits) != f.Corrected() {
+				t.Errorf("Incorrect corrected flag")
+			}
+			if tt.want != string(f.Raw()) {
+				t.Errorf("Expected corrected frame %s, got %s", tt.want, f.Raw())
+			}
+		})
+	}
+}
+
+func Test_buildSyndromeTable(t *testing.T) {
+	// every single bit error (outside of the DF field) must be fixable
+	for _, msgLen := range []int{modesShortMsgBytes, modesLongMsgBytes} {
+		numSingle := 0
+		for _, e := range crcSyndromes[msgLen] {
+			if 1 == e.numBits {
+				numSingle++
+			}
+		}
+		if numSingle != msgLen*8-5 {
+			t.Errorf("Expected %d single bit syndromes for %d byte messages, got %d", msgLen*8-5, msgLen, numSingle)
+		}
+	}
+}
@@ -3,12 +3,7 @@ package mode_s
 // extended squitter decoding
 
 import (
-	"errors"
-	"fmt"
 	"math"
-	"reflect"
-	"strconv"
-	"strings"
 )
 
 func (f *Frame) decodeAdsbLatLon() {
@@ -192,18 +187,23 @@ func (f *Frame) decodeAdsb() {
 			var emergencyId = int((f.message[5] & 0xe0) >> 5)
 			f.alert = emergencyId != 0
 			f.emergency = emergencyStateTable[emergencyId]
+			f.emergencyState = EmergencyState(emergencyId)
+			f.validEmergencyState = true
 
 			// can get the Mode A Address too
 			//mode_a_code = (short) (msg[2]|((msg[1]&0x1F)<<8));
 
 		} else if f.messageSubType == 2 {
 			// TCAS Resolution Advisory
+			f.decodeAcasRa(f.message[4:11])
 		}
 	case 29:
 		// Target State and Status Message
 		// DO-260 - unused
 		// DO-260A = Target State and Status Information Message
-		// DO-260B =
+		// DO-260B = Target State and Status Message
+		// the subtype is only 2 bits, the third bit is the SIL supplement
+		f.messageSubType = (f.message[4] >> 1) & 3
 		if f.messageSubType == 0 {
 			// DO-260A
 		} else if f.messageSubType == 1 {
@@ -218,14 +218,16 @@ func (f *Frame) decodeAdsb() {
 			// bit 72-75 NACp (Navigation Accuracy Category_Position)
 			// bit 76    NICbaro (Navigation Integrity Category_Baro)
 			// bit 77-78 SIL (Source Integrity Level)
-			// bit 79    MCP/FPU Status
+			// bit 79    MCP/FCU Status
 			// bit 80    Autopilot Engaged
 			// bit 81    VNAV Mode Engaged
 			// bit 82    Altitude Hold Mode
 			// bit 83    Reserved for ADS-R Flag
 			// bit 84    Approach Mode
 			// bit 85    TCAS Operational
-			// bit 86-88 Reserved
+			// bit 86    LNAV Mode Engaged
+			// bit 87-88 Reserved
+			f.decodeTargetState(f.message[4:11])
 		}
 	case 30:
 	// NoOp
@@ -283,109 +285,6 @@ func (f *Frame) decodeAdsb() {
 	}
 }
 
-func (f *Frame) bitString() string {
-	var bits string
-	for _, msg := range f.message {
-		bits += fmt.Sprintf("%08b", msg)
-	}
-	return bits
-}
-
-func (f *Frame) decodeInto(bitStream string, t interface{}) error {
-	valParentPtr := reflect.ValueOf(t)
-	if reflect.Ptr != valParentPtr.Kind() || valParentPtr.IsNil() {
-		return errors.New("you need to pass in a pointer to your struct")
-	}
-	valParent := valParentPtr.Elem()
-	if !valParent.CanAddr() || !valParent.IsValid() || !valParent.CanSet() {
-		return errors.New("cannot edit struct")
-	}
-	tType := reflect.TypeOf(t).Elem()
-	if tType.Kind() != reflect.Struct {
-		return errors.New("you need to pass in a struct with all the struct tags (bits,name,desc)")
-	}
-
-	for i := 0; i < tType.NumField(); i++ {
-		field := tType.Field(i)
-
-		bits := field.Tag.Get("bits")
-
-		splitBits := strings.SplitN(bits, "-", 2)
-		if 2 != len(splitBits) {
-			println("Incorrect Struct Tag `bits`")
-		}
-		low, err := strconv.ParseUint(splitBits[0], 10, 8)
-		if nil != err {
-			return fmt.Errorf("incorrect Struct Tag `bits` low (%s) needs to be a number", splitBits[0])
-		}
-		high, err := strconv.ParseUint(splitBits[1], 10, 8)
-		if nil != err {
-			return fmt.Errorf("incorrect Struct Tag `bits` high (%s) needs to be a number", splitBits[1])
-		}
-		fieldBits := bitStream[low:high]
-		ii, err := strconv.ParseUint(fieldBits, 2, 64)
-
-		valField := valParent.Field(i)
-		if !valField.IsValid() || !valField.CanSet() {
-			err = fmt.Errorf("cannot Set Field %s", field.Name)
-		}
-		valField.SetUint(ii)
-	}
-	return nil
-}
-
-func (f *Frame) decodeAdsb2() (*extendedSquitter, error) {
-
-	// Down Link Format 17 Message Types
-	f.messageType = f.message[4] >> 3
-	f.messageSubType = f.message[4] & 7
-	bitStream := f.bitString()
-
-	var err error
-	var es extendedSquitter
-
-	err = f.decodeInto(bitStream, &es)
-
-	switch f.messageType {
-	case 1, 2, 3, 4:
-		err = f.decodeInto(bitStream, &es.df17me1)
-	case 19:
-		switch f.messageSubType {
-		case 1, 2:
-			err = f.decodeInto(bitStream, &es.df17me19t1)
-		case 3, 4:
-			err = f.decodeInto(bitStream, &es.df17me19t3)
-		}
-	case 28:
-		switch f.messageSubType {
-		case 1:
-			err = f.decodeInto(bitStream, &es.df17me28t1)
-		case 2:
-			err = f.decodeInto(bitStream, &es.df17me28t2)
-		}
-	case 29:
-		switch f.messageSubType {
-		case 0:
-			// DO-260A
-			err = f.decodeInto(bitStream, &es.df17me29t0)
-		case 1:
-			// DO-260B
-			err = f.decodeInto(bitStream, &es.df17me29t1)
-		default:
-			err = f.decodeInto(bitStream, &es.df17me29t2)
-		}
-	case 31:
-		switch f.messageSubType {
-		case 0:
-			err = f.decodeInto(bitStream, &es.df17me31t0)
-		case 1:
-			err = f.decodeInto(bitStream, &es.df17me31t1)
-		}
-	default:
-	}
-	return &es, err
-}
-
 //
 //=========================================================================
 //
@@ -432,3 +331,39 @@ func calcSurfaceSpeed(value uint64) (float64, bool) {
 	}
 	return gSpeed, validVelocity
 }
+
+// decodeTargetState decodes a DO-260B Target State and Status message (Type 29, Subtype 1)
+func (f *Frame) decodeTargetState(me []byte) {
+	if alt := mbBits(me, 10, 20); 0 != alt {
+		if 0 == mbBits(me, 9, 9) {
+			f.selectedAltitudeMcp = int32(alt-1) * 32
+			f.validSelectedAltitudeMcp = true
+		} else {
+			f.selectedAltitudeFms = int32(alt-1) * 32
+			f.validSelectedAltitudeFms = true
+		}
+	}
+
+	if baro := mbBits(me, 21, 29); 0 != baro {
+		f.baroSetting = 800 + float64(baro-1)*0.8
+		f.validBaroSetting = true
+	}
+
+	if 1 == mbBits(me, 30, 30) {
+		f.selectedHeading = float64(mbBits(me, 31, 39)) * 180 / 256
+		f.validSelectedHeading = true
+	}
+
+	// the mode bits are only good if the MCP/FCU status bit says so
+	if 1 == mbBits(me, 47, 47) {
+		f.validAutopilotModes = true
+		f.autopilotEngaged = 1 == mbBits(me, 48, 48)
+		f.vnavEngaged = 1 == mbBits(me, 49, 49)
+		f.altitudeHoldEngaged = 1 == mbBits(me, 50, 50)
+		f.approachEngaged = 1 == mbBits(me, 52, 52)
+		f.lnavEngaged = 1 == mbBits(me, 54, 54)
+	}
+
+	f.tcasOperational = 1 == mbBits(me, 53, 53)
+	f.validTcasOperational = true
+}
@@ -276,11 +276,14 @@ func TestDecodeDF17MT28(t *testing.T) {
 		frame     string
 		emergency bool
 		cap       int
+		icao      string
+		state     EmergencyState
 	}{
-		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8C7C4A0CE104BC0000000069DE1A", cap: 4},
-		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8D7C4A0CE101950000000095FC54", cap: 5},
-		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8D7C4A0CE104BC0000000031AF62", cap: 5},
-		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8F7C4A0CE104BC00000000814D92", cap: 7},
+		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8C7C4A0CE104BC0000000069DE1A", cap: 4, icao: "7C4A0C"},
+		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8D7C4A0CE101950000000095FC54", cap: 5, icao: "7C4A0C"},
+		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8D7C4A0CE104BC0000000031AF62", cap: 5, icao: "7C4A0C"},
+		{name: fmt.Sprintf("DF17/MT28/ST01 %s", DF17FrameEmergencyPriority), frame: "8F7C4A0CE104BC00000000814D92", cap: 7, icao: "7C4A0C"},
+		{name: "DF17/MT28/ST01 General Emergency", frame: "8D7C1234E12000000000003E742D", cap: 5, icao: "7C1234", state: EmergencyGeneral},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -299,8 +302,11 @@ func TestDecodeDF17MT28(t *testing.T) {
 				t.Error("Should have been Message Type 28")
 			}
 
-			if "7C4A0C" != frame.IcaoStr() {
-				t.Errorf("Invalid ICAO. 7C4A0C != %s", frame.IcaoStr())
+			if tt.icao != frame.IcaoStr() {
+				t.Errorf("Invalid ICAO. %s != %s", tt.icao, frame.IcaoStr())
+			}
+			if state, err := frame.EmergencyState(); nil != err || tt.state != state {
+				t.Errorf("Incorrect emergency state. %s != %s (err: %v)", tt.state, state, err)
 			}
 
 			// todo: determine more tests
@@ -310,12 +316,19 @@ func TestDecodeDF17MT28(t *testing.T) {
 
 func TestDecodeDF17MT29(t *testing.T) {
 	tests := []struct {
-		name     string
-		frame    string
-		onGround bool
+		name       string
+		frame      string
+		icao       string
+		mcpAlt     int32 // 0 for not valid
+		baro       float64
+		heading    float64
+		modesValid bool
+		modes      [5]bool // autopilot, vnav, lnav, alt hold, approach
+		tcas       bool
 	}{
-		{name: fmt.Sprintf("DF17/MT29/ST02 %s", DF17FrameTargetStateStatus), frame: "8D7C4A0CEA0000000000005D4CDC", onGround: false},
-		{name: fmt.Sprintf("DF17/MT29/ST02 %s", DF17FrameTargetStateStatus), frame: "8D7C4A0CEA00085FBD3F04D4F47E", onGround: true},
+		{name: fmt.Sprintf("DF17/MT29/ST1 %s empty", DF17FrameTargetStateStatus), frame: "8D7C4A0CEA0000000000005D4CDC", icao: "7C4A0C"},
+		{name: fmt.Sprintf("DF17/MT29/ST1 %s", DF17FrameTargetStateStatus), frame: "8D7C4A0CEA00085FBD3F04D4F47E", icao: "7C4A0C", baro: 1012.8, heading: 336.09375, modesValid: true, modes: [5]bool{true, false, true, false, false}},
+		{name: fmt.Sprintf("DF17/MT29/ST1 %s with altitude", DF17FrameTargetStateStatus), frame: "8DA05629EA21485CBF3F8CADAEEB", icao: "A05629", mcpAlt: 16992, baro: 1012.8, heading: 66.796875, modesValid: true, modes: [5]bool{true, true, true, false, false}, tcas: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -330,11 +343,41 @@ func TestDecodeDF17MT29(t *testing.T) {
 			if 29 != frame.MessageType() {
 				t.Error("Should have been Message Type 29")
 			}
-			if "7C4A0C" != frame.IcaoStr() {
-				t.Errorf("Invalid ICAO. 7C4A0C != %s", frame.IcaoStr())
+			if 1 != frame.MessageSubType() {
+				t.Errorf("Should have been Message Sub Type 1, got %d", frame.MessageSubType())
+			}
+			if DF17FrameTargetStateStatus != frame.MessageTypeString() {
+				t.Errorf("Incorrect message type string: %s", frame.MessageTypeString())
+			}
+			if tt.icao != frame.IcaoStr() {
+				t.Errorf("Invalid ICAO. %s != %s", tt.icao, frame.IcaoStr())
+			}
+			if alt, err := frame.SelectedAltitudeMcp(); (0 != tt.mcpAlt) != (nil == err) || alt != tt.mcpAlt {
+				t.Errorf("Expected MCP selected altitude %d, got %d (%v)", tt.mcpAlt, alt, err)
+			}
+			if frame.SelectedAltitudeFmsValid() {
+				t.Error("Did not expect an FMS selected altitude")
+			}
+			if baro, err := frame.BaroSetting(); (0 != tt.baro) != (nil == err) || fmt.Sprintf("%0.1f", baro) != fmt.Sprintf("%0.1f", tt.baro) {
+				t.Errorf("Expected baro setting %0.1f, got %0.1f (%v)", tt.baro, baro, err)
+			}
+			if hdg, err := frame.SelectedHeading(); (0 != tt.heading) != (nil == err) || hdg != tt.heading {
+				t.Errorf("Expected selected heading %f, got %f (%v)", tt.heading, hdg, err)
+			}
+			if tt.modesValid != frame.AutopilotModesValid() {
+				t.Fatalf("Expected autopilot modes valid %t", tt.modesValid)
+			}
+			ap, _ := frame.AutopilotEngaged()
+			vnav, _ := frame.VnavEngaged()
+			lnav, _ := frame.LnavEngaged()
+			altHold, _ := frame.AltitudeHoldEngaged()
+			approach, _ := frame.ApproachEngaged()
+			if modes := [5]bool{ap, vnav, lnav, altHold, approach}; modes != tt.modes {
+				t.Errorf("Expected modes %v, got %v", tt.modes, modes)
+			}
+			if tcas, err := frame.TcasOperational(); nil != err || tcas != tt.tcas {
+				t.Errorf("Expected TCAS operational %t, got %t (%v)", tt.tcas, tcas, err)
 			}
-
-			// todo: determine more tests
 		})
 	}
 }
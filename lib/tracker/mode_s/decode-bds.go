@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
+	"math"
 	"strings"
 )
 
@@ -32,6 +33,16 @@ type bds struct {
 	major, minor byte
 }
 
+// bdsMessageTypes saves us building the "major.minor" string for every Comm-B frame
+var bdsMessageTypes = func() (types [16][16]string) {
+	for major := range types {
+		for minor := range types[major] {
+			types[major][minor] = fmt.Sprintf("%d.%d", major, minor)
+		}
+	}
+	return types
+}()
+
 var (
 	UnknownCommBMessage  = errors.New("unable to infer Comm-B message type")
 	CommBIncorrectLength = errors.New("Comm-B must be exactly 7 bytes")
@@ -68,6 +79,9 @@ func (b *bds) DescribeBds() string {
 }
 
 func (b *bds) BdsMessageType() string {
+	if b.major < 16 && b.minor < 16 {
+		return bdsMessageTypes[b.major][b.minor]
+	}
 	return fmt.Sprintf("%d.%d", b.major, b.minor)
 }
 
@@ -89,14 +103,25 @@ func (f *Frame) decodeCommB() error {
 		// decode GICB
 	case BdsElsAircraftIdent: // 2.0
 		f.decodeFlightNumber()
-
+	case BdsElsAcasRA: // 3.0
+		f.decodeAcasRa(f.message[4:11])
+	case BdsEhsSelVertIntent: // 4.0
+		f.decodeBds40(f.message[4:11])
+	case BdsEhsTrackTurnReport: // 5.0
+		f.decodeBds50(f.message[4:11])
+	case BdsEhsHeadingSpeed: // 6.0
+		f.decodeBds60(f.message[4:11])
+	case BdsMetRoutineAirReport: // 4.4
+		f.decodeBds44(f.message[4:11])
+	case BdsMetHazartReport: // 4.5
+		f.decodeBds45(f.message[4:11])
 	}
 
 	// things get a lot murkier from here on in!
 	// we should attempt to decode each BDS frame, in turn.
 	// if we cannot decode it as a given frame (lots of error checking) fall through to the next type
 
-	// BDS 4,0 - BDS status bits = 1, 14, 26, 37, 48
+	// BDS 4,0 - BDS status bits = 1, 14, 27, 48, 54. bits 40-47 and 52-53 are 0's
 	// BDS 4,3 - BDS status bits = 1, 13, 26. bits 43-56 are 0's
 	// BDS 4,4 - BDS status bits = 5, 24, 35, 47, 50
 	// BDS 4,5 - BDS status bits = 1, 4, 7, 10, 13, 16, 27, 39. 52-56 are 0's
@@ -137,8 +162,8 @@ func inferCommBMessageType(mb []byte) (byte, byte, error) {
 	// Detection: BDS Code && Callsign
 	if mb[0] == 0b0010_0000 {
 		// bits 9-56 are call sign, should not contain any ? chars from aisCharset
-		callsign := string(decodeFlightNumber(mb[1:7]))
-		if !strings.Contains("?", callsign) {
+		callsign := decodeFlightNumber(mb[1:7])
+		if !strings.Contains("?", string(callsign[:])) {
 			return 2, 0, nil
 		}
 	}
@@ -152,11 +177,318 @@ func inferCommBMessageType(mb []byte) (byte, byte, error) {
 		return 3, 0, nil
 	}
 
-	// Now onto EHS Detection
-	// TODO: implement EHS
-
-	// and lastly onto Meteorological Detection
-	// TODO: Implement MRAR and MHR
+	// Now onto EHS and Meteorological Detection
+	// These registers do not carry their BDS code, so we check the status bits and value ranges of each.
+	// 5,0 and 6,0 in particular can look alike, if more than one register fits we cannot say which it is.
+	var matches []bds
+	if isBds40(mb) {
+		matches = append(matches, bds{major: 4, minor: 0})
+	}
+	if isBds50(mb) {
+		matches = append(matches, bds{major: 5, minor: 0})
+	}
+	if isBds60(mb) {
+		matches = append(matches, bds{major: 6, minor: 0})
+	}
+	if isBds44(mb) {
+		matches = append(matches, bds{major: 4, minor: 4})
+	}
+	if isBds45(mb) {
+		matches = append(matches, bds{major: 4, minor: 5})
+	}
+	if 1 == len(matches) {
+		return matches[0].major, matches[0].minor, nil
+	}
 
 	return 0, 0, UnknownCommBMessage
 }
+
+// mbBits returns the value of bits start to end (inclusive) of the MB field.
+// bits are numbered from 1, the same as the ICAO docs do
+func mbBits(mb []byte, start, end int) uint64 {
+	var field uint64
+	for _, b := range mb {
+		field = (field << 8) | uint64(b)
+	}
+	return (field >> (56 - end)) & ((1 << (end - start + 1)) - 1)
+}
+
+// mbSignedBits returns the two's complement value of bits start to end, with sign being the sign bit
+func mbSignedBits(mb []byte, sign, start, end int) int {
+	value := int(mbBits(mb, start, end))
+	if 1 == mbBits(mb, sign, sign) {
+		value -= 1 << (end - start + 1)
+	}
+	return value
+}
+
+// mbStatusOk makes sure that if the status bit is not set, the bits it covers (status+1 to end) are all 0's
+func mbStatusOk(mb []byte, status, end int) bool {
+	return 1 == mbBits(mb, status, status) || 0 == mbBits(mb, status+1, end)
+}
+
+// mbAllZeros is true when there is no data at all in the MB field
+func mbAllZeros(mb []byte) bool {
+	return 0 == mbBits(mb, 1, 56)
+}
+
+// isBds40 checks if the MB field could be a BDS 4,0 Selected vertical intention report
+func isBds40(mb []byte) bool {
+	if mbAllZeros(mb) {
+		return false
+	}
+	if !mbStatusOk(mb, 1, 13) || !mbStatusOk(mb, 14, 26) || !mbStatusOk(mb, 27, 39) ||
+		!mbStatusOk(mb, 48, 51) || !mbStatusOk(mb, 54, 56) {
+		return false
+	}
+	// reserved bits
+	if 0 != mbBits(mb, 40, 47) || 0 != mbBits(mb, 52, 53) {
+		return false
+	}
+	return true
+}
+
+// isBds50 checks if the MB field could be a BDS 5,0 Track and turn report
+func isBds50(mb []byte) bool {
+	if mbAllZeros(mb) {
+		return false
+	}
+	if !mbStatusOk(mb, 1, 11) || !mbStatusOk(mb, 12, 23) || !mbStatusOk(mb, 24, 34) ||
+		!mbStatusOk(mb, 35, 45) || !mbStatusOk(mb, 46, 56) {
+		return false
+	}
+
+	var f Frame
+	f.decodeBds50(mb)
+	if f.validRollAngle && math.Abs(f.rollAngle) > 50 {
+		return false
+	}
+	if f.validGroundSpeed && f.groundSpeed > 600 {
+		return false
+	}
+	if f.validTrueAirSpeed && f.trueAirSpeed > 500 {
+		return false
+	}
+	if f.validGroundSpeed && f.validTrueAirSpeed && math.Abs(float64(f.trueAirSpeed-f.groundSpeed)) > 200 {
+		return false
+	}
+	return true
+}
+
+// isBds60 checks if the MB field could be a BDS 6,0 Heading and speed report
+func isBds60(mb []byte) bool {
+	if mbAllZeros(mb) {
+		return false
+	}
+	if !mbStatusOk(mb, 1, 12) || !mbStatusOk(mb, 13, 23) || !mbStatusOk(mb, 24, 34) ||
+		!mbStatusOk(mb, 35, 45) || !mbStatusOk(mb, 46, 56) {
+		return false
+	}
+
+	var f Frame
+	f.decodeBds60(mb)
+	if f.validIndicatedAirSpeed && f.indicatedAirSpeed > 500 {
+		return false
+	}
+	if f.validMach && f.mach > 1 {
+		return false
+	}
+	if f.validBaroVerticalRate && (f.baroVerticalRate > 6000 || f.baroVerticalRate < -6000) {
+		return false
+	}
+	if f.validInertialVerticalRate && (f.inertialVerticalRate > 6000 || f.inertialVerticalRate < -6000) {
+		return false
+	}
+	return true
+}
+
+// decodeBds40 decodes the Selected vertical intention MB field
+func (f *Frame) decodeBds40(mb []byte) {
+	if f.validSelectedAltitudeMcp = 1 == mbBits(mb, 1, 1); f.validSelectedAltitudeMcp {
+		f.selectedAltitudeMcp = int32(mbBits(mb, 2, 13) * 16)
+	}
+	if f.validSelectedAltitudeFms = 1 == mbBits(mb, 14, 14); f.validSelectedAltitudeFms {
+		f.selectedAltitudeFms = int32(mbBits(mb, 15, 26) * 16)
+	}
+	if f.validBaroSetting = 1 == mbBits(mb, 27, 27); f.validBaroSetting {
+		f.baroSetting = float64(mbBits(mb, 28, 39))*0.1 + 800
+	}
+}
+
+// decodeBds50 decodes the Track and turn report MB field
+func (f *Frame) decodeBds50(mb []byte) {
+	if f.validRollAngle = 1 == mbBits(mb, 1, 1); f.validRollAngle {
+		f.rollAngle = float64(mbSignedBits(mb, 2, 3, 11)) * 45 / 256
+	}
+	if f.validTrueTrack = 1 == mbBits(mb, 12, 12); f.validTrueTrack {
+		f.trueTrack = float64(mbSignedBits(mb, 13, 14, 23)) * 90 / 512
+		if f.trueTrack < 0 {
+			f.trueTrack += 360
+		}
+	}
+	if f.validGroundSpeed = 1 == mbBits(mb, 24, 24); f.validGroundSpeed {
+		f.groundSpeed = int(mbBits(mb, 25, 34) * 2)
+	}
+	if f.validTrackRate = 1 == mbBits(mb, 35, 35); f.validTrackRate {
+		f.trackRate = float64(mbSignedBits(mb, 36, 37, 45)) * 8 / 256
+	}
+	if f.validTrueAirSpeed = 1 == mbBits(mb, 46, 46); f.validTrueAirSpeed {
+		f.trueAirSpeed = int(mbBits(mb, 47, 56) * 2)
+	}
+}
+
+// decodeBds60 decodes the Heading and speed report MB field
+func (f *Frame) decodeBds60(mb []byte) {
+	if f.validMagneticHeading = 1 == mbBits(mb, 1, 1); f.validMagneticHeading {
+		f.magneticHeading = float64(mbSignedBits(mb, 2, 3, 12)) * 90 / 512
+		if f.magneticHeading < 0 {
+			f.magneticHeading += 360
+		}
+	}
+	if f.validIndicatedAirSpeed = 1 == mbBits(mb, 13, 13); f.validIndicatedAirSpeed {
+		f.indicatedAirSpeed = int(mbBits(mb, 14, 23))
+	}
+	if f.validMach = 1 == mbBits(mb, 24, 24); f.validMach {
+		f.mach = float64(mbBits(mb, 25, 34)) * 2.048 / 512
+	}
+	if f.validBaroVerticalRate = 1 == mbBits(mb, 35, 35); f.validBaroVerticalRate {
+		f.baroVerticalRate = mbSignedBits(mb, 36, 37, 45) * 32
+	}
+	if f.validInertialVerticalRate = 1 == mbBits(mb, 46, 46); f.validInertialVerticalRate {
+		f.inertialVerticalRate = mbSignedBits(mb, 47, 48, 56) * 32
+	}
+}
+
+// isBds44 checks if the MB field could be a BDS 4,4 Meteorological routine air report
+func isBds44(mb []byte) bool {
+	if mbAllZeros(mb) {
+		return false
+	}
+	if !mbStatusOk(mb, 5, 23) || !mbStatusOk(mb, 35, 46) || !mbStatusOk(mb, 47, 49) || !mbStatusOk(mb, 50, 56) {
+		return false
+	}
+	// figure of merit/source, values above 4 are reserved
+	if mbBits(mb, 1, 4) > 4 {
+		return false
+	}
+
+	var f Frame
+	f.decodeBds44(mb)
+	if f.validWind && f.windSpeed > 250 {
+		return false
+	}
+	if f.staticAirTemperature > 60 || f.staticAirTemperature < -80 {
+		return false
+	}
+	return true
+}
+
+// isBds45 checks if the MB field could be a BDS 4,5 Meteorological hazard report
+func isBds45(mb []byte) bool {
+	if mbAllZeros(mb) {
+		return false
+	}
+	for _, status := range []int{1, 4, 7, 10, 13} {
+		if !mbStatusOk(mb, status, status+2) {
+			return false
+		}
+	}
+	if !mbStatusOk(mb, 16, 26) || !mbStatusOk(mb, 27, 38) || !mbStatusOk(mb, 39, 51) {
+		return false
+	}
+	// reserved bits
+	if 0 != mbBits(mb, 52, 56) {
+		return false
+	}
+
+	var f Frame
+	f.decodeBds45(mb)
+	if f.validStaticAirTemperature && (f.staticAirTemperature > 60 || f.staticAirTemperature < -80) {
+		return false
+	}
+	return true
+}
+
+// decodeBds44 decodes the Meteorological routine air report MB field
+func (f *Frame) decodeBds44(mb []byte) {
+	if f.validWind = 1 == mbBits(mb, 5, 5); f.validWind {
+		f.windSpeed = int(mbBits(mb, 6, 14))
+		f.windDirection = float64(mbBits(mb, 15, 23)) * 180 / 256
+	}
+	// there is no status bit for the temperature
+	f.validStaticAirTemperature = true
+	f.staticAirTemperature = float64(mbSignedBits(mb, 24, 25, 34)) * 0.25
+	if f.validAverageStaticPressure = 1 == mbBits(mb, 35, 35); f.validAverageStaticPressure {
+		f.averageStaticPressure = int(mbBits(mb, 36, 46))
+	}
+	if f.validTurbulence = 1 == mbBits(mb, 47, 47); f.validTurbulence {
+		f.turbulence = byte(mbBits(mb, 48, 49))
+	}
+	if f.validHumidity = 1 == mbBits(mb, 50, 50); f.validHumidity {
+		f.humidity = float64(mbBits(mb, 51, 56)) * 100 / 64
+	}
+}
+
+// decodeBds45 decodes the Meteorological hazard report MB field
+func (f *Frame) decodeBds45(mb []byte) {
+	if f.validTurbulence = 1 == mbBits(mb, 1, 1); f.validTurbulence {
+		f.turbulence = byte(mbBits(mb, 2, 3))
+	}
+	if f.validWindShear = 1 == mbBits(mb, 4, 4); f.validWindShear {
+		f.windShear = byte(mbBits(mb, 5, 6))
+	}
+	if f.validMicroburst = 1 == mbBits(mb, 7, 7); f.validMicroburst {
+		f.microburst = byte(mbBits(mb, 8, 9))
+	}
+	if f.validIcing = 1 == mbBits(mb, 10, 10); f.validIcing {
+		f.icing = byte(mbBits(mb, 11, 12))
+	}
+	if f.validWakeVortex = 1 == mbBits(mb, 13, 13); f.validWakeVortex {
+		f.wakeVortex = byte(mbBits(mb, 14, 15))
+	}
+	if f.validStaticAirTemperature = 1 == mbBits(mb, 16, 16); f.validStaticAirTemperature {
+		f.staticAirTemperature = float64(mbSignedBits(mb, 17, 18, 26)) * 0.25
+	}
+	if f.validAverageStaticPressure = 1 == mbBits(mb, 27, 27); f.validAverageStaticPressure {
+		f.averageStaticPressure = int(mbBits(mb, 28, 38))
+	}
+	if f.validRadioHeight = 1 == mbBits(mb, 39, 39); f.validRadioHeight {
+		f.radioHeight = int(mbBits(mb, 40, 51) * 16)
+	}
+}
+
+// decodeAcasRa decodes an ACAS Resolution Advisory report. The same layout is used for the BDS 3,0 MB field,
+// the DF16 MV field and the DF17 Type 28 Subtype 2 ME field, only the first 8 bits (the identifier) differ
+func (f *Frame) decodeAcasRa(field []byte) {
+	f.validAcasRa = true
+	f.activeRa = uint16(mbBits(field, 9, 22))
+	f.raComplement = byte(mbBits(field, 23, 26))
+	f.raTerminated = 1 == mbBits(field, 27, 27)
+	f.multipleThreat = 1 == mbBits(field, 28, 28)
+	f.threatType = byte(mbBits(field, 29, 30))
+
+	switch f.threatType {
+	case 1:
+		// threat identity is the ICAO address of the other aircraft
+		f.threatIcao = uint32(mbBits(field, 31, 54))
+	case 2:
+		// threat identity is the altitude, range and bearing of the other aircraft
+		f.threatAltitude, f.validThreatAltitude = decodeAC13Field(int32(mbBits(field, 31, 43)))
+
+		switch tidr := mbBits(field, 44, 50); {
+		case 0 == tidr:
+			// no range estimate
+		case 127 == tidr:
+			f.threatRange = 12.6
+			f.validThreatRange = true
+		default:
+			f.threatRange = float64(tidr-1) / 10
+			f.validThreatRange = true
+		}
+
+		if tidb := mbBits(field, 51, 56); tidb >= 1 && tidb <= 60 {
+			f.threatBearing = int(tidb-1) * 6
+			f.validThreatBearing = true
+		}
+	}
+}
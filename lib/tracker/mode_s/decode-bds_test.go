@@ -1,6 +1,10 @@
 package mode_s
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+	"time"
+)
 
 func Test_inferCommBMessageType(t *testing.T) {
 	type args struct {
@@ -56,6 +60,55 @@ func Test_inferCommBMessageType(t *testing.T) {
 			want1:   0,
 			wantErr: false,
 		},
+		{
+			name:    "Infer BDS 4.0",
+			args:    args{mb: []byte{0x85, 0xE4, 0x2F, 0x31, 0x30, 0x00, 0x00}},
+			want:    4,
+			want1:   0,
+			wantErr: false,
+		},
+		{
+			name:    "BDS 4.0 Reserved Bits Set",
+			args:    args{mb: []byte{0x85, 0xE4, 0x2F, 0x31, 0x30, 0xFF, 0x00}},
+			want:    0,
+			want1:   0,
+			wantErr: true,
+		},
+		{
+			name:    "Infer BDS 5.0",
+			args:    args{mb: []byte{0x81, 0x95, 0x15, 0x36, 0xE0, 0x24, 0xD4}},
+			want:    5,
+			want1:   0,
+			wantErr: false,
+		},
+		{
+			name:    "Infer BDS 6.0",
+			args:    args{mb: []byte{0x8F, 0x39, 0xF9, 0x1A, 0x7E, 0x27, 0xC4}},
+			want:    6,
+			want1:   0,
+			wantErr: false,
+		},
+		{
+			name:    "Infer BDS 4.4",
+			args:    args{mb: []byte{0x18, 0x5B, 0xD5, 0xCF, 0x40, 0x00, 0x00}},
+			want:    4,
+			want1:   4,
+			wantErr: false,
+		},
+		{
+			name:    "Infer BDS 4.5",
+			args:    args{mb: []byte{0xC0, 0x51, 0xD8, 0x00, 0x00, 0x00, 0x00}},
+			want:    4,
+			want1:   5,
+			wantErr: false,
+		},
+		{
+			name:    "All Zeros",
+			args:    args{mb: []byte{0, 0, 0, 0, 0, 0, 0}},
+			want:    0,
+			want1:   0,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -73,3 +126,165 @@ func Test_inferCommBMessageType(t *testing.T) {
 		})
 	}
 }
+
+func TestFrame_decodeBds40(t *testing.T) {
+	frame, err := DecodeString("A000029C85E42F313000007047D3", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	if BdsEhsSelVertIntent != frame.BdsMessageType() {
+		t.Fatalf("Expected BDS %s, got %s", BdsEhsSelVertIntent, frame.BdsMessageType())
+	}
+	if alt, err := frame.SelectedAltitudeMcp(); nil != err || 3008 != alt {
+		t.Errorf("Expected MCP selected altitude 3008, got %d (%v)", alt, err)
+	}
+	if alt, err := frame.SelectedAltitudeFms(); nil != err || 3008 != alt {
+		t.Errorf("Expected FMS selected altitude 3008, got %d (%v)", alt, err)
+	}
+	if baro, err := frame.BaroSetting(); nil != err || 1020 != baro {
+		t.Errorf("Expected baro setting 1020, got %0.1f (%v)", baro, err)
+	}
+}
+
+func TestFrame_decodeBds50(t *testing.T) {
+	frame, err := DecodeString("A000139381951536E024D4CCF6B5", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	if BdsEhsTrackTurnReport != frame.BdsMessageType() {
+		t.Fatalf("Expected BDS %s, got %s", BdsEhsTrackTurnReport, frame.BdsMessageType())
+	}
+	if roll, err := frame.RollAngle(); nil != err || 2.109375 != roll {
+		t.Errorf("Expected roll angle 2.109375, got %f (%v)", roll, err)
+	}
+	if track, err := frame.TrueTrack(); nil != err || 114.2578125 != track {
+		t.Errorf("Expected true track 114.2578125, got %f (%v)", track, err)
+	}
+	if gs, err := frame.GroundSpeed(); nil != err || 438 != gs {
+		t.Errorf("Expected ground speed 438, got %d (%v)", gs, err)
+	}
+	if rate, err := frame.TrackRate(); nil != err || 0.125 != rate {
+		t.Errorf("Expected track rate 0.125, got %f (%v)", rate, err)
+	}
+	if tas, err := frame.TrueAirSpeed(); nil != err || 424 != tas {
+		t.Errorf("Expected true air speed 424, got %d (%v)", tas, err)
+	}
+}
+
+func TestFrame_decodeBds60(t *testing.T) {
+	frame, err := DecodeString("A00004128F39F91A7E27C46ADC21", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	if BdsEhsHeadingSpeed != frame.BdsMessageType() {
+		t.Fatalf("Expected BDS %s, got %s", BdsEhsHeadingSpeed, frame.BdsMessageType())
+	}
+	if hdg, err := frame.MagneticHeading(); nil != err || 42.71484375 != hdg {
+		t.Errorf("Expected magnetic heading 42.71484375, got %f (%v)", hdg, err)
+	}
+	if ias, err := frame.IndicatedAirSpeed(); nil != err || 252 != ias {
+		t.Errorf("Expected indicated air speed 252, got %d (%v)", ias, err)
+	}
+	if mach, err := frame.Mach(); nil != err || 0.42 != mach {
+		t.Errorf("Expected mach 0.42, got %f (%v)", mach, err)
+	}
+	if vr, err := frame.BaroVerticalRate(); nil != err || -1920 != vr {
+		t.Errorf("Expected baro vertical rate -1920, got %d (%v)", vr, err)
+	}
+	if vr, err := frame.InertialVerticalRate(); nil != err || -1920 != vr {
+		t.Errorf("Expected inertial vertical rate -1920, got %d (%v)", vr, err)
+	}
+}
+
+func TestFrame_decodeBds44(t *testing.T) {
+	frame, err := DecodeString("A0001692185BD5CF400000DFC696", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	if BdsMetRoutineAirReport != frame.BdsMessageType() {
+		t.Fatalf("Expected BDS %s, got %s", BdsMetRoutineAirReport, frame.BdsMessageType())
+	}
+	if speed, direction, err := frame.Wind(); nil != err || 22 != speed || 344.53125 != direction {
+		t.Errorf("Expected wind 22kts from 344.53125, got %dkts from %f (%v)", speed, direction, err)
+	}
+	if temp, err := frame.StaticAirTemperature(); nil != err || -48.75 != temp {
+		t.Errorf("Expected static air temperature -48.75, got %f (%v)", temp, err)
+	}
+	if frame.AverageStaticPressureValid() || frame.HumidityValid() || frame.TurbulenceValid() {
+		t.Error("Did not expect pressure, humidity or turbulence")
+	}
+}
+
+func TestFrame_decodeBds45(t *testing.T) {
+	frame, err := DecodeString("A0001692C051D800000000DFC696", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	if BdsMetHazartReport != frame.BdsMessageType() {
+		t.Fatalf("Expected BDS %s, got %s", BdsMetHazartReport, frame.BdsMessageType())
+	}
+	if level, err := frame.Turbulence(); nil != err || "Moderate" != HazardLevelString(level) {
+		t.Errorf("Expected Moderate turbulence, got %s (%v)", HazardLevelString(level), err)
+	}
+	if level, err := frame.Icing(); nil != err || "Light" != HazardLevelString(level) {
+		t.Errorf("Expected Light icing, got %s (%v)", HazardLevelString(level), err)
+	}
+	if frame.WindShearValid() || frame.MicroburstValid() || frame.WakeVortexValid() {
+		t.Error("Did not expect wind shear, microburst or wake vortex")
+	}
+	if temp, err := frame.StaticAirTemperature(); nil != err || -40 != temp {
+		t.Errorf("Expected static air temperature -40, got %f (%v)", temp, err)
+	}
+}
+
+func TestFrame_decodeAcasRa(t *testing.T) {
+	tests := []struct {
+		name          string
+		frame         string
+		activeRa      []string
+		complements   []string
+		terminated    bool
+		threatIcao    uint32
+		threatAlt     int32
+		threatRange   float64
+		threatBearing int
+	}{
+		{name: "BDS 3.0 ICAO", frame: "A00003B830C00205F048D092EDCB", activeRa: []string{"Corrective", "Upward Sense", "Vertical Speed Limit"}, complements: []string{"Do Not Pass Below"}, threatIcao: 0x7C1234},
+		{name: "DF16 ICAO", frame: "800003B830C00205F048D0635AB5", activeRa: []string{"Corrective", "Upward Sense", "Vertical Speed Limit"}, complements: []string{"Do Not Pass Below"}, threatIcao: 0x7C1234},
+		{name: "DF17 ICAO", frame: "8D7C6B2DE2C00205F048D08D2247", activeRa: []string{"Corrective", "Upward Sense", "Vertical Speed Limit"}, complements: []string{"Do Not Pass Below"}, threatIcao: 0x7C1234},
+		{name: "BDS 3.0 Alt/Range/Bearing", frame: "A00003B830A00128760690CD5A73", activeRa: []string{"Preventive", "Downward Sense", "Vertical Speed Limit"}, complements: []string{"Do Not Pass Above"}, terminated: true, threatAlt: 5000, threatRange: 2.5, threatBearing: 90},
+		{name: "DF17 Alt/Range/Bearing", frame: "8D7C6B2DE2A00128760690D295FF", activeRa: []string{"Preventive", "Downward Sense", "Vertical Speed Limit"}, complements: []string{"Do Not Pass Above"}, terminated: true, threatAlt: 5000, threatRange: 2.5, threatBearing: 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := DecodeString(tt.frame, time.Now())
+			if nil != err {
+				t.Fatalf("Failed to decode frame: %s", err)
+			}
+			if !frame.AcasRaValid() {
+				t.Fatalf("Expected a valid ACAS RA")
+			}
+			if fmt.Sprint(tt.activeRa) != fmt.Sprint(frame.AcasActiveRa()) {
+				t.Errorf("Expected active RA %v, got %v", tt.activeRa, frame.AcasActiveRa())
+			}
+			if fmt.Sprint(tt.complements) != fmt.Sprint(frame.AcasRaComplements()) {
+				t.Errorf("Expected RA complements %v, got %v", tt.complements, frame.AcasRaComplements())
+			}
+			if tt.terminated != frame.AcasRaTerminated() {
+				t.Errorf("Expected terminated %t, got %t", tt.terminated, frame.AcasRaTerminated())
+			}
+			if icao, err := frame.AcasThreatIcao(); (0 != tt.threatIcao) != (nil == err) || icao != tt.threatIcao {
+				t.Errorf("Expected threat ICAO %06X, got %06X (%v)", tt.threatIcao, icao, err)
+			}
+			if alt, err := frame.AcasThreatAltitude(); (0 != tt.threatAlt) != (nil == err) || alt != tt.threatAlt {
+				t.Errorf("Expected threat altitude %d, got %d (%v)", tt.threatAlt, alt, err)
+			}
+			if rng, err := frame.AcasThreatRange(); (0 != tt.threatRange) != (nil == err) || rng != tt.threatRange {
+				t.Errorf("Expected threat range %0.1f, got %0.1f (%v)", tt.threatRange, rng, err)
+			}
+			if bearing, err := frame.AcasThreatBearing(); (0 != tt.threatBearing) != (nil == err) || bearing != tt.threatBearing {
+				t.Errorf("Expected threat bearing %d, got %d (%v)", tt.threatBearing, bearing, err)
+			}
+		})
+	}
+}
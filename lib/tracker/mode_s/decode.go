@@ -54,16 +54,60 @@ func NewFrame(rawFrame string, t time.Time) *Frame {
 	return &f
 }
 
+// DecodeBytes decodes a binary (7 or 14 byte) Mode S message, skipping the hex string handling DecodeString needs
+func DecodeBytes(message []byte, t time.Time) (*Frame, error) {
+	f := &Frame{}
+	if err := f.DecodeBytes(message, t); nil != err {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewFrameFromBytes gives us an undecoded frame for a binary Mode S message, nil if it is the wrong length
+func NewFrameFromBytes(message []byte, t time.Time) *Frame {
+	f := Frame{
+		timeStamp: t,
+	}
+	if err := f.setMessage(message); nil != err {
+		return nil
+	}
+	return &f
+}
+
+// DecodeBytes resets this frame and decodes the binary message into it, keeping its error correction level.
+// Reusing a frame like this does not allocate
+func (f *Frame) DecodeBytes(message []byte, t time.Time) error {
+	*f = Frame{
+		timeStamp:       t,
+		errorCorrection: f.errorCorrection,
+	}
+	if err := f.setMessage(message); nil != err {
+		return err
+	}
+	return f.parse()
+}
+
 func (f *Frame) Decode() (bool, error) {
 	if nil == f {
 		return false, nil
 	}
-	if err := f.parseIntoRaw(); nil != err {
-		return false, err
+	if nil == f.message {
+		if err := f.parseIntoRaw(); nil != err {
+			return false, err
+		}
 	}
 	return !f.isNoOp(), f.parse()
 }
 
+// setMessage copies the message into our own buffer, so the caller is free to reuse theirs
+func (f *Frame) setMessage(message []byte) error {
+	if !(len(message) == modesShortMsgBytes || len(message) == modesLongMsgBytes) {
+		return fmt.Errorf("frame is incorrect length. %d != 7 or 14", len(message))
+	}
+	f.message = f.buf[:copy(f.buf[:], message)]
+	return nil
+}
+
 func (f *Frame) parseIntoRaw() error {
 	encodedFrame := strings.TrimFunc(f.full, func(r rune) bool {
 		return unicode.IsSpace(r) || ';' == r
@@ -157,16 +201,16 @@ func (f *Frame) parse() error {
 		err = f.decode13bitAltitudeCode()
 		f.decodeReplyInformation()
 		f.decodeSensitivityLevel()
+		if 0x30 == f.message[4] {
+			// the MV field is an ACAS Resolution Advisory report
+			f.decodeAcasRa(f.message[4:11])
+		}
 	case 17: //DF_17
 		f.decodeICAO()
 		f.decodeCapability()
 		f.decodeAdsb()
 	case 18: //DF_18
-		f.decodeCapability() // control field
-		if 0 == f.ca {
-			f.decodeICAO()
-			f.decodeAdsb()
-		}
+		f.decodeControlField()
 	case 20: //DF_20
 		f.decodeICAO()
 		f.decodeFlightStatus()
@@ -202,7 +246,7 @@ func (f *Frame) parseBeastTimeStamp() error {
 	if "" == f.beastTimeStamp || "00000000000" == f.beastTimeStamp {
 		return nil
 	}
-	// MLAT timestamps from Beast AVR are dependent on when the device started ( 500ns intervals / 12mhz)
+	// MLAT timestamps from Beast AVR are dependent on when the device started (12mhz ticks)
 	// calculated from power on.
 	// 48 bits = 2.81474976711e+14
 	// max: 2,000,000 seconds
@@ -213,10 +257,22 @@ func (f *Frame) parseBeastTimeStamp() error {
 	if err != nil {
 		return fmt.Errorf("failed to decode beast avr timestamp: %s", err)
 	}
-	f.beastTicksNs = f.beastTicks * 500
+	f.beastTicksNs = f.beastTicks * 1000 / 12
 	return nil
 }
 
+// SetBeastTicks sets the beast mlat timestamp for a frame that did not come from an AVR string
+func (f *Frame) SetBeastTicks(ticks uint64) {
+	f.mode = "MLAT"
+	f.beastTicks = ticks
+	f.beastTicksNs = ticks * 1000 / 12
+}
+
+// BeastTicks is the raw 48 bit 12MHz mlat timestamp, 0 if the frame did not have one
+func (f *Frame) BeastTicks() uint64 {
+	return f.beastTicks
+}
+
 // BeastTicksNs returns a time.Duration timestamp for this frame
 func (f *Frame) BeastTicksNs() time.Duration {
 	return time.Duration(f.beastTicksNs)
@@ -248,23 +304,31 @@ func (f *Frame) parseRawToMessage() error {
 		return fmt.Errorf("frame is incorrect length. %d != 7 or 14", messageLen)
 	}
 
-	f.message = make([]byte, messageLen)
 	// the rest of the frame is encoded in 2 char hex values
-
-	index := 0
-	for i := 0; i < len(f.raw); i += 2 {
-		pair := f.raw[i : i+2]
-		myInt, err := strconv.ParseUint(pair, 16, 8)
-
-		if err != nil {
-			return err
+	for i := 0; i < messageLen; i++ {
+		hi, okHi := fromHexChar(f.raw[i*2])
+		lo, okLo := fromHexChar(f.raw[i*2+1])
+		if !okHi || !okLo {
+			return fmt.Errorf("frame (%s) is not valid hex", f.raw)
 		}
-		f.message[index] = byte(myInt)
-		index++
+		f.buf[i] = hi<<4 | lo
 	}
+	f.message = f.buf[:messageLen]
 	return nil
 }
 
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
 func (f *Frame) decodeCapability() {
 	f.ca = f.message[0] & 7
 
@@ -278,6 +342,57 @@ func (f *Frame) decodeCapability() {
 	default:
 	}
 }
+
+// decodeControlField decodes the DF18 CF field. It tells us who is sending the message and what sort of address it has
+func (f *Frame) decodeControlField() {
+	f.ca = f.message[0] & 7
+
+	switch f.ca {
+	case 0: // ADS-B ES/NT device with an ICAO address
+		f.decodeICAO()
+		f.decodeAdsb()
+	case 1: // ADS-B ES/NT device with a non ICAO address
+		f.addressType = AddressTypeAdsbOther
+		f.decodeICAO()
+		f.decodeAdsb()
+	case 2, 6: // Fine TIS-B / ADS-R rebroadcast, the IMF bit tells us if this is an ICAO address
+		f.decodeICAO()
+		f.decodeAdsb()
+		if f.imfBit() {
+			if 2 == f.ca {
+				f.addressType = AddressTypeTisbOther
+			} else {
+				f.addressType = AddressTypeAdsrOther
+			}
+		}
+	case 3: // Coarse TIS-B airborne position and velocity, ME bit 1 is the IMF
+		// the rest of the coarse format is not yet decoded
+		f.decodeICAO()
+		if 0 != f.message[4]&0x80 {
+			f.addressType = AddressTypeTisbOther
+		}
+	case 5: // Fine TIS-B with a non ICAO address
+		f.addressType = AddressTypeTisbOther
+		f.decodeICAO()
+		f.decodeAdsb()
+	default:
+		// 4 is TIS-B/ADS-R management messages, 7 is reserved
+	}
+}
+
+// imfBit is the ICAO/Mode A Flag for TIS-B and ADS-R messages. It is set when the address is not an ICAO address
+func (f *Frame) imfBit() bool {
+	switch f.messageType {
+	case 5, 6, 7, 8: // surface position, ME bit 21
+		return 0 != f.message[6]&0x08
+	case 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 20, 21, 22: // airborne position, ME bit 8
+		return 0 != f.message[4]&0x01
+	case 19: // velocity, ME bit 9
+		return 0 != f.message[5]&0x80
+	}
+	return false
+}
+
 func (f *Frame) decodeCrossLinkCapability() {
 	f.cc = f.message[0] & 0x2 >> 1
 }
@@ -439,7 +554,7 @@ func (f *Frame) decode13bitAltitudeCode() error {
 func (f *Frame) getMessageLengthBits() uint32 {
 	//if f.downLinkFormat & 0x10 != 0 {
 	if f.downLinkFormat&0x10 != 0 {
-		if len(f.raw) == 14 {
+		if len(f.message) == modesShortMsgBytes {
 			return modesShortMsgBits
 		}
 		return modesLongMsgBits
@@ -458,13 +573,14 @@ func (f *Frame) getMessageLengthBytes() uint32 {
 
 func (f *Frame) decodeFlightNumber() {
 	f.flight = decodeFlightNumber(f.message[5:11])
+	f.validFlight = true
 }
 
-func decodeFlightNumber(b []byte) []byte {
+func decodeFlightNumber(b []byte) [8]byte {
 	if 6 != len(b) {
 		panic(fmt.Sprintf("attempting to decode a flight number/callsign with too many bytes (%d)", len(b)))
 	}
-	callsign := make([]byte, 8)
+	var callsign [8]byte
 	callsign[0] = aisCharset[b[0]>>2]
 	callsign[1] = aisCharset[((b[0]&3)<<4)|(b[1]>>4)]
 	callsign[2] = aisCharset[((b[1]&15)<<2)|(b[2]>>6)]
@@ -2,6 +2,8 @@ package mode_s
 
 import (
 	//"fmt"
+	"bytes"
+	"encoding/hex"
 	"testing"
 	"time"
 )
@@ -173,6 +175,98 @@ func BenchmarkDecodeDF17Msg11(b *testing.B) {
 	}
 }
 
+var benchmarkFrames = []struct {
+	name, frame string
+}{
+	{name: "DF4", frame: "200018386DFEF1"},
+	{name: "DF11", frame: "5D7C7DAACD3CE9"},
+	{name: "DF17 Position", frame: "8D40621D58C382D690C8AC2863A7"},
+	{name: "DF17 Velocity", frame: "8D485020994409940838175B284F"},
+	{name: "DF17 Identification", frame: "8D4840D6202CC371C32CE0576098"},
+	{name: "DF20", frame: "A0001838CA3E51F0A8000047A9B2"},
+	{name: "DF21", frame: "A800161110010080E6000030D4F6"},
+}
+
+func BenchmarkDecodeString(b *testing.B) {
+	for _, bf := range benchmarkFrames {
+		b.Run(bf.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = DecodeString(bf.frame, time.Now())
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	for _, bf := range benchmarkFrames {
+		msg, _ := hex.DecodeString(bf.frame)
+		b.Run(bf.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = DecodeBytes(msg, time.Now())
+			}
+		})
+	}
+}
+
+func BenchmarkFrame_DecodeBytes(b *testing.B) {
+	for _, bf := range benchmarkFrames {
+		msg, _ := hex.DecodeString(bf.frame)
+		b.Run(bf.name, func(b *testing.B) {
+			var f Frame
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = f.DecodeBytes(msg, time.Now())
+			}
+		})
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	now := time.Now()
+	for _, bf := range benchmarkFrames {
+		t.Run(bf.name, func(t *testing.T) {
+			msg, _ := hex.DecodeString(bf.frame)
+			want, err := DecodeString(bf.frame, now)
+			if nil != err {
+				t.Fatalf("DecodeString() error = %v", err)
+			}
+			got, err := DecodeBytes(msg, now)
+			if nil != err {
+				t.Fatalf("DecodeBytes() error = %v", err)
+			}
+			// the frame must not hold on to the callers buffer
+			msg[0] = 0
+
+			if string(want.Raw()) != string(got.Raw()) {
+				t.Errorf("Raw() = %s, want %s", got.Raw(), want.Raw())
+			}
+			var wantDesc, gotDesc bytes.Buffer
+			want.Describe(&wantDesc)
+			got.Describe(&gotDesc)
+			if wantDesc.String() != gotDesc.String() {
+				t.Errorf("Describe() = %s\nwant %s", gotDesc.String(), wantDesc.String())
+			}
+
+			var reused Frame
+			if err = reused.DecodeBytes(want.message, now); nil != err {
+				t.Fatalf("Frame.DecodeBytes() error = %v", err)
+			}
+			if want.IcaoStr() != reused.IcaoStr() || want.FlightNumber() != reused.FlightNumber() {
+				t.Errorf("Frame.DecodeBytes() = %s %s, want %s %s", reused.IcaoStr(), reused.FlightNumber(), want.IcaoStr(), want.FlightNumber())
+			}
+		})
+	}
+
+	if _, err := DecodeBytes([]byte{0x8D, 0x48}, now); nil == err {
+		t.Error("DecodeBytes() expected an error for a short message")
+	}
+	if nil != NewFrameFromBytes([]byte{0x8D, 0x48}, now) {
+		t.Error("NewFrameFromBytes() expected nil for a short message")
+	}
+}
+
 type tIcaoMessage struct {
 	msg, expectedIcao, df string
 }
@@ -487,3 +581,52 @@ func TestFrame_decodeDownLinkFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestFrame_decodeControlField(t *testing.T) {
+	tests := []struct {
+		name        string
+		frame       string
+		cf          byte
+		addressType AddressType
+		dataSource  string
+		icao        uint32
+	}{
+		{name: "CF0 ADS-B ICAO", frame: "907C123458C382D690C8AC6859C9", cf: 0, addressType: AddressTypeIcao, dataSource: DataSourceAdsb, icao: 0x7C1234},
+		{name: "CF1 ADS-B Other", frame: "917C123458C382D690C8AC3028B1", cf: 1, addressType: AddressTypeAdsbOther, dataSource: DataSourceAdsb, icao: 0x7C1234},
+		{name: "CF2 Fine TIS-B ICAO", frame: "927C123458C382D690C8ACD8BB39", cf: 2, addressType: AddressTypeIcao, dataSource: DataSourceTisb, icao: 0x7C1234},
+		{name: "CF2 Fine TIS-B Other", frame: "927C123459C382D690C8AC04C1CE", cf: 2, addressType: AddressTypeTisbOther, dataSource: DataSourceTisb, icao: 0x7C1234},
+		{name: "CF3 Coarse TIS-B Other", frame: "937C123480C382D690C8AC840B6A", cf: 3, addressType: AddressTypeTisbOther, dataSource: DataSourceTisb, icao: 0x7C1234},
+		{name: "CF4 Management", frame: "947C123458C382D690C8ACF66820", cf: 4, addressType: AddressTypeIcao, dataSource: DataSourceTisb, icao: 0},
+		{name: "CF5 Fine TIS-B Other", frame: "957C123458C382D690C8ACAE1958", cf: 5, addressType: AddressTypeTisbOther, dataSource: DataSourceTisb, icao: 0x7C1234},
+		{name: "CF6 ADS-R Other", frame: "967C123459C382D690C8AC9AF027", cf: 6, addressType: AddressTypeAdsrOther, dataSource: DataSourceAdsr, icao: 0x7C1234},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := DecodeString(tt.frame, time.Now())
+			if nil != err {
+				t.Fatalf("failed to decode %s: %s", tt.frame, err)
+			}
+			if 18 != frame.DownLinkType() {
+				t.Errorf("Should have been DF18, got DF%d", frame.DownLinkType())
+			}
+			if tt.cf != frame.ca {
+				t.Errorf("Incorrect control field, expected %d, got %d", tt.cf, frame.ca)
+			}
+			if tt.addressType != frame.AddressType() {
+				t.Errorf("Incorrect address type, expected %s, got %s", tt.addressType, frame.AddressType())
+			}
+			if tt.dataSource != frame.DataSource() {
+				t.Errorf("Incorrect data source, expected %s, got %s", tt.dataSource, frame.DataSource())
+			}
+			if tt.icao != frame.Icao() {
+				t.Errorf("Incorrect address, expected %06X, got %06X", tt.icao, frame.Icao())
+			}
+		})
+	}
+
+	frame, _ := DecodeString("927C123459C382D690C8AC04C1CE", time.Now())
+	frame.SetMlat(true)
+	if DataSourceMlat != frame.DataSource() {
+		t.Errorf("MLAT frames should have a data source of %s, got %s", DataSourceMlat, frame.DataSource())
+	}
+}
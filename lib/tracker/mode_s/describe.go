@@ -28,6 +28,7 @@ var featureDescription = map[string]featureDescriptionType{
 	"AB":   {field: "Air Speed Bit", meaning: "0=indicated air speed, 1=true air speed"},
 	"AS":   {field: "True/Indicated Air Speed", meaning: "0=indicated air speed, 1=true air speed"},
 	"CA":   {field: "Capability", meaning: "aircraft report of system capability"},
+	"CF":   {field: "Control Field", meaning: "the type of DF18 message and what its address is"},
 	"CC":   {field: "Crosslink Capability", meaning: "Indicates XPDR has ability to support crosslink capability"},
 	"DF":   {field: "Downlink Format", meaning: "downlink descriptor"},
 	"DI":   {field: "Designator Identification", meaning: "describes content of SD field"},
@@ -175,7 +176,7 @@ var featureDF17AirVelocityAirSpeed = []featureBreakdown{
 }
 
 var featureDF17AirVelocity = []featureBreakdown{
-	{name: "??", start: 37, end: 88, subFields: map[string][]featureBreakdown{
+	{name: "??", start: 32, end: 88, subFields: map[string][]featureBreakdown{
 		"0": featureDF17AirVelocityUnknown,
 		"1": featureDF17AirVelocityGroundSpeed,
 		"2": featureDF17AirVelocityGroundSpeed,
@@ -353,6 +354,78 @@ var bdsFeatures = map[string][]featureBreakdown{
 		{name: "TID", start: 62, end: 86, longName: "Threat identity data"},
 		{name: "??", start: 86, end: 88, longName: "Reserved"},
 	},
+	"4.0": {
+		{name: "S", start: 32, end: 33, longName: "Status"},
+		{name: "MCP ALT", start: 33, end: 45, longName: "MCP/FCU selected altitude"},
+		{name: "S", start: 45, end: 46, longName: "Status"},
+		{name: "FMS ALT", start: 46, end: 58, longName: "FMS selected altitude"},
+		{name: "S", start: 58, end: 59, longName: "Status"},
+		{name: "BARO", start: 59, end: 71, longName: "Barometric pressure setting minus 800 mb"},
+		{name: "??", start: 71, end: 79, longName: "Reserved"},
+		{name: "S", start: 79, end: 80, longName: "Status of MCP/FCU mode bits"},
+		{name: "VNAV", start: 80, end: 81, longName: "VNAV mode"},
+		{name: "ALT", start: 81, end: 82, longName: "Alt hold mode"},
+		{name: "APP", start: 82, end: 83, longName: "Approach mode"},
+		{name: "??", start: 83, end: 85, longName: "Reserved"},
+		{name: "S", start: 85, end: 86, longName: "Status of target altitude source bits"},
+		{name: "SRC", start: 86, end: 88, longName: "Target altitude source"},
+	},
+	"4.4": {
+		{name: "FOM", start: 32, end: 36, longName: "Figure of merit / source"},
+		{name: "S", start: 36, end: 37, longName: "Status"},
+		{name: "WS", start: 37, end: 46, longName: "Wind speed"},
+		{name: "WD", start: 46, end: 55, longName: "Wind direction"},
+		{name: "SAT", start: 55, end: 66, longName: "Static air temperature (sign + 10 bits)"},
+		{name: "S", start: 66, end: 67, longName: "Status"},
+		{name: "ASP", start: 67, end: 78, longName: "Average static pressure"},
+		{name: "S", start: 78, end: 79, longName: "Status"},
+		{name: "TURB", start: 79, end: 81, longName: "Turbulence"},
+		{name: "S", start: 81, end: 82, longName: "Status"},
+		{name: "HUM", start: 82, end: 88, longName: "Humidity"},
+	},
+	"4.5": {
+		{name: "S", start: 32, end: 33, longName: "Status"},
+		{name: "TURB", start: 33, end: 35, longName: "Turbulence"},
+		{name: "S", start: 35, end: 36, longName: "Status"},
+		{name: "WS", start: 36, end: 38, longName: "Wind shear"},
+		{name: "S", start: 38, end: 39, longName: "Status"},
+		{name: "MB", start: 39, end: 41, longName: "Microburst"},
+		{name: "S", start: 41, end: 42, longName: "Status"},
+		{name: "ICE", start: 42, end: 44, longName: "Icing"},
+		{name: "S", start: 44, end: 45, longName: "Status"},
+		{name: "WV", start: 45, end: 47, longName: "Wake vortex"},
+		{name: "S", start: 47, end: 48, longName: "Status"},
+		{name: "SAT", start: 48, end: 58, longName: "Static air temperature (sign + 9 bits)"},
+		{name: "S", start: 58, end: 59, longName: "Status"},
+		{name: "ASP", start: 59, end: 70, longName: "Average static pressure"},
+		{name: "S", start: 70, end: 71, longName: "Status"},
+		{name: "RH", start: 71, end: 83, longName: "Radio height"},
+		{name: "??", start: 83, end: 88, longName: "Reserved"},
+	},
+	"5.0": {
+		{name: "S", start: 32, end: 33, longName: "Status"},
+		{name: "ROLL", start: 33, end: 43, longName: "Roll angle (sign + 9 bits)"},
+		{name: "S", start: 43, end: 44, longName: "Status"},
+		{name: "TRK", start: 44, end: 55, longName: "True track angle (sign + 10 bits)"},
+		{name: "S", start: 55, end: 56, longName: "Status"},
+		{name: "GS", start: 56, end: 66, longName: "Ground speed"},
+		{name: "S", start: 66, end: 67, longName: "Status"},
+		{name: "TRK RATE", start: 67, end: 77, longName: "Track angle rate (sign + 9 bits)"},
+		{name: "S", start: 77, end: 78, longName: "Status"},
+		{name: "TAS", start: 78, end: 88, longName: "True airspeed"},
+	},
+	"6.0": {
+		{name: "S", start: 32, end: 33, longName: "Status"},
+		{name: "HDG", start: 33, end: 44, longName: "Magnetic heading (sign + 10 bits)"},
+		{name: "S", start: 44, end: 45, longName: "Status"},
+		{name: "IAS", start: 45, end: 55, longName: "Indicated airspeed"},
+		{name: "S", start: 55, end: 56, longName: "Status"},
+		{name: "MACH", start: 56, end: 66, longName: "Mach number"},
+		{name: "S", start: 66, end: 67, longName: "Status"},
+		{name: "BARO VR", start: 67, end: 77, longName: "Barometric altitude rate (sign + 9 bits)"},
+		{name: "S", start: 77, end: 78, longName: "Status"},
+		{name: "INS VR", start: 78, end: 88, longName: "Inertial vertical velocity (sign + 9 bits)"},
+	},
 }
 
 var frameFeatures = map[byte][]featureBreakdown{
@@ -413,8 +486,10 @@ var frameFeatures = map[byte][]featureBreakdown{
 	},
 	18: {
 		{name: "DF", start: 0, end: 5},
-		{name: "??", start: 5, end: 88},
-		{name: "CRC", start: 88, end: 112},
+		{name: "CF", start: 5, end: 8},
+		{name: "AA", start: 8, end: 32},
+		{name: "ME", start: 32, end: 88, subFields: asdbFeatures},
+		{name: "PI", start: 88, end: 112},
 	},
 	19: {
 		{name: "DF", start: 0, end: 5},
@@ -455,7 +530,7 @@ func (f *Frame) String() string {
 func (f *Frame) Describe(output io.Writer) {
 	fprintf(output, "MODE S Packet:\n")
 	fprintf(output, "Length              : %d bits\n", f.getMessageLengthBits())
-	fprintf(output, "Frame               : %s\n", f.raw)
+	fprintf(output, "Frame               : %s\n", f.rawString())
 	fprintf(output, "DF: Downlink Format : (%d) %s\n", f.downLinkFormat, f.DownLinkFormat())
 	if f.mode == "MLAT" {
 		fprintf(output, "MLAT: Beast Ticks  : %d (@12mhz clock)\n", f.beastTicks)
@@ -496,13 +571,15 @@ func (f *Frame) Describe(output io.Writer) {
 		f.showICAO(output)
 		f.showAdsb(output)
 	case 18: //DF_18
-		//f.showCapability() // control field
-		if 0 == f.ca {
-			f.showCapability(output)
+		f.showControlField(output)
+		switch f.ca {
+		case 0, 1, 2, 5, 6:
 			f.showICAO(output)
 			f.showAdsb(output)
-		} else {
-			fprintln(output, "Unable to decode DF18 Capability:", f.ca)
+		case 3:
+			f.showICAO(output)
+		default:
+			fprintln(output, "Unable to decode DF18 Control Field:", f.ca)
 		}
 	case 20: //DF_20
 		f.showFlightStatus(output)
@@ -518,6 +595,7 @@ func (f *Frame) Describe(output io.Writer) {
 		f.showICAO(output)
 	}
 
+	f.showAcasRa(output)
 	f.showBitString(output)
 
 }
@@ -626,6 +704,12 @@ func (f *Frame) showCapability(output io.Writer) {
 	f.showVerticalStatus(output)
 }
 
+func (f *Frame) showControlField(output io.Writer) {
+	fprintf(output, "CF: Control Field   : (%d) %s\n", f.ca, controlFieldTable[f.ca])
+	fprintf(output, "    Address Type    : %s\n", f.addressType)
+	fprintf(output, "    Data Source     : %s\n", f.DataSource())
+}
+
 func (f *Frame) showIdentity(output io.Writer) {
 	fprintf(output, "ID: squawk Identity : %04d\n", f.identity)
 }
@@ -756,6 +840,10 @@ func (f *Frame) showAdsb(output io.Writer) {
 			// TCAS RA
 		}
 	case 29:
+		f.showAdsbMsgSubType(output)
+		if 1 == f.messageSubType {
+			f.showTargetState(output)
+		}
 	case 31:
 		f.showAdsbMsgSubType(output)
 		f.showCapabilityClassInfo(output)
@@ -773,6 +861,31 @@ func (f *Frame) showAdsb(output io.Writer) {
 	fprintln(output, "")
 }
 
+func (f *Frame) showTargetState(output io.Writer) {
+	if f.validSelectedAltitudeMcp {
+		fprintf(output, "MCP Sel Alt         : %d ft\n", f.selectedAltitudeMcp)
+	}
+	if f.validSelectedAltitudeFms {
+		fprintf(output, "FMS Sel Alt         : %d ft\n", f.selectedAltitudeFms)
+	}
+	if f.validBaroSetting {
+		fprintf(output, "Baro Setting        : %0.1f mb\n", f.baroSetting)
+	}
+	if f.validSelectedHeading {
+		fprintf(output, "Selected Heading    : %0.2f°\n", f.selectedHeading)
+	}
+	if f.validAutopilotModes {
+		fprintf(output, "Autopilot           : %t\n", f.autopilotEngaged)
+		fprintf(output, "VNAV                : %t\n", f.vnavEngaged)
+		fprintf(output, "LNAV                : %t\n", f.lnavEngaged)
+		fprintf(output, "Altitude Hold       : %t\n", f.altitudeHoldEngaged)
+		fprintf(output, "Approach            : %t\n", f.approachEngaged)
+	}
+	if f.validTcasOperational {
+		fprintf(output, "TCAS Operational    : %t\n", f.tcasOperational)
+	}
+}
+
 func (f *Frame) showAdsbMsgSubType(output io.Writer) {
 	fprintf(output, "SUB:      Sub Type  : %d \n", f.messageSubType)
 }
@@ -842,6 +955,106 @@ func (f *Frame) showAdsbVersion(output io.Writer) {
 func (f *Frame) showBdsData(output io.Writer) {
 	fprintln(output, "BDS Info")
 	fprintf(output, "  BDS Msg       : %s\n", f.DescribeBds())
+	switch f.BdsMessageType() {
+	case BdsEhsSelVertIntent:
+		if f.validSelectedAltitudeMcp {
+			fprintf(output, "  MCP Sel Alt   : %d ft\n", f.selectedAltitudeMcp)
+		}
+		if f.validSelectedAltitudeFms {
+			fprintf(output, "  FMS Sel Alt   : %d ft\n", f.selectedAltitudeFms)
+		}
+		if f.validBaroSetting {
+			fprintf(output, "  Baro Setting  : %0.1f mb\n", f.baroSetting)
+		}
+	case BdsEhsTrackTurnReport:
+		if f.validRollAngle {
+			fprintf(output, "  Roll Angle    : %0.2f°\n", f.rollAngle)
+		}
+		if f.validTrueTrack {
+			fprintf(output, "  True Track    : %0.2f°\n", f.trueTrack)
+		}
+		if f.validGroundSpeed {
+			fprintf(output, "  Ground Speed  : %d knots\n", f.groundSpeed)
+		}
+		if f.validTrackRate {
+			fprintf(output, "  Track Rate    : %0.3f°/s\n", f.trackRate)
+		}
+		if f.validTrueAirSpeed {
+			fprintf(output, "  True Airspeed : %d knots\n", f.trueAirSpeed)
+		}
+	case BdsEhsHeadingSpeed:
+		if f.validMagneticHeading {
+			fprintf(output, "  Mag Heading   : %0.2f°\n", f.magneticHeading)
+		}
+		if f.validIndicatedAirSpeed {
+			fprintf(output, "  Ind Airspeed  : %d knots\n", f.indicatedAirSpeed)
+		}
+		if f.validMach {
+			fprintf(output, "  Mach          : %0.3f\n", f.mach)
+		}
+		if f.validBaroVerticalRate {
+			fprintf(output, "  Baro Alt Rate : %d ft/min\n", f.baroVerticalRate)
+		}
+		if f.validInertialVerticalRate {
+			fprintf(output, "  Ins Vert Rate : %d ft/min\n", f.inertialVerticalRate)
+		}
+	case BdsMetRoutineAirReport, BdsMetHazartReport:
+		if f.validWind {
+			fprintf(output, "  Wind          : %d knots from %0.1f°\n", f.windSpeed, f.windDirection)
+		}
+		if f.validStaticAirTemperature {
+			fprintf(output, "  Air Temp      : %0.2f°C\n", f.staticAirTemperature)
+		}
+		if f.validAverageStaticPressure {
+			fprintf(output, "  Pressure      : %d hPa\n", f.averageStaticPressure)
+		}
+		if f.validHumidity {
+			fprintf(output, "  Humidity      : %0.1f%%\n", f.humidity)
+		}
+		if f.validRadioHeight {
+			fprintf(output, "  Radio Height  : %d ft\n", f.radioHeight)
+		}
+		if f.validTurbulence {
+			fprintf(output, "  Turbulence    : %s\n", HazardLevelString(f.turbulence))
+		}
+		if f.validWindShear {
+			fprintf(output, "  Wind Shear    : %s\n", HazardLevelString(f.windShear))
+		}
+		if f.validMicroburst {
+			fprintf(output, "  Microburst    : %s\n", HazardLevelString(f.microburst))
+		}
+		if f.validIcing {
+			fprintf(output, "  Icing         : %s\n", HazardLevelString(f.icing))
+		}
+		if f.validWakeVortex {
+			fprintf(output, "  Wake Vortex   : %s\n", HazardLevelString(f.wakeVortex))
+		}
+	}
+}
+
+func (f *Frame) showAcasRa(output io.Writer) {
+	if !f.AcasRaValid() {
+		return
+	}
+	fprintln(output, "ACAS Resolution Advisory")
+	fprintf(output, "  Active RA     : %s\n", strings.Join(f.AcasActiveRa(), ", "))
+	fprintf(output, "  RA Complement : %s\n", strings.Join(f.AcasRaComplements(), ", "))
+	fprintf(output, "  Terminated    : %t\n", f.raTerminated)
+	fprintf(output, "  Multi Threat  : %t\n", f.multipleThreat)
+	switch f.threatType {
+	case 1:
+		fprintf(output, "  Threat ICAO   : %06X\n", f.threatIcao)
+	case 2:
+		if f.validThreatAltitude {
+			fprintf(output, "  Threat Alt    : %d ft\n", f.threatAltitude)
+		}
+		if f.validThreatRange {
+			fprintf(output, "  Threat Range  : %0.1f NM\n", f.threatRange)
+		}
+		if f.validThreatBearing {
+			fprintf(output, "  Threat Bearing: %d°\n", f.threatBearing)
+		}
+	}
 }
 
 func (f *Frame) showBitString(output io.Writer) {
@@ -902,12 +1115,13 @@ func (f *Frame) formatBitString(features []featureBreakdown) string {
 	var feature featureDescriptionType
 
 	var fieldBitCounter, subFieldBitCounter, subSubFieldBitCounter int
+	ssk := strconv.Itoa(int(f.messageSubType))
 	for _, feat := range features {
 		var sk string
 
 		// determine any specified sub feature we need to recurse down into
 		switch f.downLinkFormat {
-		case 17:
+		case 17, 18:
 			sk = strconv.Itoa(int(f.messageType))
 		case 20, 21:
 			sk = f.BdsMessageType()
@@ -915,7 +1129,7 @@ func (f *Frame) formatBitString(features []featureBreakdown) string {
 
 		if fieldBitCounter != feat.start {
 			log.Warn().
-				Str("frame", f.raw).
+				Str("frame", f.rawString()).
 				Msgf("Describe: Top Level Fields Not Adding up. (%d %s %d). Expected Start=%d, got=%d", f.downLinkFormat, sk, f.messageSubType, feat.start, fieldBitCounter)
 		}
 		fieldBitCounter = feat.end
@@ -940,24 +1154,24 @@ func (f *Frame) formatBitString(features []featureBreakdown) string {
 			for _, sf := range feat.subFields[sk] {
 				if subFieldBitCounter != sf.start {
 					log.Warn().
-						Str("frame", f.raw).
+						Str("frame", f.rawString()).
 						Msgf("Describe: Second Level Fields Not Adding up. (%d %s %d). Expected Start=%d, got=%d", f.downLinkFormat, sk, f.messageSubType, sf.start, subFieldBitCounter)
 				}
 				subFieldBitCounter = sf.end
-				if 0 == len(sf.subFields[sk]) {
+				if 0 == len(sf.subFields[ssk]) {
 					doMakeBitString(sf)
 					doMakeFooterString(sf, " -> ")
 
 				} else {
 					feature = featureDescription[sf.name]
 					subSubFieldBitCounter = sf.start
-					ssk := strconv.Itoa(int(f.messageSubType))
 					for _, ssf := range sf.subFields[ssk] {
 						if subSubFieldBitCounter != ssf.start {
 							log.Warn().
-								Str("frame", f.raw).
+								Str("frame", f.rawString()).
 								Msgf("Describe: Third Level Fields Not Adding up. (%d %s %d). Expected Start=%d, got=%d", f.downLinkFormat, sk, f.messageSubType, ssf.start, subSubFieldBitCounter)
 						}
+						subSubFieldBitCounter = ssf.end
 						doMakeBitString(ssf)
 						doMakeFooterString(ssf, "   -> ")
 					}
@@ -0,0 +1,40 @@
+package mode_s
+
+// EmergencyState is the emergency/priority status of an aircraft, the values are the DF17 Type 28 Subtype 1 codes
+type EmergencyState byte
+
+const (
+	EmergencyNone EmergencyState = iota
+	EmergencyGeneral
+	EmergencyLifeguard
+	EmergencyMinimumFuel
+	EmergencyNoCommunications // NORDO
+	EmergencyUnlawfulInterference
+	EmergencyDownedAircraft
+	EmergencyReserved
+)
+
+func (e EmergencyState) String() string {
+	if s, ok := emergencyStateTable[int(e)]; ok {
+		return s
+	}
+	return emergencyStateTable[int(EmergencyReserved)]
+}
+
+// IsEmergency tells us if this state is anything other than no emergency
+func (e EmergencyState) IsEmergency() bool {
+	return EmergencyNone != e
+}
+
+// EmergencyStateFromSquawk gives us the emergency that a squawk of 7500, 7600 or 7700 means
+func EmergencyStateFromSquawk(squawk uint32) EmergencyState {
+	switch squawk {
+	case 7500:
+		return EmergencyUnlawfulInterference
+	case 7600:
+		return EmergencyNoCommunications
+	case 7700:
+		return EmergencyGeneral
+	}
+	return EmergencyNone
+}
@@ -0,0 +1,180 @@
+package mode_s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type (
+	// Field is one field of a decoded frame, with where it sits in the message and what we made of it
+	Field struct {
+		Name    string // the short name of the field, e.g. DF, AA, ME
+		Field   string `json:",omitempty"`
+		Meaning string `json:",omitempty"`
+		// Start and End are the first and last bit of the field, counting from 0
+		Start, End int
+		Bits       string
+		Raw        uint64
+		// Value is our interpretation of the field, if we have one
+		Value interface{} `json:",omitempty"`
+	}
+
+	// frameJson is what MarshalJSON gives for a frame
+	frameJson struct {
+		Icao           string
+		AddressType    string
+		DownlinkFormat byte
+		Description    string
+		DataSource     string
+		Raw            string
+		TimeStamp      time.Time
+		BdsMessageType string `json:",omitempty"`
+		CorrectedBits  int    `json:",omitempty"`
+		Fields         []Field
+	}
+)
+
+// Fields breaks the frame down into its fields, using the same layouts as Describe
+func (f *Frame) Fields() []Field {
+	if nil == f || nil == f.message {
+		return nil
+	}
+	features, ok := frameFeatures[f.downLinkFormat]
+	if !ok {
+		return nil
+	}
+
+	// the keys for the layout of any sub fields, see formatBitString
+	var sk string
+	switch f.downLinkFormat {
+	case 17, 18:
+		sk = strconv.Itoa(int(f.messageType))
+	case 20, 21:
+		sk = f.BdsMessageType()
+	}
+	ssk := strconv.Itoa(int(f.messageSubType))
+
+	fields := make([]Field, 0, len(features))
+	for _, feat := range features {
+		if 0 == len(feat.subFields[sk]) {
+			fields = append(fields, f.field(feat))
+			continue
+		}
+		for _, sf := range feat.subFields[sk] {
+			if 0 == len(sf.subFields[ssk]) {
+				fields = append(fields, f.field(sf))
+				continue
+			}
+			for _, ssf := range sf.subFields[ssk] {
+				fields = append(fields, f.field(ssf))
+			}
+		}
+	}
+	return fields
+}
+
+// MarshalJSON gives us the frame with all of its fields
+func (f *Frame) MarshalJSON() ([]byte, error) {
+	if nil == f {
+		return []byte("null"), nil
+	}
+	fj := frameJson{
+		Icao:           f.IcaoStr(),
+		AddressType:    f.AddressType().String(),
+		DownlinkFormat: f.downLinkFormat,
+		Description:    f.DownLinkFormat(),
+		DataSource:     f.DataSource(),
+		Raw:            f.rawString(),
+		TimeStamp:      f.timeStamp,
+		CorrectedBits:  f.correctedBits,
+		Fields:         f.Fields(),
+	}
+	if 20 == f.downLinkFormat || 21 == f.downLinkFormat {
+		fj.BdsMessageType = f.BdsMessageType()
+	}
+	return json.Marshal(fj)
+}
+
+func (f *Frame) field(feat featureBreakdown) Field {
+	field := Field{
+		Name:  feat.name,
+		Start: feat.start,
+		End:   feat.end - 1,
+	}
+	if "" != feat.longName {
+		field.Field = feat.name
+		field.Meaning = feat.longName
+	} else {
+		description := featureDescription[feat.name]
+		field.Field = description.field
+		field.Meaning = description.meaning
+	}
+
+	bits := make([]byte, 0, feat.end-feat.start)
+	for i := feat.start; i < feat.end && i < len(f.message)*8; i++ {
+		bit := f.message[i/8] >> (7 - i%8) & 1
+		field.Raw = field.Raw<<1 | uint64(bit)
+		bits = append(bits, '0'+bit)
+	}
+	field.Bits = string(bits)
+	field.Value = f.fieldValue(feat.name, field.Raw)
+	return field
+}
+
+// fieldValue interprets the raw value of a field, nil if there is nothing more to say than the raw value
+func (f *Frame) fieldValue(name string, raw uint64) interface{} {
+	switch name {
+	case "DF":
+		return f.DownLinkFormat()
+	case "CA":
+		return capabilityTable[byte(raw)]
+	case "CF":
+		return controlFieldTable[byte(raw)]
+	case "AA":
+		return fmt.Sprintf("%06X", raw)
+	case "AP":
+		// the address is overlaid on the parity
+		return f.IcaoStr()
+	case "FS":
+		return flightStatusTable[byte(raw)]
+	case "VS":
+		if 0 == raw {
+			return "Airborne"
+		}
+		return "On The Ground"
+	case "AC":
+		if alt, err := f.Altitude(); nil == err {
+			return fmt.Sprintf("%d %s", alt, f.AltitudeUnits())
+		}
+	case "ID":
+		if 0 != f.identity {
+			return fmt.Sprintf("%04d", f.identity)
+		}
+	case "TC":
+		return f.MessageTypeString()
+	case "CHAR":
+		return string(aisCharset[raw&63])
+	case "CPR":
+		if 0 == raw {
+			return "Even"
+		}
+		return "Odd"
+	case "SS":
+		return surveillanceStatus[raw&3]
+	case "EID":
+		return EmergencyString(int(raw))
+	case "HD":
+		if heading, err := f.Heading(); nil == err {
+			return heading
+		}
+	case "VR":
+		if vr, err := f.VerticalRate(); nil == err {
+			return vr
+		}
+	case "BDS#":
+		return f.BdsMessageType()
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package mode_s
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrame_Fields(t *testing.T) {
+	type want struct {
+		name, bits string
+		raw        uint64
+		value      interface{}
+	}
+	tests := []struct {
+		name   string
+		frame  string
+		names  string
+		checks map[int]want
+	}{
+		{
+			name:  "DF4 Altitude",
+			frame: "200018386DFEF1",
+			names: "DF FS DR UM AC AP",
+			checks: map[int]want{
+				0: {name: "DF", bits: "00100", raw: 4, value: "Roll Call Reply - altitude (~100ft accuracy)"},
+				4: {name: "AC", bits: "1100000111000", raw: 6200, value: "38000 feet"},
+				5: {name: "AP", raw: 0x6DFEF1, value: "7C7DAA"},
+			},
+		},
+		{
+			name:  "DF17 Identification",
+			frame: "8D4840D6202CC371C32CE0576098",
+			names: "DF CA AA TC CAT CHAR CHAR CHAR CHAR CHAR CHAR CHAR CHAR PI",
+			checks: map[int]want{
+				2:  {name: "AA", raw: 0x4840D6, value: "4840D6"},
+				5:  {name: "CHAR", bits: "001011", raw: 11, value: "K"},
+				12: {name: "CHAR", bits: "100000", raw: 32, value: " "},
+			},
+		},
+		{
+			name:  "DF17 Position",
+			frame: "8D40621D58C382D690C8AC2863A7",
+			names: "DF CA AA TC SS NICb AC TI CPR LAT LON PI",
+			checks: map[int]want{
+				8:  {name: "CPR", bits: "0", raw: 0, value: "Even"},
+				9:  {name: "LAT", raw: 93000},
+				10: {name: "LON", raw: 51372},
+			},
+		},
+		{
+			name:  "DF17 Velocity",
+			frame: "8D485020994409940838175B284F",
+			names: "DF CA AA TC SUB IC IFR NACv EWD EWV NSD NSV VSRC VRS VR ?? HAED HAEV PI",
+			checks: map[int]want{
+				9:  {name: "EWV", bits: "0000001001", raw: 9},
+				14: {name: "VR", raw: 14, value: -832},
+			},
+		},
+		{
+			name:  "DF18 Control Field",
+			frame: "907C123458C382D690C8AC6859C9",
+			names: "DF CF AA TC SS NICb AC TI CPR LAT LON PI",
+			checks: map[int]want{
+				1: {name: "CF", bits: "000", raw: 0, value: "ADS-B ES/NT device with ICAO address"},
+			},
+		},
+		{
+			name:  "DF20 Selected Vertical Intention",
+			frame: "A0001838CA3E51F0A8000047A9B2",
+			names: "DF FS DR UM AC S MCP ALT S FMS ALT S BARO ?? S VNAV ALT APP ?? S SRC AP",
+			checks: map[int]want{
+				6: {name: "MCP ALT", bits: "100101000111", raw: 2375},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := DecodeString(tt.frame, time.Now())
+			if nil != err {
+				t.Fatalf("Failed to decode frame %s: %s", tt.frame, err)
+			}
+			fields := frame.Fields()
+
+			var names []string
+			bitCount := 0
+			for i, field := range fields {
+				names = append(names, strings.ReplaceAll(field.Name, " ", " "))
+				if field.Start != bitCount {
+					t.Errorf("Field %d (%s) starts at bit %d, expected %d", i, field.Name, field.Start, bitCount)
+				}
+				if len(field.Bits) != field.End-field.Start+1 {
+					t.Errorf("Field %d (%s) has %d bits for range %d-%d", i, field.Name, len(field.Bits), field.Start, field.End)
+				}
+				bitCount = field.End + 1
+			}
+			if bitCount != len(tt.frame)*4 {
+				t.Errorf("Fields cover %d bits, expected %d", bitCount, len(tt.frame)*4)
+			}
+			if got := strings.Join(names, " "); got != tt.names {
+				t.Errorf("Fields() = %s, want %s", got, tt.names)
+			}
+
+			for i, w := range tt.checks {
+				if i >= len(fields) {
+					t.Errorf("Missing field %d (%s)", i, w.name)
+					continue
+				}
+				got := fields[i]
+				if w.name != got.Name {
+					t.Errorf("Field %d name = %s, want %s", i, got.Name, w.name)
+				}
+				if "" != w.bits && w.bits != got.Bits {
+					t.Errorf("Field %d (%s) bits = %s, want %s", i, got.Name, got.Bits, w.bits)
+				}
+				if w.raw != got.Raw {
+					t.Errorf("Field %d (%s) raw = %d, want %d", i, got.Name, got.Raw, w.raw)
+				}
+				if w.value != got.Value {
+					t.Errorf("Field %d (%s) value = %v, want %v", i, got.Name, got.Value, w.value)
+				}
+			}
+		})
+	}
+}
+
+func TestFrame_MarshalJSON(t *testing.T) {
+	frame, err := DecodeString("8D4840D6202CC371C32CE0576098", time.Now())
+	if nil != err {
+		t.Fatalf("Failed to decode frame: %s", err)
+	}
+	b, err := json.Marshal(frame)
+	if nil != err {
+		t.Fatalf("MarshalJSON() error = %s", err)
+	}
+
+	var decoded struct {
+		Icao           string
+		DownlinkFormat byte
+		DataSource     string
+		Raw            string
+		Fields         []Field
+	}
+	if err = json.Unmarshal(b, &decoded); nil != err {
+		t.Fatalf("Failed to unmarshal %s: %s", b, err)
+	}
+	if "4840D6" != decoded.Icao || 17 != decoded.DownlinkFormat || DataSourceAdsb != decoded.DataSource {
+		t.Errorf("Unexpected frame details %s", b)
+	}
+	if "8D4840D6202CC371C32CE0576098" != decoded.Raw {
+		t.Errorf("Raw = %s, want 8D4840D6202CC371C32CE0576098", decoded.Raw)
+	}
+	if len(frame.Fields()) != len(decoded.Fields) {
+		t.Errorf("Expected %d fields, got %d", len(frame.Fields()), len(decoded.Fields))
+	}
+
+	var nilFrame *Frame
+	if b, err = json.Marshal(nilFrame); nil != err || "null" != string(b) {
+		t.Errorf("MarshalJSON() of a nil frame = %s, %v", b, err)
+	}
+}
@@ -28,6 +28,25 @@ const (
 	DF17FrameTcasRA                   = "Extended Squitter Aircraft status (1090ES TCAS Resolution Advisory)"
 	DF17FrameTargetStateStatus        = "Target State and status Message"
 	DF17FrameAircraftOperational      = "Aircraft Operational status Message"
+
+	DataSourceModeS  = "Mode S"
+	DataSourceAdsb   = "ADS-B"
+	DataSourceAdsr   = "ADS-R"
+	DataSourceTisb   = "TIS-B"
+	DataSourceMlat   = "MLAT"
+	DataSourceModeAc = "Mode A/C"
+	DataSourceUat    = "UAT"
+)
+
+// AddressType tells us if the address in the frame is a real ICAO address, or something else
+type AddressType byte
+
+const (
+	AddressTypeIcao      AddressType = iota // a real 24 bit ICAO address
+	AddressTypeAdsbOther                    // DF18 CF=1, an ADS-B device with a non ICAO address
+	AddressTypeTisbOther                    // TIS-B with a non ICAO (track file) address
+	AddressTypeAdsrOther                    // ADS-R rebroadcast with a non ICAO address
+	AddressTypeModeAc                       // a Mode A/C only target, the address is the Mode A code
 )
 
 type (
@@ -67,9 +86,10 @@ type (
 		messageType    byte // DF17 Extended Squitter Message Type
 		messageSubType byte // DF17 Extended Squitter Message Sub Type
 
-		cprFlagOddEven int    /* 1 = Odd, 0 = Even CPR message. */
-		timeFlag       int    /* UTC synchronized? */
-		flight         []byte /* 8 chars flight number. */
+		cprFlagOddEven int     /* 1 = Odd, 0 = Even CPR message. */
+		timeFlag       int     /* UTC synchronized? */
+		flight         [8]byte /* 8 chars flight number. */
+		validFlight    bool
 
 		validCompatibilityClass bool
 		compatibilityClass      int
@@ -100,166 +120,102 @@ type (
 		intentChange  byte
 		ifrCapability byte
 		nacV          byte
-	}
 
-	extendedSquitter struct {
-		Df byte   `bits:"0-5" name:"DF" desc:"Downlink Format"`
-		Ca byte   `bits:"5-8" name:"CA" desc:"Aircraft System Capability"`
-		Aa uint32 `bits:"8-32" name:"AA" desc:"Address Announce, ICAO Identification"`
-		Me uint64 `bits:"32-88" name:"ME" desc:"ADSB Message"`
-		Pi uint32 `bits:"88-111" name:"PI" desc:"Parity/Interr.Identity: reports source of interrogation. Contains the parity overlaid on the interrogator identity code"`
-
-		df17me1
-
-		df17me19t1
-		df17me19t3
-
-		df17me28t1
-		df17me28t2
-
-		df17me29t0
-		df17me29t1
-		df17me29t2
-
-		df17me31t0
-		df17me31t1
-	}
-
-	// df17me1 is Aircraft Identification and Category
-	df17me1 struct {
-		CategoryType    byte `bits:"33-38" name:"CAT" desc:"Aircraft Category"`
-		CategorySubType byte `bits:"38-41" name:"CAT" desc:"Aircraft Category Sub Type"`
-		IdentChar1      byte `bits:"41-47" name:"ident char"`
-		IdentChar2      byte `bits:"47-53" name:"ident char"`
-		IdentChar3      byte `bits:"53-59" name:"ident char"`
-		IdentChar4      byte `bits:"59-65" name:"ident char"`
-		IdentChar5      byte `bits:"65-71" name:"ident char"`
-		IdentChar6      byte `bits:"71-77" name:"ident char"`
-		IdentChar7      byte `bits:"77-83" name:"ident char"`
-		IdentChar8      byte `bits:"83-89" name:"ident char"`
-	}
-
-	// df17me19t1 Airborne Velocity Message Subtype=1 and 2
-	df17me19t1 struct {
-		CategoryType    byte   `bits:"33-38"`
-		CategorySubType byte   `bits:"38-41"`
-		IntentChange    byte   `bits:"41-42" name:"Intent" desc:"Intent Change"`
-		ReservedA       byte   `bits:"42-43" name:"Res" desc:"Reserved-A"`
-		NacV            byte   `bits:"43-46"`
-		EWDir           byte   `bits:"46-47" name:"EW" desc:"(0)East/(1)West Direction"`
-		EWVelocity      uint16 `bits:"47-57" name:"EWV" desc:"East/West Velocity"`
-		NSDir           byte   `bits:"57-58" name:"NS" desc:"(0)North/(1)South Direction"`
-		NSVelocity      uint16 `bits:"58-68" name:"NSV" desc:"North/South Velocity"`
-		VertRateSource  byte   `bits:"68-69" name:"VRS" desc:"Vertical Rate Source"`
-		VertRateSign    byte   `bits:"69-70" name:"VRS+" desc:"Vertical Rate Sign"`
-		VertRate        uint16 `bits:"70-79" name:"VR" desc:"Vertical Rate"`
-		ReservedB       byte   `bits:"79-81" name:"Res" desc:"Reserved-B"`
-		DiffBaroSign    byte   `bits:"81-82" name:"DB+" desc:"Diff from Baro Altitude Sign. 0=up, 1=down"`
-		DiffBaroAlt     byte   `bits:"82-89" name:"DB+" desc:"Diff from Baro Altitude"`
-	}
-
-	// df17me19t3 Airborne Velocity Message Subtype=3 and 4
-	df17me19t3 struct {
-		CategoryType    byte   `bits:"33-38"`
-		CategorySubType byte   `bits:"38-41"`
-		IntentChange    byte   `bits:"41-42" name:"Intent" desc:"Intent Change"`
-		ReservedA       byte   `bits:"42-43" name:"Res" desc:"Reserved-A"`
-		NacV            byte   `bits:"43-46"`
-		HeadingStatus   byte   `bits:"46-47" name:"HDS" desc:"Heading Status"`
-		Heading         uint16 `bits:"47-57" name:"HD" desc:"Heading Status"`
-		AirSpeedType    byte   `bits:"57-58" name:"AST" desc:"Air Speed Type"`
-		AirSpeed        uint16 `bits:"58-68" name:"AS" desc:"Air Speed"`
-		VertRateSource  byte   `bits:"68-69" name:"VRS" desc:"Vertical Rate Source"`
-		VertRateSign    byte   `bits:"69-70" name:"VRS+" desc:"Vertical Rate Sign"`
-		VertRate        uint16 `bits:"70-79" name:"VR" desc:"Vertical Rate"`
-		ReservedB       byte   `bits:"79-81" name:"Res" desc:"Reserved-B"`
-		DiffBaroSign    byte   `bits:"81-82" name:"DB+" desc:"Diff from Baro Altitude Sign. 0=up, 1=down"`
-		DiffBaroAlt     byte   `bits:"82-89" name:"DB+" desc:"Diff from Baro Altitude"`
-	}
-	// df17me28t1 is a Emergency / Priority Status and Mode A Code(Subtype=1)
-	df17me28t1 struct {
-		Type     byte `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType  byte `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		Status   byte `bits:"41-44" name:"Status" desc:"Emergency/Priority Status"`
-		ModeA    byte `bits:"44-57" name:"ModeA" desc:"Mode A Code"`
-		Reserved byte `bits:"57-89" name:"Res" desc:"Reserved"`
-	}
-	// df17me28t2 is a 1090ES TCAS Resolution Advisory (RA) Broadcast Message (Subtype=2
-	df17me28t2 struct {
-		Type    byte   `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType byte   `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		Ara     uint16 `bits:"41-55" name:"ARA" desc:"Active Resolution Advisories"`
-		Racs    byte   `bits:"55-59" name:"RACs" desc:"RACs Record"`
-		Rat     byte   `bits:"59-60" name:"RAT" desc:"RA Terminated"`
-		Mte     byte   `bits:"60-61" name:"MTE" desc:"Multiple Threat Encounter"`
-		Tti     byte   `bits:"61-63" name:"TTI" desc:"Threat Type Indicator"`
-		Tid     byte   `bits:"63-89" name:"TID" desc:"Threat Identity Data"`
-	}
-	df17me29t0 struct {
-		// DO-260A
-		Type    byte   `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType byte   `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		unknown uint64 `bit:"40-89" name:"Unknown"`
-	}
-	df17me29t1 struct {
-		// DO-260B - http://www.anteni.net/adsb/Doc/1090-WP30-18-DRAFT_DO-260B-V42.pdf
-		Type            byte `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType         byte `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		SilSupplement   byte `bits:"40-41" name:"SIL sup" desc:"SIL Supplement: SIL Per Hour (0) or Per Sample (1)"`
-		AltType         byte `bits:"41-42" name:"Sel Alt Type" desc:"Selected Altitude Type 0 = Mode Control Panel/Flight Control Unit, 1=Flight Management System"`
-		SelectedAlt     uint `bits:"42-53" name:"Sel Alt" desc:"MCP/FCU Selected Altitude OR FMS Selected Altitude"`
-		BaroSetting     uint `bits:"53-62" name:"Baro Set" desc:"Barometric Pressure Setting (minus 800 millibars)"`
-		SelHeadingStat  byte `bits:"62-63" name:"Sel Hd Stat" desc:"Selected Heading Status"`
-		SelHeadingSign  byte `bits:"63-64" name:"Sign" desc:"Selected Heading Sign"`
-		SelHeading      byte `bits:"64-72" name:"Sel Hd" desc:"Selected Heading"`
-		NacP            byte `bits:"72-76" name:"NACp" desc:"Navigation Accuracy Category_Position"`
-		NicBaro         byte `bits:"76-77" name:"NICbaro" desc:"Navigation Integrity Category_Baro"`
-		Sil             byte `bits:"77-79" name:"SIL" desc:"Source Integrity Level"`
-		McuFpuStatus    byte `bits:"79-80" name:"MCU/FPU" desc:"MCU/FPU Status"`
-		AutoPilot       byte `bits:"80-81" name:"Autopilot" desc:"Autopilot Engaged"`
-		VNav            byte `bits:"81-82" name:"VNAV" desc:"VNav Mode Engaged"`
-		AltHoldMode     byte `bits:"82-83" name:"Alt Hold" desc:"Altitude Hold Mode"`
-		ResAdsR         byte `bits:"83-84" name:"ads-r" desc:"Reserved for ADS-R"`
-		ApproachMode    byte `bits:"84-85" name:"Approach Mode" desc:"Approach Mode"`
-		TcasOperational byte `bits:"85-86" name:"TCAS" desc:"TCAS Operational"`
-		Reserved        byte `bits:"86-89" name:"Res" desc:"Reserved"`
-	}
-	df17me29t2 struct {
-		// DO-260A
-		Type     byte   `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType  byte   `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		reserved uint64 `bits:"40-89" name:"Unknown"`
-	}
-	df17me31t0 struct {
-		Type            byte   `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType         byte   `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		CapClass        uint16 `bits:"41-57" name:"CC" desc:"Capability Class"`
-		OperationalMode uint16 `bits:"57-73" name:"OM" desc:"Operational Mode Codes"`
-		MopsVer         byte   `bits:"73-76" name:"MOPS" desc:"MOPS Version"`
-		NicSuppA        byte   `bits:"76-77" name:"NicA" desc:"NIC Supp-A"`
-		NACp            byte   `bits:"77-81" name:"NicA" desc:"NIC Supp-A"`
-		Gva             byte   `bits:"81-83" name:"GVA" desc:"Geometric  Vertical  Accuracy"`
-		Sil             byte   `bits:"83-85" name:"SIL" desc:"Source Integrity Level"`
-		NicBaro         byte   `bits:"85-86" name:"NICbaro" desc:"NICbaro"`
-		Hrd             byte   `bits:"86-87" name:"HRD" desc:"Horizontal Reference Direction. 0=True North, 1=Magnetic"`
-		SilSup          byte   `bits:"87-88" name:"Sil Sup" desc:"SIL Supp"`
-		Reserved        byte   `bits:"88-89" name:"Res" desc:"Reserved"`
-	}
-	df17me31t1 struct {
-		Type            byte   `bits:"33-38" name:"Type" desc:"ADS-B Message Type"`
-		SubType         byte   `bits:"38-41" name:"Sub" desc:"ADS-B Message Sub Type"`
-		CapClassCodes   uint16 `bits:"41-53" name:"CCC" desc:"Capability Class Codes"`
-		LWCodes         byte   `bits:"53-57" name:"L/W C" desc:"L/W Codes"`
-		OperationalMode uint16 `bits:"57-73" name:"OM" desc:"Operational Mode Codes"`
-		MopsVer         byte   `bits:"73-76" name:"VN" desc:"ADS-B MOPS Compliant Version"`
-		NicSuppA        byte   `bits:"76-77" name:"NicA" desc:"NIC Supp-A"`
-		NACp            byte   `bits:"77-81" name:"NicA" desc:"NIC Supp-A"`
-		ReservedA       byte   `bits:"81-83" name:"Res" desc:"ReservedA"`
-		Sil             byte   `bits:"83-85" name:"SIL" desc:"Source Integrity Level"`
-		NicBaro         byte   `bits:"85-86" name:"NICbaro" desc:"NICbaro"`
-		Hrd             byte   `bits:"86-87" name:"HRD" desc:"Horizontal Reference Direction.  0=True North, 1=Magnetic"`
-		SilSup          byte   `bits:"87-88" name:"Sil Sup" desc:"SIL Supp"`
-		ReservedB       byte   `bits:"88-89" name:"Res" desc:"ReservedB"`
+		validEmergencyState bool
+		emergencyState      EmergencyState // Type 28 Subtype 1
+	}
+
+	// ehs is the Enhanced Surveillance data from Comm-B replies (BDS 4,0 5,0 and 6,0)
+	ehs struct {
+		validSelectedAltitudeMcp bool
+		selectedAltitudeMcp      int32 // feet
+		validSelectedAltitudeFms bool
+		selectedAltitudeFms      int32 // feet
+		validBaroSetting         bool
+		baroSetting              float64 // millibars
+
+		validRollAngle    bool
+		rollAngle         float64 // degrees, negative is left wing down
+		validTrueTrack    bool
+		trueTrack         float64 // degrees
+		validGroundSpeed  bool
+		groundSpeed       int // knots
+		validTrackRate    bool
+		trackRate         float64 // degrees/second
+		validTrueAirSpeed bool
+		trueAirSpeed      int // knots
+
+		validMagneticHeading      bool
+		magneticHeading           float64 // degrees
+		validIndicatedAirSpeed    bool
+		indicatedAirSpeed         int // knots
+		validMach                 bool
+		mach                      float64
+		validBaroVerticalRate     bool
+		baroVerticalRate          int // feet/minute
+		validInertialVerticalRate bool
+		inertialVerticalRate      int // feet/minute
+	}
+
+	// acas is an ACAS (TCAS) Resolution Advisory report (BDS 3,0, DF16 MV or DF17 Type 28 Subtype 2)
+	acas struct {
+		validAcasRa    bool
+		activeRa       uint16 // ARA - 14 bits
+		raComplement   byte   // RAC - 4 bits
+		raTerminated   bool
+		multipleThreat bool
+		threatType     byte // TTI - 0 = no identity, 1 = ICAO, 2 = altitude/range/bearing
+		threatIcao     uint32
+
+		validThreatAltitude bool
+		threatAltitude      int32 // feet
+		validThreatRange    bool
+		threatRange         float64 // nautical miles
+		validThreatBearing  bool
+		threatBearing       int // degrees, relative to the aircraft's heading
+	}
+
+	// targetState is the DF17 Type 29 Target State and Status (DO-260B) info that is not also in ehs
+	targetState struct {
+		validSelectedHeading bool
+		selectedHeading      float64 // degrees
+
+		validAutopilotModes bool
+		autopilotEngaged    bool
+		vnavEngaged         bool
+		lnavEngaged         bool
+		altitudeHoldEngaged bool
+		approachEngaged     bool
+
+		validTcasOperational bool
+		tcasOperational      bool
+	}
+
+	// met is the Meteorological data from Comm-B replies (BDS 4,4 and 4,5)
+	met struct {
+		validWind                  bool
+		windSpeed                  int     // knots
+		windDirection              float64 // degrees
+		validStaticAirTemperature  bool
+		staticAirTemperature       float64 // celsius
+		validAverageStaticPressure bool
+		averageStaticPressure      int // hPa
+		validHumidity              bool
+		humidity                   float64 // percent
+		validRadioHeight           bool
+		radioHeight                int // feet
+
+		// hazards, see metHazardTable
+		validTurbulence bool
+		turbulence      byte
+		validWindShear  bool
+		windShear       byte
+		validMicroburst bool
+		microburst      byte
+		validIcing      bool
+		icing           byte
+		validWakeVortex bool
+		wakeVortex      byte
 	}
 
 	rawFields struct {
@@ -282,6 +238,10 @@ type (
 		rawFields
 		bds
 		df17
+		ehs
+		acas
+		targetState
+		met
 		Position
 		mode string
 		// the timestamp we are processing this message at
@@ -297,17 +257,34 @@ type (
 		message        []byte
 		downLinkFormat byte // Down link Format (DF)
 		icao           uint32
+		addressType    AddressType
+		mlat           bool // this frame came from multilateration
 		crc, checkSum  uint32
+		correctedBits  int    // how many bit errors we fixed
 		identity       uint32 // squawk identity
 		special        string
 		emergency      string
 		alert          bool
+		// errorCorrection is how many bit errors we will try to fix, see SetErrorCorrection
+		errorCorrection ErrorCorrection
 		// if we have trouble decoding our frame, the message ends up here
 		err error
+		// buf backs message, saving an allocation per frame
+		buf [modesLongMsgBytes]byte
 	}
 )
 
 var (
+	controlFieldTable = map[byte]string{
+		0: "ADS-B ES/NT device with ICAO address",
+		1: "ADS-B ES/NT device with other address",
+		2: "Fine TIS-B",
+		3: "Coarse TIS-B",
+		4: "TIS-B and ADS-R management",
+		5: "Fine TIS-B with other address",
+		6: "ADS-R rebroadcast",
+		7: "Reserved",
+	}
 	downlinkFormatTable = map[byte]string{
 		0:  "Short air-air surveillance (TCAS)",
 		4:  "Roll Call Reply - altitude (~100ft accuracy)",
@@ -524,6 +501,39 @@ var (
 		2: "Temporary alert (change in Mode A identity code other than emergency condition)",
 		3: "SPI condition",
 	}
+
+	// activeRaTable is for when ARA bit 41 is set (one threat, or all threats in the same direction)
+	// ARA bits 42-47 each give a meaning when set and when not set
+	activeRaTable = [][2]string{
+		{"Corrective", "Preventive"},
+		{"Downward Sense", "Upward Sense"},
+		{"Increased Rate", ""},
+		{"Sense Reversal", ""},
+		{"Altitude Crossing", ""},
+		{"Positive", "Vertical Speed Limit"},
+	}
+	// activeRaMultiThreatTable is for when ARA bit 41 is not set and there are multiple threats
+	activeRaMultiThreatTable = [][2]string{
+		{"Correction in Upward Sense", ""},
+		{"Positive Climb", ""},
+		{"Correction in Downward Sense", ""},
+		{"Positive Descend", ""},
+		{"Crossing", ""},
+		{"Sense Reversal", ""},
+	}
+	raComplementTable = []string{
+		"Do Not Pass Below",
+		"Do Not Pass Above",
+		"Do Not Turn Left",
+		"Do Not Turn Right",
+	}
+
+	metHazardTable = []string{
+		0: "NIL",
+		1: "Light",
+		2: "Moderate",
+		3: "Severe",
+	}
 )
 
 func (f *Frame) MessageTypeString() string {
@@ -572,6 +582,61 @@ func (f *Frame) MessageTypeString() string {
 	return name
 }
 
+// AddressType tells us what sort of address Icao() is
+func (f *Frame) AddressType() AddressType {
+	if nil == f {
+		return AddressTypeIcao
+	}
+	return f.addressType
+}
+
+func (a AddressType) String() string {
+	switch a {
+	case AddressTypeIcao:
+		return "ICAO"
+	case AddressTypeAdsbOther:
+		return "ADS-B (non ICAO)"
+	case AddressTypeTisbOther:
+		return "TIS-B (non ICAO)"
+	case AddressTypeAdsrOther:
+		return "ADS-R (non ICAO)"
+	case AddressTypeModeAc:
+		return "Mode A/C"
+	}
+	return "Unknown"
+}
+
+// SetMlat marks the frame as one that was generated by multilateration and not sent by the aircraft itself
+func (f *Frame) SetMlat(mlat bool) {
+	if nil != f {
+		f.mlat = mlat
+	}
+}
+
+// DataSource tells us how we got this frame. One of ADS-B, ADS-R, TIS-B, Mode S or MLAT
+func (f *Frame) DataSource() string {
+	if nil == f {
+		return ""
+	}
+	if f.mlat {
+		return DataSourceMlat
+	}
+	switch f.downLinkFormat {
+	case 17:
+		return DataSourceAdsb
+	case 18:
+		switch f.ca {
+		case 0, 1:
+			return DataSourceAdsb
+		case 2, 3, 4, 5:
+			return DataSourceTisb
+		case 6:
+			return DataSourceAdsr
+		}
+	}
+	return DataSourceModeS
+}
+
 func (f *Frame) DownLinkType() byte {
 	return f.downLinkFormat
 }
@@ -580,11 +645,31 @@ func (f *Frame) Icao() uint32 {
 	return f.icao
 }
 
+// IcaoFromParity tells us the ICAO was recovered from the Address/Parity field and has not been checked by a CRC
+func (f *Frame) IcaoFromParity() bool {
+	if nil == f {
+		return false
+	}
+	switch f.downLinkFormat {
+	case 0, 4, 5, 16, 20, 21:
+		return true
+	}
+	return false
+}
+
 func (f *Frame) Raw() []byte {
 	if nil == f {
 		return []byte{}
 	}
-	return []byte(f.raw)
+	return []byte(f.rawString())
+}
+
+// Message is the binary Mode S message, with any bit errors we fixed corrected
+func (f *Frame) Message() []byte {
+	if nil == f {
+		return nil
+	}
+	return f.message
 }
 
 func (f *Frame) IcaoStr() string {
@@ -766,6 +851,20 @@ func (f *Frame) Category() string {
 	}
 	return aircraftCategory[f.catType][f.catSubType]
 }
+
+// CategoryString gives us the ADS-B emitter category description, catType 0 is set A through to 3 for set D
+func CategoryString(catType, catSubType byte) string {
+	if int(catType) >= len(aircraftCategory) || int(catSubType) >= len(aircraftCategory[catType]) {
+		return ""
+	}
+	return aircraftCategory[catType][catSubType]
+}
+
+// EmergencyString gives us the description of an ADS-B emergency/priority status
+func EmergencyString(state int) string {
+	return emergencyStateTable[state]
+}
+
 func (f *Frame) CategoryType() string {
 	return fmt.Sprintf("%d/%d", f.catType, f.catSubType)
 }
@@ -784,7 +883,10 @@ func (f *Frame) IsEven() bool {
 }
 
 func (f *Frame) FlightNumber() string {
-	return string(f.flight)
+	if !f.validFlight {
+		return ""
+	}
+	return string(f.flight[:])
 }
 func (f *Frame) Special() string {
 	return f.special
@@ -802,11 +904,512 @@ func (f *Frame) Emergency() string {
 	return f.emergency
 }
 
+// EmergencyState is the emergency/priority status the aircraft is broadcasting (DF17 Type 28 Subtype 1)
+func (f *Frame) EmergencyState() (EmergencyState, error) {
+	if f.EmergencyStateValid() {
+		return f.emergencyState, nil
+	}
+	return EmergencyNone, fmt.Errorf("emergency/priority status is not valid")
+}
+func (f *Frame) EmergencyStateValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validEmergencyState
+}
+
+func (f *Frame) SelectedAltitudeMcp() (int32, error) {
+	if f.SelectedAltitudeMcpValid() {
+		return f.selectedAltitudeMcp, nil
+	}
+	return 0, fmt.Errorf("MCP/FCU selected altitude is not valid")
+}
+func (f *Frame) SelectedAltitudeMcpValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validSelectedAltitudeMcp
+}
+
+func (f *Frame) SelectedAltitudeFms() (int32, error) {
+	if f.SelectedAltitudeFmsValid() {
+		return f.selectedAltitudeFms, nil
+	}
+	return 0, fmt.Errorf("FMS selected altitude is not valid")
+}
+func (f *Frame) SelectedAltitudeFmsValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validSelectedAltitudeFms
+}
+
+// BaroSetting is the barometric pressure setting in millibars
+func (f *Frame) BaroSetting() (float64, error) {
+	if f.BaroSettingValid() {
+		return f.baroSetting, nil
+	}
+	return 0, fmt.Errorf("barometric pressure setting is not valid")
+}
+func (f *Frame) BaroSettingValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validBaroSetting
+}
+
+// RollAngle in degrees, a negative roll is left wing down
+func (f *Frame) RollAngle() (float64, error) {
+	if f.RollAngleValid() {
+		return f.rollAngle, nil
+	}
+	return 0, fmt.Errorf("roll angle is not valid")
+}
+func (f *Frame) RollAngleValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validRollAngle
+}
+
+func (f *Frame) TrueTrack() (float64, error) {
+	if f.TrueTrackValid() {
+		return f.trueTrack, nil
+	}
+	return 0, fmt.Errorf("true track angle is not valid")
+}
+func (f *Frame) TrueTrackValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validTrueTrack
+}
+
+// TrackRate is how fast the track angle is changing in degrees/second
+func (f *Frame) TrackRate() (float64, error) {
+	if f.TrackRateValid() {
+		return f.trackRate, nil
+	}
+	return 0, fmt.Errorf("track angle rate is not valid")
+}
+func (f *Frame) TrackRateValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validTrackRate
+}
+
+func (f *Frame) GroundSpeed() (int, error) {
+	if f.GroundSpeedValid() {
+		return f.groundSpeed, nil
+	}
+	return 0, fmt.Errorf("ground speed is not valid")
+}
+func (f *Frame) GroundSpeedValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validGroundSpeed
+}
+
+func (f *Frame) TrueAirSpeed() (int, error) {
+	if f.TrueAirSpeedValid() {
+		return f.trueAirSpeed, nil
+	}
+	return 0, fmt.Errorf("true air speed is not valid")
+}
+func (f *Frame) TrueAirSpeedValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validTrueAirSpeed
+}
+
+func (f *Frame) MagneticHeading() (float64, error) {
+	if f.MagneticHeadingValid() {
+		return f.magneticHeading, nil
+	}
+	return 0, fmt.Errorf("magnetic heading is not valid")
+}
+func (f *Frame) MagneticHeadingValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validMagneticHeading
+}
+
+func (f *Frame) IndicatedAirSpeed() (int, error) {
+	if f.IndicatedAirSpeedValid() {
+		return f.indicatedAirSpeed, nil
+	}
+	return 0, fmt.Errorf("indicated air speed is not valid")
+}
+func (f *Frame) IndicatedAirSpeedValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validIndicatedAirSpeed
+}
+
+func (f *Frame) Mach() (float64, error) {
+	if f.MachValid() {
+		return f.mach, nil
+	}
+	return 0, fmt.Errorf("mach is not valid")
+}
+func (f *Frame) MachValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validMach
+}
+
+func (f *Frame) BaroVerticalRate() (int, error) {
+	if f.BaroVerticalRateValid() {
+		return f.baroVerticalRate, nil
+	}
+	return 0, fmt.Errorf("barometric altitude rate is not valid")
+}
+func (f *Frame) BaroVerticalRateValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validBaroVerticalRate
+}
+
+func (f *Frame) InertialVerticalRate() (int, error) {
+	if f.InertialVerticalRateValid() {
+		return f.inertialVerticalRate, nil
+	}
+	return 0, fmt.Errorf("inertial vertical velocity is not valid")
+}
+func (f *Frame) InertialVerticalRateValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validInertialVerticalRate
+}
+
+// SelectedHeading is the heading (in degrees) selected on the MCP/FCU
+func (f *Frame) SelectedHeading() (float64, error) {
+	if f.SelectedHeadingValid() {
+		return f.selectedHeading, nil
+	}
+	return 0, fmt.Errorf("selected heading is not valid")
+}
+func (f *Frame) SelectedHeadingValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validSelectedHeading
+}
+
+// AutopilotModesValid tells us if the Autopilot, VNAV, LNAV, Altitude Hold and Approach modes are known
+func (f *Frame) AutopilotModesValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validAutopilotModes
+}
+func (f *Frame) AutopilotEngaged() (bool, error) {
+	if f.AutopilotModesValid() {
+		return f.autopilotEngaged, nil
+	}
+	return false, fmt.Errorf("autopilot mode is not valid")
+}
+func (f *Frame) VnavEngaged() (bool, error) {
+	if f.AutopilotModesValid() {
+		return f.vnavEngaged, nil
+	}
+	return false, fmt.Errorf("VNAV mode is not valid")
+}
+func (f *Frame) LnavEngaged() (bool, error) {
+	if f.AutopilotModesValid() {
+		return f.lnavEngaged, nil
+	}
+	return false, fmt.Errorf("LNAV mode is not valid")
+}
+func (f *Frame) AltitudeHoldEngaged() (bool, error) {
+	if f.AutopilotModesValid() {
+		return f.altitudeHoldEngaged, nil
+	}
+	return false, fmt.Errorf("altitude hold mode is not valid")
+}
+func (f *Frame) ApproachEngaged() (bool, error) {
+	if f.AutopilotModesValid() {
+		return f.approachEngaged, nil
+	}
+	return false, fmt.Errorf("approach mode is not valid")
+}
+
+// TcasOperational tells us if the aircraft's TCAS/ACAS system is operational
+func (f *Frame) TcasOperational() (bool, error) {
+	if f.TcasOperationalValid() {
+		return f.tcasOperational, nil
+	}
+	return false, fmt.Errorf("TCAS operational is not valid")
+}
+func (f *Frame) TcasOperationalValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validTcasOperational
+}
+
+func (f *Frame) AcasRaValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validAcasRa
+}
+
+// AcasActiveRa gives a readable list of the resolution advisories that are currently active
+func (f *Frame) AcasActiveRa() []string {
+	if !f.AcasRaValid() {
+		return nil
+	}
+	var table [][2]string
+	if 0 != f.activeRa&0x2000 {
+		table = activeRaTable
+	} else if f.multipleThreat {
+		table = activeRaMultiThreatTable
+	} else {
+		return []string{}
+	}
+
+	ras := make([]string, 0, len(table))
+	for i, meaning := range table {
+		desc := meaning[1]
+		if 0 != f.activeRa&(0x1000>>i) {
+			desc = meaning[0]
+		}
+		if "" != desc {
+			ras = append(ras, desc)
+		}
+	}
+	return ras
+}
+
+// AcasRaComplements gives a readable list of the RA complements (what this aircraft has told the threat not to do)
+func (f *Frame) AcasRaComplements() []string {
+	if !f.AcasRaValid() {
+		return nil
+	}
+	racs := make([]string, 0, len(raComplementTable))
+	for i, rac := range raComplementTable {
+		if 0 != f.raComplement&(0x8>>i) {
+			racs = append(racs, rac)
+		}
+	}
+	return racs
+}
+
+// AcasRaTerminated is set when the RA has just finished
+func (f *Frame) AcasRaTerminated() bool {
+	return f.AcasRaValid() && f.raTerminated
+}
+
+// AcasMultipleThreat is set when there is more than one threat
+func (f *Frame) AcasMultipleThreat() bool {
+	return f.AcasRaValid() && f.multipleThreat
+}
+
+// AcasThreatIcao is the ICAO address of the aircraft that caused the RA, if it was reported
+func (f *Frame) AcasThreatIcao() (uint32, error) {
+	if f.AcasRaValid() && 1 == f.threatType {
+		return f.threatIcao, nil
+	}
+	return 0, fmt.Errorf("threat identity ICAO is not valid")
+}
+
+// AcasThreatAltitude is the altitude of the threat in feet, if it was reported
+func (f *Frame) AcasThreatAltitude() (int32, error) {
+	if f.AcasRaValid() && f.validThreatAltitude {
+		return f.threatAltitude, nil
+	}
+	return 0, fmt.Errorf("threat altitude is not valid")
+}
+
+// AcasThreatRange is how far away the threat is in nautical miles, if it was reported
+func (f *Frame) AcasThreatRange() (float64, error) {
+	if f.AcasRaValid() && f.validThreatRange {
+		return f.threatRange, nil
+	}
+	return 0, fmt.Errorf("threat range is not valid")
+}
+
+// AcasThreatBearing is the bearing (relative to our heading) to the threat in degrees, if it was reported
+func (f *Frame) AcasThreatBearing() (int, error) {
+	if f.AcasRaValid() && f.validThreatBearing {
+		return f.threatBearing, nil
+	}
+	return 0, fmt.Errorf("threat bearing is not valid")
+}
+
+// Wind gives the wind speed (knots) and the direction (degrees) it is blowing from
+func (f *Frame) Wind() (int, float64, error) {
+	if f.WindValid() {
+		return f.windSpeed, f.windDirection, nil
+	}
+	return 0, 0, fmt.Errorf("wind speed and direction is not valid")
+}
+func (f *Frame) WindValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validWind
+}
+
+// StaticAirTemperature in degrees celsius
+func (f *Frame) StaticAirTemperature() (float64, error) {
+	if f.StaticAirTemperatureValid() {
+		return f.staticAirTemperature, nil
+	}
+	return 0, fmt.Errorf("static air temperature is not valid")
+}
+func (f *Frame) StaticAirTemperatureValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validStaticAirTemperature
+}
+
+// AverageStaticPressure in hPa
+func (f *Frame) AverageStaticPressure() (int, error) {
+	if f.AverageStaticPressureValid() {
+		return f.averageStaticPressure, nil
+	}
+	return 0, fmt.Errorf("average static pressure is not valid")
+}
+func (f *Frame) AverageStaticPressureValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validAverageStaticPressure
+}
+
+// Humidity is the relative humidity as a percentage
+func (f *Frame) Humidity() (float64, error) {
+	if f.HumidityValid() {
+		return f.humidity, nil
+	}
+	return 0, fmt.Errorf("humidity is not valid")
+}
+func (f *Frame) HumidityValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validHumidity
+}
+
+// RadioHeight in feet
+func (f *Frame) RadioHeight() (int, error) {
+	if f.RadioHeightValid() {
+		return f.radioHeight, nil
+	}
+	return 0, fmt.Errorf("radio height is not valid")
+}
+func (f *Frame) RadioHeightValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validRadioHeight
+}
+
+// Turbulence hazard level, see HazardLevelString
+func (f *Frame) Turbulence() (byte, error) {
+	if f.TurbulenceValid() {
+		return f.turbulence, nil
+	}
+	return 0, fmt.Errorf("turbulence is not valid")
+}
+func (f *Frame) TurbulenceValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validTurbulence
+}
+
+// WindShear hazard level, see HazardLevelString
+func (f *Frame) WindShear() (byte, error) {
+	if f.WindShearValid() {
+		return f.windShear, nil
+	}
+	return 0, fmt.Errorf("wind shear is not valid")
+}
+func (f *Frame) WindShearValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validWindShear
+}
+
+// Microburst hazard level, see HazardLevelString
+func (f *Frame) Microburst() (byte, error) {
+	if f.MicroburstValid() {
+		return f.microburst, nil
+	}
+	return 0, fmt.Errorf("microburst is not valid")
+}
+func (f *Frame) MicroburstValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validMicroburst
+}
+
+// Icing hazard level, see HazardLevelString
+func (f *Frame) Icing() (byte, error) {
+	if f.IcingValid() {
+		return f.icing, nil
+	}
+	return 0, fmt.Errorf("icing is not valid")
+}
+func (f *Frame) IcingValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validIcing
+}
+
+// WakeVortex hazard level, see HazardLevelString
+func (f *Frame) WakeVortex() (byte, error) {
+	if f.WakeVortexValid() {
+		return f.wakeVortex, nil
+	}
+	return 0, fmt.Errorf("wake vortex is not valid")
+}
+func (f *Frame) WakeVortexValid() bool {
+	if nil == f {
+		return false
+	}
+	return f.validWakeVortex
+}
+
+// HazardLevelString turns a meteorological hazard level into something readable
+func HazardLevelString(level byte) string {
+	if int(level) < len(metHazardTable) {
+		return metHazardTable[level]
+	}
+	return ""
+}
+
 // the first character can be * or @ (or left out)
 // if the entire string is then 0's, it's a noop
 var noopRw = regexp.MustCompile("^[*@]?0+$")
 
+// rawString gives us the hex encoded message, frames decoded from bytes only build it when it is asked for
+func (f *Frame) rawString() string {
+	if "" == f.raw && nil != f.message {
+		return fmt.Sprintf("%X", f.message)
+	}
+	return f.raw
+}
+
 func (f *Frame) isNoOp() bool {
+	if nil != f.message {
+		return false
+	}
 	if "" == f.raw {
 		return true
 	}
@@ -975,6 +1578,9 @@ func (f *Frame) GetAirplaneLengthWidth() (*float32, *float32, error) {
 
 // DecodeAuIcaoRegistration takes the ICAO of an australian aircraft and can decode it into a callsign
 func (f *Frame) DecodeAuIcaoRegistration() (*string, error) {
+	if AddressTypeIcao != f.addressType {
+		return nil, errors.New("not an ICAO address")
+	}
 	start := uint32(0x7C0000)
 	end := uint32(0x7C822D)
 
@@ -0,0 +1,116 @@
+package mode_s
+
+import (
+	"fmt"
+	"time"
+)
+
+type (
+	// ModeAc is a Mode A (squawk) or Mode C (altitude) reply. Both replies are the same 12 bits so we cannot
+	// tell them apart, we decode it both ways and let the tracker figure out which one makes sense
+	ModeAc struct {
+		code      uint16
+		timeStamp time.Time
+	}
+)
+
+const modeAcSpi = 0x0080
+
+// NewModeAc takes a Mode A/C code in the 0xABCD octal nibble layout (the SPI flag is 0x0080), as beast sends it
+func NewModeAc(code uint16, t time.Time) *ModeAc {
+	return &ModeAc{
+		code:      code,
+		timeStamp: t,
+	}
+}
+
+func (m *ModeAc) Code() uint16 {
+	if nil == m {
+		return 0
+	}
+	return m.code
+}
+
+func (m *ModeAc) TimeStamp() time.Time {
+	if nil == m {
+		return time.Time{}
+	}
+	return m.timeStamp
+}
+
+// SetTimeStamp sets when we received this reply
+func (m *ModeAc) SetTimeStamp(t time.Time) {
+	if nil == m {
+		return
+	}
+	m.timeStamp = t
+}
+
+// SquawkIdentity is the Mode A interpretation of this reply, in the same format as Frame.SquawkIdentity
+func (m *ModeAc) SquawkIdentity() uint32 {
+	if nil == m {
+		return 0
+	}
+	a := uint32(m.code>>12) & 7
+	b := uint32(m.code>>8) & 7
+	c := uint32(m.code>>4) & 7
+	d := uint32(m.code) & 7
+	return a*1000 + b*100 + c*10 + d
+}
+
+// Spi is the Special Position Identification pulse (IDENT)
+func (m *ModeAc) Spi() bool {
+	if nil == m {
+		return false
+	}
+	return 0 != m.code&modeAcSpi
+}
+
+// AltitudeValid tells us if this reply can be read as a Mode C altitude
+func (m *ModeAc) AltitudeValid() bool {
+	if nil == m || m.Spi() {
+		return false
+	}
+	// D1 is never used for altitude
+	if 0 != m.code&0x8889 {
+		return false
+	}
+	// the C bits are the 100's of feet, only 1 to 5 (in gray code) are valid
+	switch m.code >> 4 & 7 {
+	case 0, 5, 7:
+		return false
+	}
+	return true
+}
+
+// Altitude is the Mode C interpretation of this reply, in feet
+func (m *ModeAc) Altitude() (int32, error) {
+	if !m.AltitudeValid() {
+		return 0, fmt.Errorf("mode A/C code %04o is not a valid altitude", m.SquawkIdentity())
+	}
+	return gillhamToAltitude(m.gillham()), nil
+}
+
+// gillham rearranges the code into the D2 D4 A1 A2 A4 B1 B2 B4 C1 C2 C4 order gillhamToAltitude wants
+func (m *ModeAc) gillham() int32 {
+	bit := func(mask uint16, shift int32) int32 {
+		if 0 != m.code&mask {
+			return 1 << shift
+		}
+		return 0
+	}
+	return bit(0x0002, 10) | bit(0x0004, 9) |
+		bit(0x1000, 8) | bit(0x2000, 7) | bit(0x4000, 6) |
+		bit(0x0100, 5) | bit(0x0200, 4) | bit(0x0400, 3) |
+		bit(0x0010, 2) | bit(0x0020, 1) | bit(0x0040, 0)
+}
+
+func (m *ModeAc) String() string {
+	if nil == m {
+		return ""
+	}
+	if alt, err := m.Altitude(); nil == err {
+		return fmt.Sprintf("Mode A/C: Squawk %04d or %d ft", m.SquawkIdentity(), alt)
+	}
+	return fmt.Sprintf("Mode A/C: Squawk %04d", m.SquawkIdentity())
+}
@@ -0,0 +1,55 @@
+package mode_s
+
+import (
+	"testing"
+	"time"
+)
+
+func TestModeAc(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          uint16
+		squawk        uint32
+		spi           bool
+		altitudeValid bool
+		altitude      int32
+	}{
+		{name: "7700", code: 0x7700, squawk: 7700},
+		{name: "1200 with ident", code: 0x1280, squawk: 1200, spi: true},
+		{name: "-1000ft", code: 0x0020, squawk: 20, altitudeValid: true, altitude: -1000},
+		{name: "0ft", code: 0x0620, squawk: 620, altitudeValid: true, altitude: 0},
+		{name: "1200ft", code: 0x0310, squawk: 310, altitudeValid: true, altitude: 1200},
+		{name: "D1 set", code: 0x0241, squawk: 241},
+		{name: "no C bits", code: 0x0200, squawk: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModeAc(tt.code, time.Now())
+			if tt.squawk != m.SquawkIdentity() {
+				t.Errorf("Incorrect squawk, expected %04d, got %04d", tt.squawk, m.SquawkIdentity())
+			}
+			if tt.spi != m.Spi() {
+				t.Errorf("Incorrect SPI, expected %t", tt.spi)
+			}
+			if tt.altitudeValid != m.AltitudeValid() {
+				t.Fatalf("Incorrect altitude validity, expected %t", tt.altitudeValid)
+			}
+			if alt, _ := m.Altitude(); tt.altitude != alt {
+				t.Errorf("Incorrect altitude, expected %d, got %d", tt.altitude, alt)
+			}
+		})
+	}
+}
+
+func TestModeAc_AltitudeMatchesModeAToModeC(t *testing.T) {
+	for code := 0; code <= 0xFFFF; code++ {
+		m := NewModeAc(uint16(code), time.Now())
+		modeC := modeAToModeC(int32(code))
+		if m.AltitudeValid() != (-9999 != modeC) {
+			t.Fatalf("%04X: altitude validity does not match modeAToModeC", code)
+		}
+		if alt, err := m.Altitude(); nil == err && modeC*100 != alt {
+			t.Fatalf("%04X: expected %d ft, got %d ft", code, modeC*100, alt)
+		}
+	}
+}
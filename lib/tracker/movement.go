@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"time"
+)
+
+// MovementKind is whether a plane took off or landed
+type MovementKind byte
+
+const (
+	MovementDeparture MovementKind = iota + 1
+	MovementArrival
+)
+
+// movementSearchRadius (metres) is how far from an airport a plane can take off or land and still be at it
+const movementSearchRadius = 5000
+
+func (mk MovementKind) String() string {
+	switch mk {
+	case MovementDeparture:
+		return "Departure"
+	case MovementArrival:
+		return "Arrival"
+	}
+	return "Unknown"
+}
+
+func (mk MovementKind) verb() string {
+	if MovementDeparture == mk {
+		return "departed"
+	}
+	return "arrived at"
+}
+
+// checkMovement works out which airport and runway a plane took off from or landed on when its flight phase
+// change is a departure or arrival, and sends a MovementEvent
+func (p *Plane) checkMovement(previous, phase FlightPhase, when time.Time) {
+	if nil == p.tracker || nil == p.tracker.airports {
+		return
+	}
+	var kind MovementKind
+	switch {
+	case previous.OnGround() && phase.Airborne():
+		kind = MovementDeparture
+	case previous.Airborne() && phase.OnGround():
+		kind = MovementArrival
+	default:
+		return
+	}
+	if !p.HasLocation() {
+		return
+	}
+
+	lat, lon := p.Lat(), p.Lon()
+	heading := -1.0
+	if p.HasHeading() {
+		heading = p.Heading()
+	}
+	airport, runway, ok := p.tracker.airports.Movement(lat, lon, heading, movementSearchRadius)
+	if !ok {
+		return
+	}
+	p.tracker.AddEvent(newMovementEvent(p, kind, airport, runway, when))
+}
@@ -0,0 +1,99 @@
+package tracker
+
+import (
+	"plane.watch/lib/airports"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMovementAirports = `ident,type,name,latitude_deg,longitude_deg,elevation_ft,iso_country,municipality,iata_code
+YPPH,large_airport,Perth International Airport,-31.9403,115.9670,67,AU,Perth,PER
+`
+const testMovementRunways = `airport_ident,length_ft,width_ft,surface,closed,le_ident,le_latitude_deg,le_longitude_deg,le_heading_degT,he_ident,he_latitude_deg,he_longitude_deg,he_heading_degT
+YPPH,11299,148,ASP,0,03,-31.9658,115.9551,22,21,-31.9374,115.9698,202
+`
+
+func TestPlane_checkMovement(t *testing.T) {
+	db, err := airports.Load(strings.NewReader(testMovementAirports), strings.NewReader(testMovementRunways))
+	if nil != err {
+		t.Fatalf("Failed to load airports: %s", err)
+	}
+	trk := NewTracker(WithAirports(db))
+	sink := &testSink{}
+	trk.AddSink(sink)
+	p := trk.GetPlane(0x7C1234)
+	now := time.Now()
+
+	steps := []struct {
+		lat, lon, heading float64
+		onGround          bool
+		update            func()
+	}{
+		{lat: -31.9650, lon: 115.9555, heading: 22, onGround: true, update: func() { p.setVelocity(10) }},
+		{lat: -31.9620, lon: 115.9571, heading: 22, onGround: true, update: func() { p.setVelocity(140) }},
+		{lat: -31.9550, lon: 115.9607, heading: 23, update: func() { p.setAltitude(300, "feet"); p.setVerticalRate(2500) }},
+		{lat: -31.8000, lon: 116.1000, heading: 45, update: func() { p.setAltitude(2000, "feet"); p.setVerticalRate(-700) }},
+		{lat: -31.9450, lon: 115.9660, heading: 201, onGround: true, update: func() { p.setVelocity(120) }},
+	}
+	for i, step := range steps {
+		when := now.Add(time.Duration(i) * 10 * time.Minute)
+		if err = p.addLatLong(step.lat, step.lon, when); nil != err {
+			t.Fatalf("Failed to add location: %s", err)
+		}
+		p.setHeading(step.heading)
+		p.setGroundStatus(step.onGround)
+		step.update()
+		p.updateFlightPhase(when)
+	}
+	trk.Stop()
+
+	var movements []*MovementEvent
+	for _, e := range sink.events {
+		if me, ok := e.(*MovementEvent); ok {
+			movements = append(movements, me)
+		}
+	}
+	if 2 != len(movements) {
+		t.Fatalf("Expected a departure and an arrival, got %d movements", len(movements))
+	}
+	expected := []struct {
+		kind   MovementKind
+		runway string
+		when   time.Time
+	}{
+		{kind: MovementDeparture, runway: "03", when: now.Add(20 * time.Minute)},
+		{kind: MovementArrival, runway: "21", when: now.Add(40 * time.Minute)},
+	}
+	for i, want := range expected {
+		got := movements[i]
+		if want.kind != got.Kind() {
+			t.Errorf("Expected movement %d to be a %s, got %s", i, want.kind, got.Kind())
+		}
+		if "YPPH" != got.Airport().Ident {
+			t.Errorf("Expected movement %d to be at YPPH, got %s", i, got.Airport().Ident)
+		}
+		if nil == got.Runway() || want.runway != got.Runway().Ident {
+			t.Errorf("Expected movement %d to use runway %s, got %v", i, want.runway, got.Runway())
+		}
+		if !want.when.Equal(got.When()) {
+			t.Errorf("Expected movement %d at %s, got %s", i, want.when, got.When())
+		}
+	}
+}
+
+func TestPlane_checkMovement_NoAirports(t *testing.T) {
+	trk := NewTracker()
+	sink := &testSink{}
+	trk.AddSink(sink)
+	p := trk.GetPlane(0x7C1234)
+	_ = p.addLatLong(-31.9550, 115.9607, time.Now())
+	p.checkMovement(FlightPhaseTakeoff, FlightPhaseClimb, time.Now())
+	trk.Stop()
+
+	for _, e := range sink.events {
+		if _, ok := e.(*MovementEvent); ok {
+			t.Error("Did not expect a movement without an airport database")
+		}
+	}
+}
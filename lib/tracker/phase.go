@@ -0,0 +1,152 @@
+package tracker
+
+import "time"
+
+// FlightPhase is what part of its flight we think an aircraft is in
+type FlightPhase byte
+
+const (
+	FlightPhaseUnknown FlightPhase = iota
+	FlightPhaseGround              // parked or taxiing
+	FlightPhaseTakeoff             // the takeoff roll
+	FlightPhaseClimb
+	FlightPhaseCruise
+	FlightPhaseDescent
+	FlightPhaseApproach
+	FlightPhaseLanding // the landing roll
+	FlightPhaseGoAround
+)
+
+const (
+	// takeoffSpeed (knots) is how fast an aircraft on the ground has to be going before it is on a takeoff or landing roll
+	takeoffSpeed = 50
+	// levelRate (feet/minute) is how fast an aircraft can climb or descend and still be level
+	levelRate = 500
+	// approachAltitude (feet) is how low a descending aircraft has to be before it is on approach
+	approachAltitude = 4000
+)
+
+var flightPhaseTable = map[FlightPhase]string{
+	FlightPhaseUnknown:  "Unknown",
+	FlightPhaseGround:   "Ground",
+	FlightPhaseTakeoff:  "Takeoff",
+	FlightPhaseClimb:    "Climb",
+	FlightPhaseCruise:   "Cruise",
+	FlightPhaseDescent:  "Descent",
+	FlightPhaseApproach: "Approach",
+	FlightPhaseLanding:  "Landing",
+	FlightPhaseGoAround: "Go Around",
+}
+
+func (fp FlightPhase) String() string {
+	if s, ok := flightPhaseTable[fp]; ok {
+		return s
+	}
+	return flightPhaseTable[FlightPhaseUnknown]
+}
+
+// Airborne tells us if this phase is in the air
+func (fp FlightPhase) Airborne() bool {
+	switch fp {
+	case FlightPhaseClimb, FlightPhaseCruise, FlightPhaseDescent, FlightPhaseApproach, FlightPhaseGoAround:
+		return true
+	}
+	return false
+}
+
+// OnGround tells us if this phase is on the ground
+func (fp FlightPhase) OnGround() bool {
+	switch fp {
+	case FlightPhaseGround, FlightPhaseTakeoff, FlightPhaseLanding:
+		return true
+	}
+	return false
+}
+
+// nextFlightPhase works out the phase an aircraft is in now that it has told us something new. altitude is in
+// feet and verticalRate in feet/minute, unknown values are nil
+func nextFlightPhase(current FlightPhase, onGround bool, groundSpeed *float64, altitude *float64, verticalRate *int) FlightPhase {
+	if onGround {
+		if nil == groundSpeed || *groundSpeed < takeoffSpeed {
+			return FlightPhaseGround
+		}
+		switch current {
+		case FlightPhaseUnknown, FlightPhaseGround, FlightPhaseTakeoff:
+			return FlightPhaseTakeoff
+		}
+		return FlightPhaseLanding
+	}
+
+	if current.OnGround() {
+		// wheels up
+		return FlightPhaseClimb
+	}
+	if nil == verticalRate {
+		return current
+	}
+
+	low := nil != altitude && *altitude <= approachAltitude
+	switch {
+	case *verticalRate >= levelRate:
+		if low && (FlightPhaseApproach == current || FlightPhaseGoAround == current) {
+			return FlightPhaseGoAround
+		}
+		return FlightPhaseClimb
+	case *verticalRate <= -levelRate:
+		if low {
+			return FlightPhaseApproach
+		}
+		return FlightPhaseDescent
+	}
+
+	// level flight, low down we are still on approach or going around until we climb or descend again
+	if low && (FlightPhaseApproach == current || FlightPhaseGoAround == current) {
+		return current
+	}
+	if nil == altitude && FlightPhaseUnknown == current {
+		return current
+	}
+	return FlightPhaseCruise
+}
+
+// FlightPhase is what part of its flight we think the aircraft is in
+func (p *Plane) FlightPhase() FlightPhase {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.flightPhase
+}
+
+// updateFlightPhase works out our flight phase and sends a FlightPhaseEvent if it has changed
+func (p *Plane) updateFlightPhase(when time.Time) bool {
+	p.rwLock.Lock()
+	var groundSpeed, altitude *float64
+	var verticalRate *int
+	if p.location.hasVelocity {
+		speed := p.location.velocity
+		groundSpeed = &speed
+	}
+	if "" != p.location.altitudeUnits {
+		feet := float64(p.location.altitude)
+		if "metres" == p.location.altitudeUnits {
+			feet *= feetPerMetre
+		}
+		altitude = &feet
+	}
+	if p.location.hasVerticalRate {
+		rate := p.location.verticalRate
+		verticalRate = &rate
+	}
+	previous := p.flightPhase
+	p.flightPhase = nextFlightPhase(previous, p.location.onGround, groundSpeed, altitude, verticalRate)
+	phase := p.flightPhase
+	p.rwLock.Unlock()
+
+	if phase == previous {
+		return false
+	}
+	if nil != p.tracker {
+		p.tracker.AddEvent(newFlightPhaseEvent(p, previous, phase, when))
+	}
+	p.checkMovement(previous, phase, when)
+	return true
+}
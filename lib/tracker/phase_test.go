@@ -0,0 +1,102 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_nextFlightPhase(t *testing.T) {
+	speed := func(knots float64) *float64 { return &knots }
+	alt := func(feet float64) *float64 { return &feet }
+	rate := func(fpm int) *int { return &fpm }
+	tests := []struct {
+		name         string
+		current      FlightPhase
+		onGround     bool
+		groundSpeed  *float64
+		altitude     *float64
+		verticalRate *int
+		want         FlightPhase
+	}{
+		{name: "Nothing known", current: FlightPhaseUnknown, want: FlightPhaseUnknown},
+		{name: "Parked", current: FlightPhaseUnknown, onGround: true, want: FlightPhaseGround},
+		{name: "Taxiing", current: FlightPhaseGround, onGround: true, groundSpeed: speed(20), want: FlightPhaseGround},
+		{name: "Takeoff roll", current: FlightPhaseGround, onGround: true, groundSpeed: speed(120), want: FlightPhaseTakeoff},
+		{name: "Wheels up", current: FlightPhaseTakeoff, groundSpeed: speed(160), altitude: alt(200), want: FlightPhaseClimb},
+		{name: "Climbing", current: FlightPhaseClimb, altitude: alt(8000), verticalRate: rate(2000), want: FlightPhaseClimb},
+		{name: "Top of climb", current: FlightPhaseClimb, altitude: alt(36000), verticalRate: rate(64), want: FlightPhaseCruise},
+		{name: "Step climb", current: FlightPhaseCruise, altitude: alt(36000), verticalRate: rate(1000), want: FlightPhaseClimb},
+		{name: "Top of descent", current: FlightPhaseCruise, altitude: alt(36000), verticalRate: rate(-1500), want: FlightPhaseDescent},
+		{name: "Descending", current: FlightPhaseDescent, altitude: alt(8000), verticalRate: rate(-1000), want: FlightPhaseDescent},
+		{name: "On approach", current: FlightPhaseDescent, altitude: alt(3000), verticalRate: rate(-800), want: FlightPhaseApproach},
+		{name: "Level on approach", current: FlightPhaseApproach, altitude: alt(2500), verticalRate: rate(0), want: FlightPhaseApproach},
+		{name: "Landing roll", current: FlightPhaseApproach, onGround: true, groundSpeed: speed(130), want: FlightPhaseLanding},
+		{name: "Still rolling", current: FlightPhaseLanding, onGround: true, groundSpeed: speed(80), want: FlightPhaseLanding},
+		{name: "Vacated the runway", current: FlightPhaseLanding, onGround: true, groundSpeed: speed(25), want: FlightPhaseGround},
+		{name: "Go around", current: FlightPhaseApproach, altitude: alt(600), verticalRate: rate(1500), want: FlightPhaseGoAround},
+		{name: "Going around", current: FlightPhaseGoAround, altitude: alt(2000), verticalRate: rate(1500), want: FlightPhaseGoAround},
+		{name: "Go around complete", current: FlightPhaseGoAround, altitude: alt(5000), verticalRate: rate(1500), want: FlightPhaseClimb},
+		{name: "No vertical rate", current: FlightPhaseCruise, altitude: alt(36000), want: FlightPhaseCruise},
+		{name: "Level with no altitude", current: FlightPhaseUnknown, verticalRate: rate(0), want: FlightPhaseUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextFlightPhase(tt.current, tt.onGround, tt.groundSpeed, tt.altitude, tt.verticalRate); got != tt.want {
+				t.Errorf("nextFlightPhase() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlane_updateFlightPhase(t *testing.T) {
+	type change struct {
+		previous, phase    FlightPhase
+		departure, arrival bool
+	}
+	trk := NewTracker()
+	sink := &testSink{}
+	trk.AddSink(sink)
+	p := trk.GetPlane(0x7C1234)
+	now := time.Now()
+
+	steps := []func(){
+		func() { p.setGroundStatus(true); p.setVelocity(10) },
+		func() { p.setVelocity(140) },
+		func() { p.setGroundStatus(false); p.setAltitude(500, "feet"); p.setVerticalRate(2500) },
+		func() { p.setAltitude(35000, "feet"); p.setVerticalRate(0) },
+		func() { p.setAltitude(2000, "feet"); p.setVerticalRate(-700) },
+		func() { p.setGroundStatus(true); p.setVelocity(120) },
+		func() { p.setVelocity(15) },
+	}
+	for i, step := range steps {
+		step()
+		p.updateFlightPhase(now.Add(time.Duration(i) * time.Minute))
+	}
+	trk.Stop()
+
+	expected := []change{
+		{previous: FlightPhaseUnknown, phase: FlightPhaseGround},
+		{previous: FlightPhaseGround, phase: FlightPhaseTakeoff},
+		{previous: FlightPhaseTakeoff, phase: FlightPhaseClimb, departure: true},
+		{previous: FlightPhaseClimb, phase: FlightPhaseCruise},
+		{previous: FlightPhaseCruise, phase: FlightPhaseApproach},
+		{previous: FlightPhaseApproach, phase: FlightPhaseLanding, arrival: true},
+		{previous: FlightPhaseLanding, phase: FlightPhaseGround},
+	}
+	var changes []change
+	for _, e := range sink.events {
+		if fe, ok := e.(*FlightPhaseEvent); ok {
+			if fe.Plane() != p {
+				t.Error("Flight phase event is for the wrong plane")
+			}
+			changes = append(changes, change{previous: fe.Previous(), phase: fe.Phase(), departure: fe.Departure(), arrival: fe.Arrival()})
+		}
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("Expected flight phase changes %v, got %v", expected, changes)
+	}
+	if FlightPhaseGround != p.FlightPhase() {
+		t.Errorf("Expected to end up on the ground, got %s", p.FlightPhase())
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"plane.watch/lib/geo"
+	"plane.watch/lib/icao"
 	"plane.watch/lib/tile_grid"
+	"plane.watch/lib/tracker/mode_s"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +15,28 @@ import (
 
 const (
 	max17Bits = 131071
+
+	metresPerNauticalMile = 1852
+	// maxLocalCprRange is half of an airborne CPR zone, past this a locally decoded position is ambiguous
+	maxLocalCprRange = 180
+	// maxLocalSurfaceCprRange is half of a surface CPR zone
+	maxLocalSurfaceCprRange = 45
+
+	// defaultMaxSpeed is mach 1 in metres/second, seems fast enough...
+	defaultMaxSpeed = 343
+	// minPlausibleSpeed (knots) stops slow and taxiing aircraft from having their positions rejected
+	minPlausibleSpeed = 100
+	// positionTolerance (metres) allows for CPR resolution and timing jitter between receivers
+	positionTolerance = 500
+
+	// defaultMaxPrediction is how far past the last position we are willing to guess where a plane is
+	defaultMaxPrediction = time.Minute
+
+	// acasRaRepeat is how long an ACAS RA has to go unheard before we send it out again
+	acasRaRepeat = time.Minute
+
+	// dataSourceExpiry is how long we keep a better data source than plain Mode S after we last heard from it
+	dataSourceExpiry = 30 * time.Second
 )
 
 type (
@@ -38,6 +63,8 @@ type (
 		distanceTravelled    float64
 		durationTravelled    float64
 		TrackFinished        bool
+		estimated            bool // we have predicted this location, the plane did not tell us
+		smoothed             bool // this location has come out of our smoothing filter
 
 		gridTileLocation string
 	}
@@ -56,13 +83,47 @@ type (
 		registration *string
 	}
 
+	// autopilot is what the crew have told the aircraft to do
+	autopilot struct {
+		selectedAltitudeMcp *int32
+		selectedAltitudeFms *int32
+		baroSetting         *float64
+		selectedHeading     *float64
+
+		// the modes the autopilot is in, from the ADS-B Target State and Status message
+		autopilotEngaged    *bool
+		vnavEngaged         *bool
+		lnavEngaged         *bool
+		altitudeHoldEngaged *bool
+		approachEngaged     *bool
+		tcasOperational     *bool
+	}
+
+	// airData is the aircraft's own view of its speed and attitude, from the Mode S Enhanced Surveillance replies
+	airData struct {
+		rollAngle            *float64
+		trueTrack            *float64
+		trackRate            *float64
+		groundSpeed          *int
+		trueAirSpeed         *int
+		magneticHeading      *float64
+		indicatedAirSpeed    *int
+		mach                 *float64
+		baroVerticalRate     *int
+		inertialVerticalRate *int
+	}
+
 	Plane struct {
 		tracker          *Tracker
 		trackedSince     time.Time
 		lastSeen         time.Time
 		icaoIdentifier   uint32
 		icao             string
+		addressType      mode_s.AddressType
+		dataSource       string
+		dataSourceSeen   time.Time
 		squawk           uint32
+		emergencyStatus  mode_s.EmergencyState // what the aircraft says, see Emergency()
 		flight           flight
 		locationHistory  []*PlaneLocation
 		location         *PlaneLocation
@@ -72,9 +133,27 @@ type (
 		recentFrameCount int
 		msgCount         uint64
 		airframe         airframe
+		autopilot        autopilot
+		airData          airData
 
 		signalLevel *float64 // RSSI dBFS
 
+		// quarantinedLocation is a position that did not agree with our track, waiting for another to confirm it
+		quarantinedLocation *PlaneLocation
+		rejectedPositions   uint64
+
+		// modeAcMatched is when a Mode A/C reply last matched our squawk, see Tracker.correlateModeAc
+		modeAcMatched time.Time
+
+		// acasRa is the last ACAS RA we sent out and acasRaSeen is when we last heard it, see setAcasRa
+		acasRa     string
+		acasRaSeen time.Time
+
+		// smoothed is our Kalman filtered track, nil unless the tracker has track smoothing turned on
+		smoothed *trackFilter
+
+		flightPhase FlightPhase
+
 		rwLock sync.RWMutex
 	}
 
@@ -179,6 +258,62 @@ func (p *Plane) setIcaoIdentifier(icaoIdentifier uint32) {
 	p.icao = fmt.Sprintf("%06X", icaoIdentifier)
 }
 
+// AddressType tells us whether IcaoIdentifier is a real ICAO address or an anonymous/TIS-B/ADS-R one
+func (p *Plane) AddressType() mode_s.AddressType {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.addressType
+}
+
+// setAddressType sets the address type, non ICAO addresses are shown with a leading ~
+func (p *Plane) setAddressType(addressType mode_s.AddressType) {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	p.addressType = addressType
+	if mode_s.AddressTypeIcao == addressType {
+		p.icao = fmt.Sprintf("%06X", p.icaoIdentifier)
+	} else {
+		p.icao = fmt.Sprintf("~%06X", p.icaoIdentifier)
+	}
+}
+
+// Country is who the plane's ICAO address was allocated to, planes without a real ICAO address do not have one
+func (p *Plane) Country() (icao.Country, bool) {
+	if mode_s.AddressTypeIcao != p.AddressType() {
+		return icao.Country{}, false
+	}
+	return icao.Lookup(p.IcaoIdentifier())
+}
+
+// IsMilitary tells us if the plane's ICAO address is in a block used by military aircraft
+func (p *Plane) IsMilitary() bool {
+	if mode_s.AddressTypeIcao != p.AddressType() {
+		return false
+	}
+	return icao.IsMilitary(p.IcaoIdentifier())
+}
+
+// DataSource is where we are getting our information about this plane from (ADS-B, ADS-R, TIS-B, Mode S, MLAT)
+func (p *Plane) DataSource() string {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.dataSource
+}
+
+// setDataSource records where our information is coming from. Plain Mode S replies come from every transponder,
+// so they only take over once we have not heard from the better source for dataSourceExpiry
+func (p *Plane) setDataSource(dataSource string, seen time.Time) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	if mode_s.DataSourceModeS == dataSource && "" != p.dataSource && seen.Sub(p.dataSourceSeen) < dataSourceExpiry {
+		return false
+	}
+	hasChanged := p.dataSource != dataSource
+	p.dataSource = dataSource
+	p.dataSourceSeen = seen
+	return hasChanged
+}
+
 // resetLocationHistory Zeros out the tracking history for this aircraft
 func (p *Plane) resetLocationHistory() {
 	p.rwLock.Lock()
@@ -336,8 +471,17 @@ func (p *Plane) setAltitude(altitude int32, altitudeUnits string) bool {
 		hasChanged = true
 	}
 	if p.location.altitudeUnits != altitudeUnits {
+		// without this the units were never stored, so events had no units and every altitude was a change
+		p.location.altitudeUnits = altitudeUnits
 		hasChanged = true
 	}
+	if nil != p.smoothed {
+		feet := float64(altitude)
+		if "metres" == altitudeUnits {
+			feet *= feetPerMetre
+		}
+		p.smoothed.updateAltitude(feet, p.lastSeen)
+	}
 	return hasChanged
 }
 
@@ -433,10 +577,44 @@ func (p *Plane) setSquawkIdentity(ident uint32) bool {
 	p.rwLock.Lock()
 	defer p.rwLock.Unlock()
 	hasChanged := p.squawk != ident
+	if hasChanged && nil != p.tracker {
+		p.tracker.indexSquawk(p, p.squawk, ident)
+	}
 	p.squawk = ident
 	return hasChanged
 }
 
+// setEmergencyStatus records the emergency/priority status the aircraft is broadcasting
+func (p *Plane) setEmergencyStatus(status mode_s.EmergencyState) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := p.emergencyStatus != status
+	p.emergencyStatus = status
+	return hasChanged
+}
+
+// Emergency is the emergency this aircraft is in. What the aircraft broadcasts wins, otherwise we go off its squawk
+func (p *Plane) Emergency() mode_s.EmergencyState {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	if p.emergencyStatus.IsEmergency() {
+		return p.emergencyStatus
+	}
+	return mode_s.EmergencyStateFromSquawk(p.squawk)
+}
+
+// HasEmergency tells us if this aircraft is in any kind of emergency
+func (p *Plane) HasEmergency() bool {
+	return p.Emergency().IsEmergency()
+}
+
+// checkEmergency sends out an EmergencyEvent if the emergency state is different to what it was before
+func (p *Plane) checkEmergency(before mode_s.EmergencyState, when time.Time) {
+	if after := p.Emergency(); after != before {
+		p.tracker.AddEvent(newEmergencyEvent(p, before, after, when))
+	}
+}
+
 // SquawkIdentity the integer version of the squawk
 func (p *Plane) SquawkIdentity() uint32 {
 	p.rwLock.RLock()
@@ -506,6 +684,313 @@ func (p *Plane) AirFrameLength() *float32 {
 	return p.airframe.length
 }
 
+// setSelectedAltitudeMcp is the altitude selected on the Mode Control Panel / Flight Control Unit
+func (p *Plane) setSelectedAltitudeMcp(altitude int32) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.autopilot.selectedAltitudeMcp || *p.autopilot.selectedAltitudeMcp != altitude
+	p.autopilot.selectedAltitudeMcp = &altitude
+	return hasChanged
+}
+
+// SelectedAltitudeMcp is the altitude (in feet) the aircraft has been told to fly to on the MCP/FCU
+func (p *Plane) SelectedAltitudeMcp() *int32 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.selectedAltitudeMcp
+}
+
+// setSelectedAltitudeFms is the altitude selected in the Flight Management System
+func (p *Plane) setSelectedAltitudeFms(altitude int32) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.autopilot.selectedAltitudeFms || *p.autopilot.selectedAltitudeFms != altitude
+	p.autopilot.selectedAltitudeFms = &altitude
+	return hasChanged
+}
+
+// SelectedAltitudeFms is the altitude (in feet) the Flight Management System is flying to
+func (p *Plane) SelectedAltitudeFms() *int32 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.selectedAltitudeFms
+}
+
+// setBaroSetting is the pressure the altimeter is set to
+func (p *Plane) setBaroSetting(millibars float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.autopilot.baroSetting || *p.autopilot.baroSetting != millibars
+	p.autopilot.baroSetting = &millibars
+	return hasChanged
+}
+
+// BaroSetting is the altimeter setting in millibars (hPa)
+func (p *Plane) BaroSetting() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.baroSetting
+}
+
+// setSelectedHeading is the heading selected on the MCP/FCU
+func (p *Plane) setSelectedHeading(degrees float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.autopilot.selectedHeading || *p.autopilot.selectedHeading != degrees
+	p.autopilot.selectedHeading = &degrees
+	return hasChanged
+}
+
+// SelectedHeading is the heading (in degrees) the aircraft has been told to fly
+func (p *Plane) SelectedHeading() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.selectedHeading
+}
+
+// setAutopilotModes records which autopilot modes are engaged
+func (p *Plane) setAutopilotModes(autopilot, vnav, lnav, altitudeHold, approach bool) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := false
+	set := func(current **bool, engaged bool) {
+		if nil == *current || **current != engaged {
+			hasChanged = true
+		}
+		*current = &engaged
+	}
+	set(&p.autopilot.autopilotEngaged, autopilot)
+	set(&p.autopilot.vnavEngaged, vnav)
+	set(&p.autopilot.lnavEngaged, lnav)
+	set(&p.autopilot.altitudeHoldEngaged, altitudeHold)
+	set(&p.autopilot.approachEngaged, approach)
+	return hasChanged
+}
+
+// AutopilotEngaged is nil if we do not know
+func (p *Plane) AutopilotEngaged() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.autopilotEngaged
+}
+
+// VnavEngaged is nil if we do not know
+func (p *Plane) VnavEngaged() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.vnavEngaged
+}
+
+// LnavEngaged is nil if we do not know
+func (p *Plane) LnavEngaged() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.lnavEngaged
+}
+
+// AltitudeHoldEngaged is nil if we do not know
+func (p *Plane) AltitudeHoldEngaged() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.altitudeHoldEngaged
+}
+
+// ApproachEngaged is nil if we do not know
+func (p *Plane) ApproachEngaged() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.approachEngaged
+}
+
+// setTcasOperational records if the aircraft's TCAS is working
+func (p *Plane) setTcasOperational(operational bool) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.autopilot.tcasOperational || *p.autopilot.tcasOperational != operational
+	p.autopilot.tcasOperational = &operational
+	return hasChanged
+}
+
+// TcasOperational is nil if we do not know
+func (p *Plane) TcasOperational() *bool {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.autopilot.tcasOperational
+}
+
+// setRollAngle sets how far the aircraft is banking
+func (p *Plane) setRollAngle(degrees float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.rollAngle || *p.airData.rollAngle != degrees
+	p.airData.rollAngle = &degrees
+	return hasChanged
+}
+
+// RollAngle in degrees, negative is left wing down
+func (p *Plane) RollAngle() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.rollAngle
+}
+
+// setTrueTrack sets the track angle the aircraft reports
+func (p *Plane) setTrueTrack(degrees float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.trueTrack || *p.airData.trueTrack != degrees
+	p.airData.trueTrack = &degrees
+	return hasChanged
+}
+
+// TrueTrack is the direction (in degrees from true north) the aircraft is travelling over the ground
+func (p *Plane) TrueTrack() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.trueTrack
+}
+
+// setTrackRate sets how fast the aircraft is turning
+func (p *Plane) setTrackRate(degreesPerSecond float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.trackRate || *p.airData.trackRate != degreesPerSecond
+	p.airData.trackRate = &degreesPerSecond
+	return hasChanged
+}
+
+// TrackRate is how fast the aircraft is turning in degrees/second
+func (p *Plane) TrackRate() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.trackRate
+}
+
+// setGroundSpeed sets the aircraft's speed over the ground, as it reports it in BDS 5,0
+func (p *Plane) setGroundSpeed(knots int) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.groundSpeed || *p.airData.groundSpeed != knots
+	p.airData.groundSpeed = &knots
+	return hasChanged
+}
+
+// GroundSpeed in knots, from the Track and turn report
+func (p *Plane) GroundSpeed() *int {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.groundSpeed
+}
+
+// setTrueAirSpeed sets the aircraft's speed through the air
+func (p *Plane) setTrueAirSpeed(knots int) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.trueAirSpeed || *p.airData.trueAirSpeed != knots
+	p.airData.trueAirSpeed = &knots
+	return hasChanged
+}
+
+// TrueAirSpeed in knots
+func (p *Plane) TrueAirSpeed() *int {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.trueAirSpeed
+}
+
+// setMagneticHeading sets the direction the nose is pointing
+func (p *Plane) setMagneticHeading(degrees float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.magneticHeading || *p.airData.magneticHeading != degrees
+	p.airData.magneticHeading = &degrees
+	return hasChanged
+}
+
+// MagneticHeading is the direction (in degrees from magnetic north) the nose of the aircraft is pointing
+func (p *Plane) MagneticHeading() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.magneticHeading
+}
+
+// setIndicatedAirSpeed sets the air speed shown on the aircraft's instruments
+func (p *Plane) setIndicatedAirSpeed(knots int) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.indicatedAirSpeed || *p.airData.indicatedAirSpeed != knots
+	p.airData.indicatedAirSpeed = &knots
+	return hasChanged
+}
+
+// IndicatedAirSpeed in knots
+func (p *Plane) IndicatedAirSpeed() *int {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.indicatedAirSpeed
+}
+
+// setMach sets the aircraft's speed as a fraction of the speed of sound
+func (p *Plane) setMach(mach float64) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.mach || *p.airData.mach != mach
+	p.airData.mach = &mach
+	return hasChanged
+}
+
+// Mach is the aircraft's speed as a fraction of the speed of sound
+func (p *Plane) Mach() *float64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.mach
+}
+
+// setBaroVerticalRate sets how fast the barometric altitude is changing
+func (p *Plane) setBaroVerticalRate(rate int) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.baroVerticalRate || *p.airData.baroVerticalRate != rate
+	p.airData.baroVerticalRate = &rate
+	return hasChanged
+}
+
+// BaroVerticalRate in feet/minute, from the Heading and speed report
+func (p *Plane) BaroVerticalRate() *int {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.baroVerticalRate
+}
+
+// setInertialVerticalRate sets how fast the aircraft's inertial systems say it is climbing or descending
+func (p *Plane) setInertialVerticalRate(rate int) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	hasChanged := nil == p.airData.inertialVerticalRate || *p.airData.inertialVerticalRate != rate
+	p.airData.inertialVerticalRate = &rate
+	return hasChanged
+}
+
+// InertialVerticalRate in feet/minute, from the Heading and speed report
+func (p *Plane) InertialVerticalRate() *int {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.airData.inertialVerticalRate
+}
+
+// setAcasRa records the RA we have heard, an RA is repeated for as long as it is active.
+// Returns true if it is a different RA, or one we have not heard for acasRaRepeat
+func (p *Plane) setAcasRa(ra string, seen time.Time) bool {
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	isNew := ra != p.acasRa || seen.Sub(p.acasRaSeen) > acasRaRepeat
+	p.acasRa = ra
+	if seen.After(p.acasRaSeen) {
+		p.acasRaSeen = seen
+	}
+	return isNew
+}
+
 // setHeading gives our plane some direction in life
 func (p *Plane) setHeading(heading float64) bool {
 	p.rwLock.Lock()
@@ -553,6 +1038,9 @@ func (p *Plane) setVelocity(velocity float64) bool {
 
 	p.location.hasVelocity = true
 	p.location.velocity = velocity
+	if nil != p.smoothed && p.location.hasHeading {
+		p.smoothed.updateVelocity(p.location.heading, velocity, p.lastSeen)
+	}
 	return hasChanged
 }
 
@@ -590,6 +1078,9 @@ func (p *Plane) setVerticalRate(rate int) bool {
 	hasChanged := p.location.hasVerticalRate != true || p.location.verticalRate != rate
 	p.location.hasVerticalRate = true
 	p.location.verticalRate = rate
+	if nil != p.smoothed {
+		p.smoothed.updateVerticalRate(float64(rate), p.lastSeen)
+	}
 	return hasChanged
 }
 
@@ -633,31 +1124,27 @@ func (p *Plane) Lon() float64 {
 	return p.location.longitude
 }
 
-func (p *Plane) decodeCprFilledRefLatLon(refLat, refLon *float64, ts time.Time) error {
-	if nil == refLat || nil == refLon {
-		// let's see if we can use a past plane location for this decode
-		// all we need for our reference lat/lon is a location within 45 nautical miles
-		for _, loc := range p.locationHistory {
-			// assume our aircraft is travelling < mach 4 and that it will not cover > 45mn in 1 minute
-			if nil != loc && loc.hasLatLon && loc.timeStamp.After(time.Now().Add(-time.Minute)) {
-				lat := loc.latitude
-				refLat = &lat
-				lon := loc.longitude
-				refLon = &lon
-				break
-			}
-		}
+// recentLocation is where we last saw the plane, if that was recent enough to be a reference for decoding CPR
+func (p *Plane) recentLocation(ts time.Time) (lat, lon float64, ok bool) {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	numHistoryItems := len(p.locationHistory)
+	if 0 == numHistoryItems {
+		return 0, 0, false
 	}
-	if nil != refLat && nil != refLon {
-		if err := p.decodeCpr(*refLat, *refLon, ts); nil != err {
-			return err
-		}
+	// all we need for our reference lat/lon is a location within 45 nautical miles
+	// assume our aircraft is travelling < mach 4 and that it will not cover > 45nm in 1 minute
+	loc := p.locationHistory[numHistoryItems-1]
+	if nil == loc || !loc.hasLatLon || loc.timeStamp.Before(ts.Add(-time.Minute)) {
+		return 0, 0, false
 	}
-	return nil
+	return loc.latitude, loc.longitude, true
 }
 
-// addLatLong Adds a Lat/Long pair to our location tracking and sets it as the current plane location
-func (p *Plane) addLatLong(lat, lon float64, ts time.Time) (warn error) {
+// addLatLong Adds a Lat/Long pair to our location tracking and sets it as the current plane location.
+// A position the plane could not have got to from its last one is quarantined instead, and is only used once
+// a second position agrees with it
+func (p *Plane) addLatLong(lat, lon float64, ts time.Time) error {
 	if lat < -95.0 || lat > 95 || lon < -180 || lon > 180 {
 		return fmt.Errorf("cannot add invalid coordinates {%0.6f, %0.6f}", lat, lon)
 	}
@@ -667,29 +1154,25 @@ func (p *Plane) addLatLong(lat, lon float64, ts time.Time) (warn error) {
 	var travelledDistance float64
 	var durationTravelled float64
 	numHistoryItems := len(p.locationHistory)
-	// determine speed?
-	if numHistoryItems > 0 && p.location.latitude != 0 && p.location.longitude != 0 {
-		referenceTime := p.locationHistory[numHistoryItems-1].timeStamp
-		if !referenceTime.IsZero() {
-			durationTravelled = float64(ts.Sub(referenceTime)) / float64(time.Second)
-			if 0.0 == durationTravelled {
-				durationTravelled = 1
-			}
-			acceptableMaxDistance := durationTravelled * 343 // mach1 in metres/second seems fast enough...
-			if acceptableMaxDistance > 50000 {
-				acceptableMaxDistance = 50000
-			}
-
-			travelledDistance = distance(lat, lon, p.location.latitude, p.location.longitude)
-
-			//log.Printf("%s travelled %0.2fm in %0.2f seconds (%s -> %s)", p.icaoStr, DistanceTravelled, durationTravelled, referenceTime.Format(time.RFC3339Nano), ts.Format(time.RFC3339Nano))
-
-			if travelledDistance > acceptableMaxDistance {
-				warn = fmt.Errorf("the distance (%0.2fm) between {%0.4f,%0.4f} and {%0.4f,%0.4f} is too great for %s to travel in %0.2f seconds. New Track", travelledDistance, lat, lon, p.location.latitude, p.location.longitude, p.icao, durationTravelled)
-				p.location.TrackFinished = true
+	if numHistoryItems > 0 && p.location.hasLatLon {
+		last := p.locationHistory[numHistoryItems-1]
+		var plausible bool
+		travelledDistance, durationTravelled, plausible = p.plausibleMove(last, lat, lon, ts)
+		if !plausible {
+			if _, _, confirmed := p.plausibleMove(p.quarantinedLocation, lat, lon, ts); !confirmed {
+				p.quarantinedLocation = &PlaneLocation{latitude: lat, longitude: lon, timeStamp: ts, hasLatLon: true}
+				p.rejectedPositions++
+				if nil != p.tracker && nil != p.tracker.stats.rejectedPositions {
+					p.tracker.stats.rejectedPositions.WithLabelValues(p.icao).Inc()
+				}
+				return fmt.Errorf("the distance (%0.2fm) between {%0.4f,%0.4f} and {%0.4f,%0.4f} is too great for %s to travel in %0.2f seconds. Quarantined", travelledDistance, lat, lon, last.latitude, last.longitude, p.icao, durationTravelled)
 			}
+			// two positions agree with each other, the plane really is over here. New Track
+			last.TrackFinished = true
+			travelledDistance, durationTravelled = 0, 0
 		}
 	}
+	p.quarantinedLocation = nil
 
 	if MaxLocationHistory > 0 && numHistoryItems >= MaxLocationHistory {
 		p.locationHistory = p.locationHistory[1:]
@@ -697,6 +1180,13 @@ func (p *Plane) addLatLong(lat, lon float64, ts time.Time) (warn error) {
 	p.location.latitude = lat
 	p.location.longitude = lon
 	p.location.hasLatLon = true
+	p.location.timeStamp = ts
+	p.location.distanceTravelled = travelledDistance
+	p.location.durationTravelled = durationTravelled
+	p.location.TrackFinished = false
+	if nil != p.smoothed {
+		p.smoothed.updatePosition(lat, lon, p.location.onGround, p.dataSource, ts)
+	}
 
 	needsLookup := true
 	if "" != p.location.gridTileLocation {
@@ -708,7 +1198,35 @@ func (p *Plane) addLatLong(lat, lon float64, ts time.Time) (warn error) {
 		p.location.gridTileLocation = tile_grid.LookupTile(lat, lon)
 	}
 	p.locationHistory = append(p.locationHistory, p.location.Copy())
-	return
+	return nil
+}
+
+// plausibleMove works out if the plane could have got from one location to lat/lon by ts. It allows for the
+// speed the plane told us it is doing (with some room to speed up) but never more than the tracker's max speed
+func (p *Plane) plausibleMove(from *PlaneLocation, lat, lon float64, ts time.Time) (metres, seconds float64, plausible bool) {
+	if nil == from || !from.hasLatLon {
+		return 0, 0, false
+	}
+	metres = geo.Distance(lat, lon, from.latitude, from.longitude)
+	if from.timeStamp.IsZero() {
+		// we do not know when the last position was, so we have nothing to check against
+		return metres, 0, true
+	}
+	seconds = math.Abs(ts.Sub(from.timeStamp).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	maxSpeed := float64(defaultMaxSpeed)
+	if nil != p.tracker && p.tracker.maxSpeed > 0 {
+		maxSpeed = p.tracker.maxSpeed
+	}
+	speed := maxSpeed
+	if p.location.hasVelocity {
+		reported := math.Max(p.location.velocity*1.5, minPlausibleSpeed) * metresPerNauticalMile / 3600
+		speed = math.Min(reported, maxSpeed)
+	}
+	return metres, seconds, metres <= speed*seconds+positionTolerance
 }
 
 func (p *Plane) GridTileLocation() string {
@@ -732,11 +1250,28 @@ func (p *Plane) setCprOddLocation(lat, lon float64, t time.Time) error {
 	return p.cprLocation.SetOddLocation(lat, lon, t)
 }
 
-// decodeCpr decodes the CPR Even and Odd frames and gets our Plane position
-func (p *Plane) decodeCpr(refLat, refLon float64, ts time.Time) error {
-	p.cprLocation.refLat = refLat
-	p.cprLocation.refLon = refLon
-	loc, err := p.cprLocation.decode(p.OnGround())
+// decodeCpr decodes our CPR frames and gets our Plane position. An even/odd pair is decoded globally, otherwise
+// our latest frame is decoded on its own against where we last saw the plane, or the receiver if it is close enough
+func (p *Plane) decodeCpr(refLat, refLon *float64, ts time.Time) error {
+	onGround := p.OnGround()
+	recentLat, recentLon, hasRecent := p.recentLocation(ts)
+
+	var loc *PlaneLocation
+	var err error
+	if p.cprLocation.canDecode() {
+		// surface positions need a reference, the receiver's location or failing that where we last saw the plane
+		p.cprLocation.refLat, p.cprLocation.refLon = 0, 0
+		if nil != refLat && nil != refLon {
+			p.cprLocation.refLat, p.cprLocation.refLon = *refLat, *refLon
+		} else if hasRecent {
+			p.cprLocation.refLat, p.cprLocation.refLon = recentLat, recentLon
+		}
+		loc, err = p.cprLocation.decode(onGround)
+	} else if hasRecent {
+		loc, err = p.cprLocation.decodeLocal(recentLat, recentLon, onGround, 0)
+	} else if nil != refLat && nil != refLon && p.tracker.receiverRange > 0 {
+		loc, err = p.cprLocation.decodeLocal(*refLat, *refLon, onGround, p.tracker.receiverRange)
+	}
 	if nil != err || loc == nil {
 		return err
 	}
@@ -745,6 +1280,61 @@ func (p *Plane) decodeCpr(refLat, refLon float64, ts time.Time) error {
 	return err
 }
 
+// SmoothedLocation is where our smoothing filter thinks the plane is, as of the last report we had from it
+func (p *Plane) SmoothedLocation() (*PlaneLocation, error) {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	if nil == p.smoothed || !p.smoothed.hasPosition {
+		return nil, fmt.Errorf("%s does not have a smoothed track", p.icao)
+	}
+
+	loc := p.location.Copy()
+	loc.latitude, loc.longitude = p.smoothed.toLatLon(p.smoothed.east.x[0], p.smoothed.north.x[0])
+	loc.timeStamp = p.smoothed.when
+	loc.smoothed = true
+	if ve, vn := p.smoothed.east.x[1], p.smoothed.north.x[1]; p.location.hasVelocity {
+		loc.velocity = math.Hypot(ve, vn) / metresPerKnot
+		loc.heading = math.Mod(math.Atan2(ve, vn)*180/math.Pi+360, 360)
+		loc.hasHeading = true
+	}
+	if p.smoothed.hasAltitude {
+		altitude := p.smoothed.altitude.x[0]
+		if "metres" == loc.altitudeUnits {
+			altitude /= feetPerMetre
+		}
+		loc.altitude = int32(math.Round(altitude))
+		if p.location.hasVerticalRate {
+			loc.verticalRate = int(math.Round(p.smoothed.altitude.x[1] * 60))
+		}
+	}
+	if !tile_grid.InGridLocation(loc.latitude, loc.longitude, loc.gridTileLocation) {
+		loc.gridTileLocation = tile_grid.LookupTile(loc.latitude, loc.longitude)
+	}
+	return loc, nil
+}
+
+// SmoothedCovariance tells us how sure our smoothing filter is of the plane's track
+func (p *Plane) SmoothedCovariance() (TrackCovariance, error) {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	if nil == p.smoothed || !p.smoothed.hasPosition {
+		return TrackCovariance{}, fmt.Errorf("%s does not have a smoothed track", p.icao)
+	}
+	return p.smoothed.covariance(), nil
+}
+
+// PublishSmoothed tells our sinks to send the smoothed track instead of what the plane told us
+func (p *Plane) PublishSmoothed() bool {
+	return nil != p.tracker && p.tracker.publishSmoothed
+}
+
+// RejectedPositions is how many positions we have thrown away for this plane because they were implausible
+func (p *Plane) RejectedPositions() uint64 {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	return p.rejectedPositions
+}
+
 // LocationHistory returns the track history of the Plane
 func (p *Plane) LocationHistory() []*PlaneLocation {
 	p.rwLock.RLock()
@@ -752,30 +1342,55 @@ func (p *Plane) LocationHistory() []*PlaneLocation {
 	return p.locationHistory
 }
 
-// Distance function returns the distance (in meters) between two points of
-//     a given longitude and latitude relatively accurately (using a spherical
-//     approximation of the Earth) through the Haversin Distance Formula for
-//     great arc distance on a sphere with accuracy for small distances
-//
-// point coordinates are supplied in degrees and converted into rad. in the func
-//
-// distance returned is METERS!!!!!!
-// http://en.wikipedia.org/wiki/Haversine_formula
-func distance(lat1, lon1, lat2, lon2 float64) float64 {
-	// convert to radians
-	// must cast radius as float to multiply later
-	var la1, lo1, la2, lo2, r float64
-	la1 = lat1 * math.Pi / 180
-	lo1 = lon1 * math.Pi / 180
-	la2 = lat2 * math.Pi / 180
-	lo2 = lon2 * math.Pi / 180
-
-	r = 6378100 // Earth radius in METERS
+// PredictLocation works out where the plane should be at a point in time, dead reckoning from its last position
+// along its track at its ground speed and vertical rate. We will not guess further ahead than the tracker's
+// max prediction window
+func (p *Plane) PredictLocation(at time.Time) (*PlaneLocation, error) {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
 
-	// calculate
-	h := hsin(la2-la1) + math.Cos(la1)*math.Cos(la2)*hsin(lo2-lo1)
+	numHistoryItems := len(p.locationHistory)
+	if 0 == numHistoryItems || !p.location.hasLatLon {
+		return nil, fmt.Errorf("%s does not have a location to predict from", p.icao)
+	}
+	if !p.location.hasHeading || !p.location.hasVelocity {
+		return nil, fmt.Errorf("%s needs a track and ground speed to predict its location", p.icao)
+	}
+	// the last position the plane gave us, p.location.timeStamp can move on without the position changing
+	last := p.locationHistory[numHistoryItems-1]
+	if last.timeStamp.IsZero() {
+		return nil, fmt.Errorf("do not know when %s was last at {%0.4f,%0.4f}", p.icao, last.latitude, last.longitude)
+	}
+	elapsed := at.Sub(last.timeStamp)
+	if elapsed < 0 {
+		return nil, fmt.Errorf("cannot predict where %s was before its last position", p.icao)
+	}
+	maxPrediction := defaultMaxPrediction
+	if nil != p.tracker && p.tracker.maxPrediction > 0 {
+		maxPrediction = p.tracker.maxPrediction
+	}
+	if elapsed > maxPrediction {
+		return nil, fmt.Errorf("%s last position is %s old, too long ago to predict from (max %s)", p.icao, elapsed, maxPrediction)
+	}
 
-	return 2 * r * math.Asin(math.Sqrt(h))
+	predicted := p.location.Copy()
+	metres := p.location.velocity * metresPerNauticalMile / 3600 * elapsed.Seconds()
+	predicted.latitude, predicted.longitude = geo.Destination(last.latitude, last.longitude, p.location.heading, metres)
+	if p.location.hasVerticalRate && !p.location.onGround {
+		climb := float64(p.location.verticalRate) * elapsed.Minutes() // feet
+		if "metres" == p.location.altitudeUnits {
+			climb /= feetPerMetre
+		}
+		predicted.altitude += int32(climb)
+	}
+	predicted.timeStamp = at
+	predicted.estimated = true
+	predicted.distanceTravelled = metres
+	predicted.durationTravelled = elapsed.Seconds()
+	if !tile_grid.InGridLocation(predicted.latitude, predicted.longitude, predicted.gridTileLocation) {
+		predicted.gridTileLocation = tile_grid.LookupTile(predicted.latitude, predicted.longitude)
+	}
+	return predicted, nil
 }
 
 // Valid let's us know if we have some data
@@ -812,6 +1427,7 @@ func (pl *PlaneLocation) Copy() *PlaneLocation {
 		longitude:         pl.longitude,
 		altitude:          pl.altitude,
 		hasVerticalRate:   pl.hasVerticalRate,
+		hasVelocity:       pl.hasVelocity,
 		verticalRate:      pl.verticalRate,
 		altitudeUnits:     pl.altitudeUnits,
 		heading:           pl.heading,
@@ -823,6 +1439,9 @@ func (pl *PlaneLocation) Copy() *PlaneLocation {
 		distanceTravelled: pl.distanceTravelled,
 		durationTravelled: pl.durationTravelled,
 		TrackFinished:     pl.TrackFinished,
+		estimated:         pl.estimated,
+		smoothed:          pl.smoothed,
+		gridTileLocation:  pl.gridTileLocation,
 	}
 }
 
@@ -839,3 +1458,66 @@ func (pl *PlaneLocation) Lon() float64 {
 	defer pl.rwlock.RUnlock()
 	return pl.longitude
 }
+
+// Altitude returns the Locations altitude, in AltitudeUnits
+func (pl *PlaneLocation) Altitude() int32 {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.altitude
+}
+
+// AltitudeUnits returns the units of the Locations altitude
+func (pl *PlaneLocation) AltitudeUnits() string {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.altitudeUnits
+}
+
+// Heading returns the Locations track
+func (pl *PlaneLocation) Heading() float64 {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.heading
+}
+
+// Velocity returns the Locations ground speed in knots
+func (pl *PlaneLocation) Velocity() float64 {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.velocity
+}
+
+// TimeStamp returns when the plane was at this Location
+func (pl *PlaneLocation) TimeStamp() time.Time {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.timeStamp
+}
+
+// GridTileLocation returns the grid tile this Location is in
+func (pl *PlaneLocation) GridTileLocation() string {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.gridTileLocation
+}
+
+// Estimated tells us if this Location is a prediction rather than something the plane told us
+func (pl *PlaneLocation) Estimated() bool {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.estimated
+}
+
+// Smoothed tells us if this Location came out of our smoothing filter
+func (pl *PlaneLocation) Smoothed() bool {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.smoothed
+}
+
+// VerticalRate returns the Locations vertical rate in feet/minute
+func (pl *PlaneLocation) VerticalRate() int {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return pl.verticalRate
+}
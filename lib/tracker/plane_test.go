@@ -2,6 +2,10 @@ package tracker
 
 import (
 	"fmt"
+	"math"
+	"plane.watch/lib/geo"
+	"plane.watch/lib/tracker/mode_s"
+	"strings"
 	"testing"
 	"time"
 )
@@ -494,3 +498,277 @@ func Test_headingInfo_getCompassLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestPlane_addLatLongPlausibility(t *testing.T) {
+	type position struct {
+		lat, lon float64
+		after    time.Duration
+		wantErr  bool
+	}
+	tests := []struct {
+		name      string
+		opts      []Option
+		velocity  float64
+		positions []position
+		rejected  uint64
+		history   int
+	}{
+		{
+			name: "Normal Flight",
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -31.91, lon: 115.91, after: 10 * time.Second},
+				{lat: -31.92, lon: 115.92, after: 20 * time.Second},
+			},
+			history: 3,
+		},
+		{
+			name: "Teleport",
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -33.9, lon: 117.9, after: time.Second, wantErr: true},
+				{lat: -31.91, lon: 115.91, after: 10 * time.Second},
+			},
+			rejected: 1,
+			history:  2,
+		},
+		{
+			name: "Confirmed Jump",
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -33.9, lon: 117.9, after: time.Second, wantErr: true},
+				{lat: -33.9, lon: 117.91, after: 5 * time.Second},
+			},
+			rejected: 1,
+			history:  2,
+		},
+		{
+			name: "Unconfirmed Jumps",
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -33.9, lon: 117.9, after: time.Second, wantErr: true},
+				{lat: -29.9, lon: 113.9, after: 2 * time.Second, wantErr: true},
+			},
+			rejected: 2,
+			history:  1,
+		},
+		{
+			name:     "Faster Than Reported Speed",
+			velocity: 200,
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -31.9, lon: 115.91, after: 2 * time.Second, wantErr: true},
+			},
+			rejected: 1,
+			history:  1,
+		},
+		{
+			name: "Faster Than Max Speed",
+			opts: []Option{WithMaxSpeed(100)},
+			positions: []position{
+				{lat: -31.9, lon: 115.9},
+				{lat: -31.9, lon: 115.91, after: 2 * time.Second, wantErr: true},
+			},
+			rejected: 1,
+			history:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			trk := NewTracker(tt.opts...)
+			p := trk.GetPlane(0x7C1234)
+			if 0 != tt.velocity {
+				p.setVelocity(tt.velocity)
+			}
+			for i, pos := range tt.positions {
+				err := p.addLatLong(pos.lat, pos.lon, now.Add(pos.after))
+				if pos.wantErr != (nil != err) {
+					t.Errorf("Position %d: addLatLong() error = %v, wantErr %t", i, err, pos.wantErr)
+				}
+			}
+			if tt.rejected != p.RejectedPositions() {
+				t.Errorf("Expected %d rejected positions, got %d", tt.rejected, p.RejectedPositions())
+			}
+			if tt.history != len(p.LocationHistory()) {
+				t.Errorf("Expected %d locations in our history, got %d", tt.history, len(p.LocationHistory()))
+			}
+		})
+	}
+}
+
+func TestPlane_PredictLocation(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		opts     []Option
+		noTrack  bool
+		at       time.Duration
+		wantErr  bool
+		metres   float64
+		altitude int32
+	}{
+		{name: "Now", at: 0, metres: 0, altitude: 10000},
+		{name: "30 Seconds", at: 30 * time.Second, metres: 5556, altitude: 10500},
+		{name: "Too Far Ahead", at: 2 * time.Minute, wantErr: true},
+		{name: "Shorter Window", opts: []Option{WithMaxPrediction(10 * time.Second)}, at: 30 * time.Second, wantErr: true},
+		{name: "Before Last Position", at: -time.Second, wantErr: true},
+		{name: "No Track", noTrack: true, at: 10 * time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewTracker(tt.opts...).GetPlane(0x7C1234)
+			p.setAltitude(10000, "feet")
+			p.setVerticalRate(1000)
+			if !tt.noTrack {
+				p.setHeading(90)
+				p.setVelocity(360)
+			}
+			if err := p.addLatLong(-31.9, 115.9, now); nil != err {
+				t.Fatal(err)
+			}
+
+			loc, err := p.PredictLocation(now.Add(tt.at))
+			if tt.wantErr {
+				if nil == err {
+					t.Errorf("Expected an error, got %0.4f,%0.4f", loc.Lat(), loc.Lon())
+				}
+				return
+			}
+			if nil != err {
+				t.Fatal(err)
+			}
+			if !loc.Estimated() {
+				t.Error("Predicted locations should be estimated")
+			}
+			if d := geo.Distance(-31.9, 115.9, loc.Lat(), loc.Lon()); math.Abs(d-tt.metres) > 1 {
+				t.Errorf("Expected to travel %0.0fm, went %0.2fm", tt.metres, d)
+			}
+			if tt.metres > 0 && math.Abs(loc.Lat()+31.9) > 0.001 {
+				t.Errorf("Heading east should not change our latitude, got %0.6f", loc.Lat())
+			}
+			if tt.altitude != loc.Altitude() {
+				t.Errorf("Expected altitude %d, got %d", tt.altitude, loc.Altitude())
+			}
+			if !now.Add(tt.at).Equal(loc.TimeStamp()) {
+				t.Errorf("Predicted location has the wrong time %s", loc.TimeStamp())
+			}
+			if "-31.900000,+115.900000" != fmt.Sprintf("%+0.6f,%+0.6f", p.Lat(), p.Lon()) {
+				t.Errorf("Predicting should not move the plane, it is at %0.6f,%0.6f", p.Lat(), p.Lon())
+			}
+		})
+	}
+}
+
+func TestPlane_Country(t *testing.T) {
+	tests := []struct {
+		name        string
+		icao        uint32
+		addressType mode_s.AddressType
+		country     string
+		military    bool
+	}{
+		{name: "Australian airliner", icao: 0x7C1BE8, addressType: mode_s.AddressTypeIcao, country: "Australia"},
+		{name: "RAAF", icao: 0x7CF86B, addressType: mode_s.AddressTypeIcao, country: "Australia", military: true},
+		{name: "Non ICAO address", icao: 0x7CF86B, addressType: mode_s.AddressTypeAdsbOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlane(tt.icao)
+			p.setAddressType(tt.addressType)
+			country, ok := p.Country()
+			if ok != ("" != tt.country) || country.Name != tt.country {
+				t.Errorf("Country() = %s, %t, want %s", country.Name, ok, tt.country)
+			}
+			if tt.military != p.IsMilitary() {
+				t.Errorf("IsMilitary() = %t, want %t", p.IsMilitary(), tt.military)
+			}
+		})
+	}
+}
+
+func TestPlane_setAltitudeEvents(t *testing.T) {
+	trk := NewTracker()
+	sink := &testSink{}
+	trk.AddSink(sink)
+
+	frame, err := mode_s.DecodeString("8D40621D58C382D690C8AC2863A7", time.Now())
+	if nil != err {
+		t.Fatal(err)
+	}
+	p := trk.GetPlane(frame.Icao())
+	p.HandleModeSFrame(frame, nil, nil)
+	// with the units remembered, the same altitude is no longer a change that sends out another event
+	if p.setAltitude(38000, "feet") {
+		t.Error("Expected the same altitude in the same units to not be a change")
+	}
+	if !p.setAltitude(38000, "metres") {
+		t.Error("Expected a change of units to be a change")
+	}
+	p.setAltitude(38000, "feet")
+	trk.Stop()
+
+	if 0 == len(sink.events) {
+		t.Fatal("Expected a location event")
+	}
+	e, ok := sink.events[0].(*PlaneLocationEvent)
+	if !ok || "feet" != e.Plane().AltitudeUnits() || !strings.Contains(e.String(), "38000 feet") {
+		t.Errorf("Expected the location event to be in feet, got %s", sink.events[0])
+	}
+}
+
+func TestPlane_setAcasRa(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		ra   string
+		seen time.Time
+		want bool
+	}{
+		{name: "first RA", ra: "climb", seen: now, want: true},
+		{name: "repeated", ra: "climb", seen: now.Add(time.Second), want: false},
+		{name: "still active", ra: "climb", seen: now.Add(50 * time.Second), want: false},
+		{name: "changed", ra: "descend", seen: now.Add(51 * time.Second), want: true},
+		{name: "out of order", ra: "descend", seen: now.Add(40 * time.Second), want: false},
+		{name: "heard again after going quiet", ra: "descend", seen: now.Add(51*time.Second + acasRaRepeat + time.Second), want: true},
+	}
+	p := NewTracker().GetPlane(0x123456)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.setAcasRa(tt.ra, tt.seen); got != tt.want {
+				t.Errorf("setAcasRa(%q) = %t, want %t", tt.ra, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlane_setDataSource(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		dataSource string
+		seen       time.Time
+		want       string
+		changed    bool
+	}{
+		{name: "first heard", dataSource: mode_s.DataSourceModeS, seen: now, want: mode_s.DataSourceModeS, changed: true},
+		{name: "ADS-B replaces Mode S", dataSource: mode_s.DataSourceAdsb, seen: now.Add(time.Second), want: mode_s.DataSourceAdsb, changed: true},
+		{name: "Mode S while ADS-B is current", dataSource: mode_s.DataSourceModeS, seen: now.Add(2 * time.Second), want: mode_s.DataSourceAdsb},
+		{name: "ADS-B again", dataSource: mode_s.DataSourceAdsb, seen: now.Add(20 * time.Second), want: mode_s.DataSourceAdsb},
+		{name: "Mode S before ADS-B expires", dataSource: mode_s.DataSourceModeS, seen: now.Add(20*time.Second + dataSourceExpiry - time.Second), want: mode_s.DataSourceAdsb},
+		{name: "Mode S once ADS-B expires", dataSource: mode_s.DataSourceModeS, seen: now.Add(20*time.Second + dataSourceExpiry), want: mode_s.DataSourceModeS, changed: true},
+		{name: "MLAT replaces Mode S", dataSource: mode_s.DataSourceMlat, seen: now.Add(time.Minute), want: mode_s.DataSourceMlat, changed: true},
+		{name: "most recent source wins", dataSource: mode_s.DataSourceAdsb, seen: now.Add(time.Minute + time.Second), want: mode_s.DataSourceAdsb, changed: true},
+	}
+	p := NewTracker().GetPlane(0x123456)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := p.setDataSource(tt.dataSource, tt.seen); changed != tt.changed {
+				t.Errorf("setDataSource(%s) = %t, want %t", tt.dataSource, changed, tt.changed)
+			}
+			if tt.want != p.DataSource() {
+				t.Errorf("Expected data source %s, got %s", tt.want, p.DataSource())
+			}
+		})
+	}
+}
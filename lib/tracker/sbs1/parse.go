@@ -59,6 +59,20 @@ func (f *Frame) TimeStamp() time.Time {
 	return f.Received
 }
 
+// GeneratedTime is when the message was generated, without having to Parse the whole frame
+func (f *Frame) GeneratedTime() (time.Time, error) {
+	bits := strings.Split(f.original, ",")
+	if len(bits) != 22 {
+		return time.Time{}, fmt.Errorf("Failed to Parse Input - not enough parameters: %s", f.original)
+	}
+	return parseGeneratedTime(bits)
+}
+
+func parseGeneratedTime(bits []string) (time.Time, error) {
+	//2016/06/03 00:00:38.350
+	return time.Parse("2006/01/02 15:04:05.999999999", bits[sbsRecvDate]+" "+bits[sbsRecvTime])
+}
+
 func (f *Frame) Parse() error {
 	// decode the string
 	var err error
@@ -73,9 +87,7 @@ func (f *Frame) Parse() error {
 	if nil != err {
 		return err
 	}
-	sTime := bits[sbsRecvDate] + " " + bits[sbsRecvTime]
-	//2016/06/03 00:00:38.350
-	f.Received, err = time.Parse("2006/01/02 15:04:05.999999999", sTime)
+	f.Received, err = parseGeneratedTime(bits)
 	if nil != err {
 		f.Received = time.Now()
 	}
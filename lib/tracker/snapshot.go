@@ -0,0 +1,302 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"plane.watch/lib/tracker/mode_s"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the snapshot format changes in a way older snapshots cannot be read
+const snapshotVersion = 1
+
+type (
+	// trackerSnapshot is what we write out so that a restarted tracker can carry on where we left off
+	trackerSnapshot struct {
+		Version int
+		Taken   time.Time
+		Planes  []planeSnapshot
+	}
+
+	planeSnapshot struct {
+		Icao            uint32
+		AddressType     mode_s.AddressType
+		DataSource      string `json:",omitempty"`
+		TrackedSince    time.Time
+		LastSeen        time.Time
+		MsgCount        uint64
+		Squawk          uint32
+		EmergencyStatus mode_s.EmergencyState `json:",omitempty"`
+		FlightPhase     FlightPhase           `json:",omitempty"`
+
+		Flight struct {
+			Identifier string `json:",omitempty"`
+			Status     string `json:",omitempty"`
+			StatusId   byte   `json:",omitempty"`
+		}
+		AirFrame struct {
+			Category     string   `json:",omitempty"`
+			CategoryType string   `json:",omitempty"`
+			Width        *float32 `json:",omitempty"`
+			Length       *float32 `json:",omitempty"`
+			Registration *string  `json:",omitempty"`
+		}
+
+		Location        locationSnapshot
+		LocationHistory []locationSnapshot `json:",omitempty"`
+
+		// the half of a CPR pair we were waiting on the other half for
+		CprEven *cprSnapshot `json:",omitempty"`
+		CprOdd  *cprSnapshot `json:",omitempty"`
+	}
+
+	locationSnapshot struct {
+		Lat, Lon          float64
+		HasLatLon         bool    `json:",omitempty"`
+		Altitude          int32   `json:",omitempty"`
+		AltitudeUnits     string  `json:",omitempty"`
+		Heading           float64 `json:",omitempty"`
+		HasHeading        bool    `json:",omitempty"`
+		Velocity          float64 `json:",omitempty"`
+		HasVelocity       bool    `json:",omitempty"`
+		VerticalRate      int     `json:",omitempty"`
+		HasVerticalRate   bool    `json:",omitempty"`
+		OnGround          bool    `json:",omitempty"`
+		TimeStamp         time.Time
+		DistanceTravelled float64 `json:",omitempty"`
+		DurationTravelled float64 `json:",omitempty"`
+		TrackFinished     bool    `json:",omitempty"`
+		GridTileLocation  string  `json:",omitempty"`
+	}
+
+	cprSnapshot struct {
+		Lat, Lon float64
+		When     time.Time
+	}
+)
+
+// Snapshot writes out every plane we are tracking so that Restore can pick up where we left off
+func (t *Tracker) Snapshot(w io.Writer) error {
+	snap := trackerSnapshot{
+		Version: snapshotVersion,
+		Taken:   time.Now(),
+		Planes:  make([]planeSnapshot, 0),
+	}
+	t.EachPlane(func(p *Plane) bool {
+		snap.Planes = append(snap.Planes, p.snapshot())
+		return true
+	})
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// Restore loads the planes from a Snapshot. Planes we have not heard from in longer than our prune time are
+// dropped, planes we are already tracking are left alone. Each restored plane is sent to our sinks, so add them first
+func (t *Tracker) Restore(r io.Reader) error {
+	var snap trackerSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); nil != err {
+		return err
+	}
+	if snapshotVersion != snap.Version {
+		return fmt.Errorf("unable to restore snapshot version %d, expected version %d", snap.Version, snapshotVersion)
+	}
+
+	oldest := time.Now().Add(-t.pruneAfter)
+	restored := 0
+	for _, ps := range snap.Planes {
+		if ps.LastSeen.Before(oldest) {
+			continue
+		}
+		key := planeKey(ps.Icao, ps.AddressType)
+		if _, ok := t.planeList.Load(key); ok {
+			continue
+		}
+		p := newPlane(ps.Icao)
+		p.tracker = t
+		if t.smoothTracks {
+			p.smoothed = &trackFilter{}
+		}
+		p.restore(ps)
+		t.indexSquawk(p, 0, p.SquawkIdentity())
+		t.planeList.Store(key, p)
+		if nil != t.stats.currentPlanes {
+			t.stats.currentPlanes.Inc()
+		}
+		t.AddEvent(newPlaneLocationEvent(p))
+		restored++
+	}
+	t.infoMessage("Restored %d of %d planes from a snapshot taken %s", restored, len(snap.Planes), snap.Taken.Format(time.RFC3339))
+	return nil
+}
+
+// snapshotFile writes our snapshot to the file configured with WithSnapshotFile.
+// We write to a temp file and move it into place so a crash part way through does not leave us a broken snapshot
+func (t *Tracker) snapshotFile() error {
+	f, err := os.CreateTemp(filepath.Dir(t.snapshotPath), filepath.Base(t.snapshotPath)+".*.tmp")
+	if nil != err {
+		return err
+	}
+	if err = t.Snapshot(f); nil != err {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = f.Close(); nil != err {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err = os.Rename(f.Name(), t.snapshotPath); nil != err {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// RestoreFile restores from the file configured with WithSnapshotFile, not having one is not a problem.
+// Call it once the sinks are added so that they hear about the restored planes
+func (t *Tracker) RestoreFile() error {
+	if "" == t.snapshotPath {
+		return nil
+	}
+	f, err := os.Open(t.snapshotPath)
+	if nil != err {
+		if errors.Is(err, os.ErrNotExist) {
+			t.infoMessage("No snapshot to restore from at %s", t.snapshotPath)
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return t.Restore(f)
+}
+
+// snapshot gives us everything we need to know to carry on tracking this plane
+func (p *Plane) snapshot() planeSnapshot {
+	p.rwLock.RLock()
+	defer p.rwLock.RUnlock()
+	ps := planeSnapshot{
+		Icao:            p.icaoIdentifier,
+		AddressType:     p.addressType,
+		DataSource:      p.dataSource,
+		TrackedSince:    p.trackedSince,
+		LastSeen:        p.lastSeen,
+		MsgCount:        p.msgCount,
+		Squawk:          p.squawk,
+		EmergencyStatus: p.emergencyStatus,
+		FlightPhase:     p.flightPhase,
+		Location:        p.location.snapshot(),
+	}
+	ps.Flight.Identifier = p.flight.identifier
+	ps.Flight.Status = p.flight.status
+	ps.Flight.StatusId = p.flight.statusId
+	ps.AirFrame.Category = p.airframe.category
+	ps.AirFrame.CategoryType = p.airframe.categoryType
+	ps.AirFrame.Width = p.airframe.width
+	ps.AirFrame.Length = p.airframe.length
+	ps.AirFrame.Registration = p.airframe.registration
+
+	ps.LocationHistory = make([]locationSnapshot, len(p.locationHistory))
+	for i, loc := range p.locationHistory {
+		ps.LocationHistory[i] = loc.snapshot()
+	}
+
+	p.cprLocation.rwLock.RLock()
+	if p.cprLocation.evenFrame {
+		ps.CprEven = &cprSnapshot{Lat: p.cprLocation.evenLat, Lon: p.cprLocation.evenLon, When: p.cprLocation.time0}
+	}
+	if p.cprLocation.oddFrame {
+		ps.CprOdd = &cprSnapshot{Lat: p.cprLocation.oddLat, Lon: p.cprLocation.oddLon, When: p.cprLocation.time1}
+	}
+	p.cprLocation.rwLock.RUnlock()
+	return ps
+}
+
+// restore puts a plane back the way it was when we took its snapshot
+func (p *Plane) restore(ps planeSnapshot) {
+	p.setAddressType(ps.AddressType)
+	if nil != ps.CprEven {
+		_ = p.setCprEvenLocation(ps.CprEven.Lat, ps.CprEven.Lon, ps.CprEven.When)
+	}
+	if nil != ps.CprOdd {
+		_ = p.setCprOddLocation(ps.CprOdd.Lat, ps.CprOdd.Lon, ps.CprOdd.When)
+	}
+
+	p.rwLock.Lock()
+	defer p.rwLock.Unlock()
+	p.dataSource = ps.DataSource
+	p.dataSourceSeen = ps.LastSeen
+	p.trackedSince = ps.TrackedSince
+	p.lastSeen = ps.LastSeen
+	p.msgCount = ps.MsgCount
+	p.squawk = ps.Squawk
+	p.emergencyStatus = ps.EmergencyStatus
+	p.flightPhase = ps.FlightPhase
+	p.flight.identifier = ps.Flight.Identifier
+	p.flight.status = ps.Flight.Status
+	p.flight.statusId = ps.Flight.StatusId
+	p.airframe.category = ps.AirFrame.Category
+	p.airframe.categoryType = ps.AirFrame.CategoryType
+	p.airframe.width = ps.AirFrame.Width
+	p.airframe.length = ps.AirFrame.Length
+	p.airframe.registration = ps.AirFrame.Registration
+
+	p.location = ps.Location.location()
+	p.locationHistory = make([]*PlaneLocation, 0, len(ps.LocationHistory))
+	for _, ls := range ps.LocationHistory {
+		p.locationHistory = append(p.locationHistory, ls.location())
+	}
+	if nil != p.smoothed && p.location.hasLatLon {
+		p.smoothed.updatePosition(p.location.latitude, p.location.longitude, p.location.onGround, p.dataSource, p.location.timeStamp)
+	}
+}
+
+func (pl *PlaneLocation) snapshot() locationSnapshot {
+	pl.rwlock.RLock()
+	defer pl.rwlock.RUnlock()
+	return locationSnapshot{
+		Lat:               pl.latitude,
+		Lon:               pl.longitude,
+		HasLatLon:         pl.hasLatLon,
+		Altitude:          pl.altitude,
+		AltitudeUnits:     pl.altitudeUnits,
+		Heading:           pl.heading,
+		HasHeading:        pl.hasHeading,
+		Velocity:          pl.velocity,
+		HasVelocity:       pl.hasVelocity,
+		VerticalRate:      pl.verticalRate,
+		HasVerticalRate:   pl.hasVerticalRate,
+		OnGround:          pl.onGround,
+		TimeStamp:         pl.timeStamp,
+		DistanceTravelled: pl.distanceTravelled,
+		DurationTravelled: pl.durationTravelled,
+		TrackFinished:     pl.TrackFinished,
+		GridTileLocation:  pl.gridTileLocation,
+	}
+}
+
+func (ls locationSnapshot) location() *PlaneLocation {
+	return &PlaneLocation{
+		latitude:          ls.Lat,
+		longitude:         ls.Lon,
+		hasLatLon:         ls.HasLatLon,
+		altitude:          ls.Altitude,
+		altitudeUnits:     ls.AltitudeUnits,
+		heading:           ls.Heading,
+		hasHeading:        ls.HasHeading,
+		velocity:          ls.Velocity,
+		hasVelocity:       ls.HasVelocity,
+		verticalRate:      ls.VerticalRate,
+		hasVerticalRate:   ls.HasVerticalRate,
+		onGround:          ls.OnGround,
+		timeStamp:         ls.TimeStamp,
+		distanceTravelled: ls.DistanceTravelled,
+		durationTravelled: ls.DurationTravelled,
+		TrackFinished:     ls.TrackFinished,
+		gridTileLocation:  ls.GridTileLocation,
+	}
+}
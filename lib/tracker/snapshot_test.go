@@ -0,0 +1,141 @@
+package tracker
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"plane.watch/lib/tracker/mode_s"
+	"testing"
+	"time"
+)
+
+func TestTracker_SnapshotRestore(t *testing.T) {
+	now := time.Now()
+	trk := NewTracker()
+
+	p := trk.GetPlane(0x7C1234)
+	p.setLastSeen(now)
+	p.setFlightNumber("QFA123")
+	p.setSquawkIdentity(0x1234)
+	p.setAirFrameCategory("A3")
+	p.setAltitude(10000, "feet")
+	p.setHeading(90)
+	p.setVelocity(360)
+	if err := p.addLatLong(-31.9, 115.9, now.Add(-10*time.Second)); nil != err {
+		t.Fatal(err)
+	}
+	if err := p.addLatLong(-31.9, 115.92, now); nil != err {
+		t.Fatal(err)
+	}
+	if err := p.setCprEvenLocation(92095, 39846, now); nil != err {
+		t.Fatal(err)
+	}
+
+	anon := trk.GetPlaneByAddress(0x7C1234, mode_s.AddressTypeAdsbOther)
+	anon.setLastSeen(now)
+
+	stale := trk.GetPlane(0x7CFFFF)
+	stale.setLastSeen(now.Add(-time.Hour))
+	stale.setFlightNumber("STALE")
+
+	var buf bytes.Buffer
+	if err := trk.Snapshot(&buf); nil != err {
+		t.Fatal(err)
+	}
+
+	restored := NewTracker()
+	if err := restored.Restore(&buf); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != restored.numPlanes() {
+		t.Errorf("Expected 2 planes to be restored (the stale one dropped), got %d", restored.numPlanes())
+	}
+	if _, ok := restored.findPlane(0x7CFFFF); ok {
+		t.Error("Should not have restored a stale plane")
+	}
+
+	rp, ok := restored.findPlane(0x7C1234)
+	if !ok {
+		t.Fatal("Did not restore our plane")
+	}
+	if "QFA123" != rp.FlightNumber() {
+		t.Errorf("Expected flight QFA123, got %s", rp.FlightNumber())
+	}
+	if 0x1234 != rp.SquawkIdentity() {
+		t.Errorf("Expected squawk 1234, got %04X", rp.SquawkIdentity())
+	}
+	if "A3" != rp.AirFrame() {
+		t.Errorf("Expected airframe A3, got %s", rp.AirFrame())
+	}
+	if !rp.LastSeen().Equal(now) {
+		t.Errorf("Expected last seen %s, got %s", now, rp.LastSeen())
+	}
+	if !rp.HasLocation() || -31.9 != rp.Lat() || 115.92 != rp.Lon() || 10000 != rp.Altitude() || 90 != rp.Heading() {
+		t.Errorf("Location was not restored, got {%0.4f,%0.4f} at %d heading %0.0f", rp.Lat(), rp.Lon(), rp.Altitude(), rp.Heading())
+	}
+	if 2 != len(rp.LocationHistory()) {
+		t.Errorf("Expected 2 location history items, got %d", len(rp.LocationHistory()))
+	}
+	if !rp.cprLocation.evenFrame || rp.cprLocation.oddFrame || 92095 != rp.cprLocation.evenLat {
+		t.Error("Expected our even CPR frame to be restored")
+	}
+
+	ra, ok := restored.planeList.Load(planeKey(0x7C1234, mode_s.AddressTypeAdsbOther))
+	if !ok {
+		t.Fatal("Did not restore our non ICAO plane")
+	}
+	if "~7C1234" != ra.(*Plane).IcaoIdentifierStr() {
+		t.Errorf("Expected a non ICAO address, got %s", ra.(*Plane).IcaoIdentifierStr())
+	}
+}
+
+func TestTracker_RestoreBadVersion(t *testing.T) {
+	trk := NewTracker()
+	if err := trk.Restore(bytes.NewBufferString(`{"Version":999,"Planes":[]}`)); nil == err {
+		t.Error("Should not restore a snapshot version we do not know")
+	}
+}
+
+func TestTracker_SnapshotFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	trk := NewTracker(WithSnapshotFile(file))
+	p := trk.GetPlane(0x7C1234)
+	p.setLastSeen(time.Now())
+	p.setFlightNumber("QFA123")
+	trk.Stop()
+
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(entries) || "snapshot.json" != entries[0].Name() {
+		t.Errorf("Expected only our snapshot file, the temp file should have been moved into place. got %v", entries)
+	}
+
+	restored := NewTracker(WithSnapshotFile(file))
+	sink := &testSink{}
+	restored.AddSink(sink)
+	if err = restored.RestoreFile(); nil != err {
+		t.Fatal(err)
+	}
+	restored.Stop()
+	if 1 != restored.numPlanes() {
+		t.Fatalf("Expected 1 plane to be restored, got %d", restored.numPlanes())
+	}
+
+	announced := false
+	for _, e := range sink.events {
+		if le, ok := e.(*PlaneLocationEvent); ok && "QFA123" == le.Plane().FlightNumber() {
+			announced = true
+		}
+	}
+	if !announced {
+		t.Error("Expected the restored plane to be sent to our sinks")
+	}
+
+	missing := NewTracker(WithSnapshotFile(filepath.Join(t.TempDir(), "missing.json")))
+	if err = missing.RestoreFile(); nil != err {
+		t.Errorf("Not having a snapshot to restore from should not be an error: %s", err)
+	}
+	missing.Stop()
+}
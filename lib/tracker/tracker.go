@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
+	"plane.watch/lib/airports"
+	"plane.watch/lib/geofence"
 	"plane.watch/lib/tracker/mode_s"
 	"plane.watch/lib/tracker/sbs1"
+	"plane.watch/lib/tracker/uat"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -32,6 +35,30 @@ type (
 		decodingQueue       chan *FrameEvent
 		decodingQueueWaiter sync.WaitGroup
 
+		// how we decide where our planes are
+		receiverRange float64 // metres, how far from the receiver we will locally decode CPR positions
+		maxSpeed      float64 // metres/second, anything faster than this is an implausible position
+		maxPrediction time.Duration
+
+		// errorCorrection is how many bit errors we fix in Mode S frames
+		errorCorrection mode_s.ErrorCorrection
+
+		smoothTracks, publishSmoothed bool
+
+		// snapshotPath is where we save our planes on Finish() and load them from in RestoreFile()
+		snapshotPath string
+
+		// squawks indexes our planes by squawk and modeAPlanes are the planes with a Mode A match (see correlateModeAc),
+		// so a Mode A/C reply does not have to look through every plane we are tracking
+		squawkLock  sync.RWMutex
+		squawks     map[uint32]map[*Plane]bool
+		modeAPlanes sync.Map
+
+		// airports lets us work out where planes take off from and land at
+		airports *airports.Database
+		// geofences sends events when planes go in and out of areas we care about
+		geofences *geofence.Engine
+
 		eventSync    sync.RWMutex
 		eventsOpen   bool
 		finishDone   bool
@@ -44,7 +71,11 @@ type (
 		numFrames uint64
 
 		stats struct {
-			currentPlanes prometheus.Gauge
+			currentPlanes   prometheus.Gauge
+			correctedFrames prometheus.Counter
+			rejectedFrames  prometheus.Counter
+
+			rejectedPositions *prometheus.CounterVec
 		}
 	}
 )
@@ -61,6 +92,7 @@ func NewTracker(opts ...Option) *Tracker {
 		events:            make(chan Event, 10000),
 		eventsOpen:        true,
 		pruneExitChan:     make(chan bool),
+		squawks:           map[uint32]map[*Plane]bool{},
 
 		startTime: time.Now(),
 	}
@@ -103,22 +135,47 @@ func (t *Tracker) numPlanes() int {
 	return count
 }
 
+// planeKey gives us the key a plane is stored under, non ICAO addresses can clash with real ICAO addresses
+func planeKey(address uint32, addressType mode_s.AddressType) uint32 {
+	return uint32(addressType)<<24 | address
+}
+
+// GetPlane gives us the plane with the given ICAO address, creating it if we are not yet tracking it
 func (t *Tracker) GetPlane(icao uint32) *Plane {
-	plane, ok := t.planeList.Load(icao)
+	return t.GetPlaneByAddress(icao, mode_s.AddressTypeIcao)
+}
+
+// GetPlaneByAddress gives us the plane with the given address and address type, creating it if needed
+func (t *Tracker) GetPlaneByAddress(address uint32, addressType mode_s.AddressType) *Plane {
+	key := planeKey(address, addressType)
+	plane, ok := t.planeList.Load(key)
 	if ok {
 		return plane.(*Plane)
 	}
-	t.infoMessage("Plane %06X has made an appearance", icao)
+	t.infoMessage("Plane %06X (%s) has made an appearance", address, addressType)
 	if nil != t.stats.currentPlanes {
 		t.stats.currentPlanes.Inc()
 	}
 
-	p := newPlane(icao)
+	p := newPlane(address)
+	p.setAddressType(addressType)
 	p.tracker = t
-	t.planeList.Store(icao, p)
+	if t.smoothTracks {
+		p.smoothed = &trackFilter{}
+	}
+	t.planeList.Store(key, p)
 	return p
 }
 
+// findPlane gives us the plane if we are already tracking it, it does not create one
+func (t *Tracker) findPlane(icao uint32) (*Plane, bool) {
+	plane, ok := t.planeList.Load(planeKey(icao, mode_s.AddressTypeIcao))
+	if !ok {
+		return nil, false
+	}
+	return plane.(*Plane), true
+}
+
 func (t *Tracker) EachPlane(pi PlaneIterator) {
 	t.planeList.Range(func(key, value interface{}) bool {
 		return pi(value.(*Plane))
@@ -135,6 +192,7 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 	}
 	var planeFormat string
 	var hasChanged bool
+	emergency := p.Emergency()
 
 	p.setLastSeen(frame.TimeStamp())
 	p.incMsgCount()
@@ -146,14 +204,19 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 
 	hasChanged = p.setRegistration(frame.DecodeAuIcaoRegistration()) || hasChanged
 
-	log.Trace().
-		Str("frame", frame.String()).
-		Str("icao", frame.IcaoStr()).
-		Str("Downlink Type", "DF"+strconv.Itoa(int(frame.DownLinkType()))).
-		Int("Downlink Format", int(frame.DownLinkType())).
-		Str("DF17 Msg Type", frame.MessageTypeString()).
-		Bytes("RAW", frame.Raw()).
-		Send()
+	hasChanged = p.setDataSource(frame.DataSource(), frame.TimeStamp()) || hasChanged
+
+	// building the frame description is expensive, only do it when someone is going to see it
+	if trace := log.Trace(); trace.Enabled() {
+		trace.
+			Str("frame", frame.String()).
+			Str("icao", frame.IcaoStr()).
+			Str("Downlink Type", "DF"+strconv.Itoa(int(frame.DownLinkType()))).
+			Int("Downlink Format", int(frame.DownLinkType())).
+			Str("DF17 Msg Type", frame.MessageTypeString()).
+			Bytes("RAW", frame.Raw()).
+			Send()
+	}
 
 	// determine what to do with our given frame
 	switch frame.DownLinkType() {
@@ -251,7 +314,7 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 				} else {
 					_ = p.setCprOddLocation(float64(frame.Latitude()), float64(frame.Longitude()), frame.TimeStamp())
 				}
-				if err := p.decodeCprFilledRefLatLon(refLat, refLon, frame.TimeStamp()); nil != err {
+				if err := p.decodeCpr(refLat, refLon, frame.TimeStamp()); nil != err {
 					debugMessage("%s", err)
 				} else {
 					hasChanged = true
@@ -276,7 +339,7 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 
 				altitude, _ := frame.Altitude()
 				p.setAltitude(altitude, frame.AltitudeUnits())
-				if err := p.decodeCpr(0, 0, frame.TimeStamp()); nil != err {
+				if err := p.decodeCpr(refLat, refLon, frame.TimeStamp()); nil != err {
 					debugMessage("%s", err)
 				} else {
 					hasChanged = true
@@ -339,17 +402,47 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 					hasChanged = p.setSpecial("special", frame.Special()) || hasChanged
 					hasChanged = p.setSpecial("emergency", frame.Emergency()) || hasChanged
 				}
+				if state, err := frame.EmergencyState(); nil == err {
+					hasChanged = p.setEmergencyStatus(state) || hasChanged
+				}
 				hasChanged = p.setSquawkIdentity(frame.SquawkIdentity()) || hasChanged
 				break
 			}
 		case mode_s.DF17FrameTcasRA: //, "Extended Squitter Aircraft status (1090ES TCAS RA)":
 			{
-				debugMessage("\033[2m Ignoring: DF%d %s\033[0m", frame.DownLinkType(), messageType)
+				// the RA itself is sent out as an event below
 				break
 			}
 		case mode_s.DF17FrameTargetStateStatus: //, "Target State and status Message":
 			{
-				debugMessage("\033[2m Ignoring: DF%d %s\033[0m", frame.DownLinkType(), messageType)
+				if frame.SelectedAltitudeMcpValid() {
+					alt, _ := frame.SelectedAltitudeMcp()
+					hasChanged = p.setSelectedAltitudeMcp(alt) || hasChanged
+				}
+				if frame.SelectedAltitudeFmsValid() {
+					alt, _ := frame.SelectedAltitudeFms()
+					hasChanged = p.setSelectedAltitudeFms(alt) || hasChanged
+				}
+				if frame.BaroSettingValid() {
+					baro, _ := frame.BaroSetting()
+					hasChanged = p.setBaroSetting(baro) || hasChanged
+				}
+				if frame.SelectedHeadingValid() {
+					hdg, _ := frame.SelectedHeading()
+					hasChanged = p.setSelectedHeading(hdg) || hasChanged
+				}
+				if frame.AutopilotModesValid() {
+					ap, _ := frame.AutopilotEngaged()
+					vnav, _ := frame.VnavEngaged()
+					lnav, _ := frame.LnavEngaged()
+					altHold, _ := frame.AltitudeHoldEngaged()
+					approach, _ := frame.ApproachEngaged()
+					hasChanged = p.setAutopilotModes(ap, vnav, lnav, altHold, approach) || hasChanged
+				}
+				if frame.TcasOperationalValid() {
+					tcas, _ := frame.TcasOperational()
+					hasChanged = p.setTcasOperational(tcas) || hasChanged
+				}
 				break
 			}
 		case mode_s.DF17FrameAircraftOperational: //, "Aircraft Operational status Message":
@@ -373,17 +466,94 @@ func (p *Plane) HandleModeSFrame(frame *mode_s.Frame, refLat, refLon *float64) {
 			}
 		case mode_s.BdsElsAircraftIdent: // 2.0
 			hasChanged = p.setFlightNumber(frame.FlightNumber()) || hasChanged
+		case mode_s.BdsEhsSelVertIntent: // 4.0
+			if frame.SelectedAltitudeMcpValid() {
+				alt, _ := frame.SelectedAltitudeMcp()
+				hasChanged = p.setSelectedAltitudeMcp(alt) || hasChanged
+			}
+			if frame.SelectedAltitudeFmsValid() {
+				alt, _ := frame.SelectedAltitudeFms()
+				hasChanged = p.setSelectedAltitudeFms(alt) || hasChanged
+			}
+			if frame.BaroSettingValid() {
+				baro, _ := frame.BaroSetting()
+				hasChanged = p.setBaroSetting(baro) || hasChanged
+			}
+		case mode_s.BdsEhsTrackTurnReport: // 5.0
+			if frame.RollAngleValid() {
+				roll, _ := frame.RollAngle()
+				hasChanged = p.setRollAngle(roll) || hasChanged
+			}
+			if frame.TrueTrackValid() {
+				track, _ := frame.TrueTrack()
+				hasChanged = p.setTrueTrack(track) || hasChanged
+			}
+			if frame.TrackRateValid() {
+				rate, _ := frame.TrackRate()
+				hasChanged = p.setTrackRate(rate) || hasChanged
+			}
+			if frame.GroundSpeedValid() {
+				gs, _ := frame.GroundSpeed()
+				hasChanged = p.setGroundSpeed(gs) || hasChanged
+			}
+			if frame.TrueAirSpeedValid() {
+				tas, _ := frame.TrueAirSpeed()
+				hasChanged = p.setTrueAirSpeed(tas) || hasChanged
+			}
+		case mode_s.BdsEhsHeadingSpeed: // 6.0
+			if frame.MagneticHeadingValid() {
+				hdg, _ := frame.MagneticHeading()
+				hasChanged = p.setMagneticHeading(hdg) || hasChanged
+			}
+			if frame.IndicatedAirSpeedValid() {
+				ias, _ := frame.IndicatedAirSpeed()
+				hasChanged = p.setIndicatedAirSpeed(ias) || hasChanged
+			}
+			if frame.MachValid() {
+				mach, _ := frame.Mach()
+				hasChanged = p.setMach(mach) || hasChanged
+			}
+			if frame.BaroVerticalRateValid() {
+				rate, _ := frame.BaroVerticalRate()
+				hasChanged = p.setBaroVerticalRate(rate) || hasChanged
+			}
+			if frame.InertialVerticalRateValid() {
+				rate, _ := frame.InertialVerticalRate()
+				hasChanged = p.setInertialVerticalRate(rate) || hasChanged
+			}
+		case mode_s.BdsMetRoutineAirReport, mode_s.BdsMetHazartReport: // 4.4, 4.5
+			p.tracker.AddEvent(newWeatherReportEvent(p, frame))
 		default:
 			// let's see if we can decode more BDS info
 			// TODO: Decode Other BDS frames
 		}
 	}
 
+	if frame.AcasRaValid() && p.setAcasRa(acasRaKey(frame), frame.TimeStamp()) {
+		var threat *Plane
+		if icao, err := frame.AcasThreatIcao(); nil == err {
+			threat, _ = p.tracker.findPlane(icao)
+		}
+		p.tracker.AddEvent(newAcasRaEvent(p, threat, frame))
+	}
+
+	p.checkEmergency(emergency, frame.TimeStamp())
+	hasChanged = p.updateFlightPhase(frame.TimeStamp()) || hasChanged
 	if hasChanged {
 		p.tracker.AddEvent(newPlaneLocationEvent(p))
 	}
 }
 
+// acasRaKey identifies an RA by the advisories given and who they were against, so we can tell when it changes
+func acasRaKey(frame *mode_s.Frame) string {
+	threat, err := frame.AcasThreatIcao()
+	if nil != err {
+		threat = 0
+	}
+	return fmt.Sprintf("%v|%v|%t|%t|%06X",
+		frame.AcasActiveRa(), frame.AcasRaComplements(), frame.AcasRaTerminated(), frame.AcasMultipleThreat(), threat)
+}
+
 func (p *Plane) HandleSbs1Frame(frame *sbs1.Frame) {
 	var hasChanged bool
 	p.setLastSeen(frame.TimeStamp())
@@ -402,6 +572,65 @@ func (p *Plane) HandleSbs1Frame(frame *sbs1.Frame) {
 	}
 }
 
+// HandleUatFrame updates the plane from a 978MHz UAT ADS-B frame
+func (p *Plane) HandleUatFrame(frame *uat.Frame) {
+	if nil == frame || frame.IsUplink() {
+		return
+	}
+	var hasChanged bool
+	emergency := p.Emergency()
+	p.setLastSeen(frame.TimeStamp())
+	p.incMsgCount()
+
+	hasChanged = p.setDataSource(frame.DataSource(), frame.TimeStamp()) || hasChanged
+
+	if frame.HasAirGroundState {
+		hasChanged = p.setGroundStatus(frame.OnGround) || hasChanged
+	}
+	if altitude, ok := frame.Altitude(); ok {
+		hasChanged = p.setAltitude(altitude, "feet") || hasChanged
+	}
+	if frame.HasPosition {
+		if err := p.addLatLong(frame.Lat, frame.Lon, frame.TimeStamp()); nil != err {
+			p.tracker.debugMessage("%s", err)
+		} else {
+			hasChanged = true
+		}
+		p.setLocationUpdateTime(frame.TimeStamp())
+	}
+	if frame.HasTrack {
+		hasChanged = p.setHeading(frame.Track) || hasChanged
+	}
+	if frame.HasVelocity {
+		hasChanged = p.setVelocity(frame.Velocity) || hasChanged
+	}
+	if frame.HasVerticalRate {
+		hasChanged = p.setVerticalRate(frame.VerticalRate) || hasChanged
+	}
+
+	if frame.HasModeStatus {
+		if "" != frame.CallSign {
+			hasChanged = p.setFlightNumber(frame.CallSign) || hasChanged
+		}
+		if frame.HasSquawk {
+			hasChanged = p.setSquawkIdentity(frame.Squawk) || hasChanged
+		}
+		hasChanged = p.setAirFrameCategory(frame.Category()) || hasChanged
+		hasChanged = p.setAirFrameCategoryType(frame.CategoryType()) || hasChanged
+		if 0 != frame.EmergencyStatus {
+			hasChanged = p.setSpecial("emergency", frame.Emergency()) || hasChanged
+		}
+		hasChanged = p.setEmergencyStatus(mode_s.EmergencyState(frame.EmergencyStatus)) || hasChanged
+	}
+	p.tracker.debugMessage("UAT Plane %s (%s) %s", p.IcaoIdentifierStr(), frame.AddressQualifierString(), p.FlightNumber())
+
+	p.checkEmergency(emergency, frame.TimeStamp())
+	hasChanged = p.updateFlightPhase(frame.TimeStamp()) || hasChanged
+	if hasChanged {
+		p.tracker.AddEvent(newPlaneLocationEvent(p))
+	}
+}
+
 func (t *Tracker) prunePlanes() {
 	ticker := time.NewTicker(t.pruneTick)
 	for {
@@ -411,10 +640,15 @@ func (t *Tracker) prunePlanes() {
 			oldest := time.Now().Add(-t.pruneAfter)
 			t.EachPlane(func(p *Plane) bool {
 				if p.LastSeen().Before(oldest) {
-					t.planeList.Delete(p.icaoIdentifier)
+					t.planeList.Delete(planeKey(p.IcaoIdentifier(), p.AddressType()))
+					t.indexSquawk(p, p.SquawkIdentity(), 0)
+					t.modeAPlanes.Delete(p)
 					if nil != t.stats.currentPlanes {
 						t.stats.currentPlanes.Dec()
 					}
+					if nil != t.stats.rejectedPositions {
+						t.stats.rejectedPositions.DeleteLabelValues(p.IcaoIdentifierStr())
+					}
 
 					// now send an event
 					t.AddEvent(newPlaneActionEvent(p, false, true))
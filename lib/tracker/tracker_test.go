@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/rs/zerolog"
 	"plane.watch/lib/tracker/mode_s"
+	"plane.watch/lib/tracker/uat"
+	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -363,3 +366,272 @@ func TestCorrectCprDecodeSouthAmerica(t *testing.T) {
 		})
 	}
 }
+
+func TestPlane_HandleEhsFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		check func(p *Plane) bool
+	}{
+		{name: "BDS 4,0", frame: "A000029C85E42F313000007047D3", check: func(p *Plane) bool {
+			return nil != p.SelectedAltitudeMcp() && 3008 == *p.SelectedAltitudeMcp() &&
+				nil != p.BaroSetting() && 1020 == *p.BaroSetting()
+		}},
+		{name: "BDS 5,0", frame: "A000139381951536E024D4CCF6B5", check: func(p *Plane) bool {
+			return nil != p.RollAngle() && 2.109375 == *p.RollAngle() &&
+				nil != p.TrueAirSpeed() && 424 == *p.TrueAirSpeed() &&
+				nil != p.GroundSpeed() && 438 == *p.GroundSpeed()
+		}},
+		{name: "BDS 6,0", frame: "A00004128F39F91A7E27C46ADC21", check: func(p *Plane) bool {
+			return nil != p.IndicatedAirSpeed() && 252 == *p.IndicatedAirSpeed() &&
+				nil != p.Mach() && 0.42 == *p.Mach() &&
+				nil != p.BaroVerticalRate() && -1920 == *p.BaroVerticalRate() &&
+				nil != p.InertialVerticalRate() && -1920 == *p.InertialVerticalRate()
+		}},
+		{name: "DF17 Target State", frame: "8DA05629EA21485CBF3F8CADAEEB", check: func(p *Plane) bool {
+			return nil != p.SelectedAltitudeMcp() && 16992 == *p.SelectedAltitudeMcp() &&
+				nil != p.SelectedHeading() && 66.796875 == *p.SelectedHeading() &&
+				nil != p.AutopilotEngaged() && *p.AutopilotEngaged() &&
+				nil != p.ApproachEngaged() && !*p.ApproachEngaged() &&
+				nil != p.TcasOperational() && *p.TcasOperational()
+		}},
+	}
+	trk := NewTracker()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := mode_s.DecodeString(tt.frame, time.Now())
+			if nil != err {
+				t.Fatal(err)
+			}
+			plane := trk.GetPlane(frame.Icao())
+			plane.HandleModeSFrame(frame, nil, nil)
+			if !tt.check(plane) {
+				t.Errorf("EHS data was not set on the plane for %s", tt.frame)
+			}
+		})
+	}
+}
+
+// testSink collects all the events the tracker sends out
+type testSink struct {
+	sync.Mutex
+	events []Event
+}
+
+func (s *testSink) OnEvent(e Event) {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, e)
+}
+func (s *testSink) Stop()                   {}
+func (s *testSink) HealthCheck() bool       { return true }
+func (s *testSink) HealthCheckName() string { return "Test Sink" }
+
+func TestPlane_HandleWeatherReport(t *testing.T) {
+	trk := NewTracker()
+	sink := &testSink{}
+	trk.AddSink(sink)
+
+	frame, err := mode_s.DecodeString("A0001692185BD5CF400000DFC696", time.Now())
+	if nil != err {
+		t.Fatal(err)
+	}
+	trk.GetPlane(frame.Icao()).HandleModeSFrame(frame, nil, nil)
+	trk.Stop()
+
+	found := false
+	for _, e := range sink.events {
+		if we, ok := e.(*WeatherReportEvent); ok {
+			found = true
+			if we.Frame() != frame {
+				t.Error("Weather report event has the wrong frame")
+			}
+			if !we.Frame().WindValid() {
+				t.Error("Expected the weather report to have wind")
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected a weather report event")
+	}
+}
+
+func TestPlane_HandleAcasRa(t *testing.T) {
+	trk := NewTracker()
+	sink := &testSink{}
+	trk.AddSink(sink)
+
+	// the threat needs to be known to us before the RA comes in
+	threat := trk.GetPlane(0x7C1234)
+
+	now := time.Now()
+	frame, err := mode_s.DecodeString("8D7C6B2DE2C00205F048D08D2247", now)
+	if nil != err {
+		t.Fatal(err)
+	}
+	trk.GetPlane(frame.Icao()).HandleModeSFrame(frame, nil, nil)
+
+	// the same RA, repeated while it is active, is only sent once
+	for _, at := range []time.Time{now.Add(time.Second), now.Add(30 * time.Second)} {
+		repeat, err := mode_s.DecodeString("8D7C6B2DE2C00205F048D08D2247", at)
+		if nil != err {
+			t.Fatal(err)
+		}
+		trk.GetPlane(repeat.Icao()).HandleModeSFrame(repeat, nil, nil)
+	}
+	trk.Stop()
+
+	found := 0
+	for _, e := range sink.events {
+		if ae, ok := e.(*AcasRaEvent); ok {
+			found++
+			if ae.Frame() != frame {
+				t.Error("ACAS RA event has the wrong frame")
+			}
+			if ae.Plane().IcaoIdentifier() != 0x7C6B2D {
+				t.Errorf("Expected the RA to be from 7C6B2D, got %s", ae.Plane().IcaoIdentifierStr())
+			}
+			if ae.Threat() != threat {
+				t.Error("Expected the RA to include the threat aircraft")
+			}
+		}
+	}
+	if 1 != found {
+		t.Errorf("Expected 1 ACAS RA event, got %d", found)
+	}
+}
+
+func TestPlane_HandleEmergency(t *testing.T) {
+	type change struct {
+		previous, emergency mode_s.EmergencyState
+	}
+	tests := []struct {
+		name    string
+		frames  []string
+		changes []change
+	}{
+		{
+			name:   "Type 28 Emergency Status",
+			frames: []string{"8D7C1234E12000000000003E742D", "8D7C1234E12000000000003E742D", "8D7C1234E10000000000009A02F4"},
+			changes: []change{
+				{previous: mode_s.EmergencyNone, emergency: mode_s.EmergencyGeneral},
+				{previous: mode_s.EmergencyGeneral, emergency: mode_s.EmergencyNone},
+			},
+		},
+		{
+			name:   "Emergency Squawks",
+			frames: []string{"28000AAA36B6FD", "28000A8A37774D", "280008082C7696"},
+			changes: []change{
+				{previous: mode_s.EmergencyNone, emergency: mode_s.EmergencyGeneral},
+				{previous: mode_s.EmergencyGeneral, emergency: mode_s.EmergencyNoCommunications},
+				{previous: mode_s.EmergencyNoCommunications, emergency: mode_s.EmergencyNone},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trk := NewTracker()
+			sink := &testSink{}
+			trk.AddSink(sink)
+			p := trk.GetPlane(0x7C1234)
+
+			for _, raw := range tt.frames {
+				frame, err := mode_s.DecodeString(raw, time.Now())
+				if nil != err {
+					t.Fatalf("Failed to decode %s: %s", raw, err)
+				}
+				p.HandleModeSFrame(frame, nil, nil)
+			}
+			trk.Stop()
+
+			var changes []change
+			for _, e := range sink.events {
+				if ee, ok := e.(*EmergencyEvent); ok {
+					if ee.Plane() != p {
+						t.Error("Emergency event is for the wrong plane")
+					}
+					if ee.Entered() == ee.Exited() {
+						t.Errorf("Emergency event %s must be one of entered or exited", ee)
+					}
+					changes = append(changes, change{previous: ee.Previous(), emergency: ee.Emergency()})
+				}
+			}
+			if !reflect.DeepEqual(tt.changes, changes) {
+				t.Errorf("Expected emergency changes %v, got %v", tt.changes, changes)
+			}
+			if p.HasEmergency() {
+				t.Errorf("Expected the emergency to be over, got %s", p.Emergency())
+			}
+		})
+	}
+}
+
+func TestTracker_GetPlaneByAddress(t *testing.T) {
+	trk := NewTracker()
+	adsb, _ := mode_s.DecodeString("907C123458C382D690C8AC6859C9", time.Now())
+	tisb, _ := mode_s.DecodeString("927C123459C382D690C8AC04C1CE", time.Now())
+
+	trk.GetPlaneByAddress(adsb.Icao(), adsb.AddressType()).HandleModeSFrame(adsb, nil, nil)
+	trk.GetPlaneByAddress(tisb.Icao(), tisb.AddressType()).HandleModeSFrame(tisb, nil, nil)
+
+	if 2 != trk.numPlanes() {
+		t.Fatalf("Expected the ICAO and TIS-B targets to be tracked separately, have %d planes", trk.numPlanes())
+	}
+	icaoPlane := trk.GetPlane(0x7C1234)
+	if "7C1234" != icaoPlane.IcaoIdentifierStr() || mode_s.DataSourceAdsb != icaoPlane.DataSource() {
+		t.Errorf("Incorrect ICAO plane, got %s from %s", icaoPlane.IcaoIdentifierStr(), icaoPlane.DataSource())
+	}
+	other := trk.GetPlaneByAddress(0x7C1234, mode_s.AddressTypeTisbOther)
+	if "~7C1234" != other.IcaoIdentifierStr() || mode_s.DataSourceTisb != other.DataSource() {
+		t.Errorf("Incorrect TIS-B plane, got %s from %s", other.IcaoIdentifierStr(), other.DataSource())
+	}
+}
+
+func TestTracker_planeForFrame(t *testing.T) {
+	trk := NewTracker()
+	df4, _ := mode_s.DecodeString("200018386DFEF1", time.Now())
+	if !df4.IcaoFromParity() || 0x7C7DAA != df4.Icao() {
+		t.Fatalf("Expected ICAO 7C7DAA from the AP field, got %06X", df4.Icao())
+	}
+
+	if _, ok := trk.planeForFrame(df4); ok {
+		t.Error("Should not have created a plane from an Address/Parity reply")
+	}
+	if 0 != trk.numPlanes() {
+		t.Errorf("Expected no planes, have %d", trk.numPlanes())
+	}
+
+	df11, _ := mode_s.DecodeString("5D7C7DAACD3CE9", time.Now())
+	allCall, ok := trk.planeForFrame(df11)
+	if !ok || nil == allCall {
+		t.Fatal("Expected a plane from the DF11 all call reply")
+	}
+
+	surveillance, ok := trk.planeForFrame(df4)
+	if !ok || surveillance != allCall {
+		t.Error("Expected the Address/Parity reply to be attached to the plane we are tracking")
+	}
+}
+
+func TestPlane_HandleUatFrame(t *testing.T) {
+	trk := NewTracker()
+	frame := uat.NewFrame("-08a1b2c3d28f5ca4c7560f18019432e0b016df3eaa18840800000200000f50000000;rs=1;", time.Now())
+	if ok, err := frame.Decode(); !ok || nil != err {
+		t.Fatalf("Failed to decode UAT frame: %s", err)
+	}
+	p, ok := trk.planeForFrame(frame)
+	if !ok {
+		t.Fatal("Expected a plane for our UAT frame")
+	}
+	p.HandleUatFrame(frame)
+
+	if "A1B2C3" != p.IcaoIdentifierStr() || mode_s.DataSourceUat != p.DataSource() {
+		t.Errorf("Incorrect plane %s from %s", p.IcaoIdentifierStr(), p.DataSource())
+	}
+	if !p.HasLocation() || 5000 != p.Altitude() || "QFA123" != p.FlightNumber() {
+		t.Errorf("Incorrect plane details, location: %t altitude: %d flight: %s", p.HasLocation(), p.Altitude(), p.FlightNumber())
+	}
+	if !p.HasVerticalRate() || -640 != p.VerticalRate() {
+		t.Errorf("Incorrect vertical rate %d", p.VerticalRate())
+	}
+}
@@ -0,0 +1,353 @@
+package uat
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"plane.watch/lib/tracker/mode_s"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decodes the dump978 raw format. Each line is one frame:
+//   -<hex>;rs=N;   an ADS-B downlink frame (18 bytes basic or 34 bytes long)
+//   +<hex>;rs=N;   a ground station uplink frame (FIS-B), which we do not decode
+// https://github.com/mutability/dump978 (uat_decode.c) and DO-282B are the references for the bit layouts
+
+const (
+	basicFrameBytes = 18
+	longFrameBytes  = 34
+
+	// altitude types, for the primary and secondary (auxiliary state vector) altitudes
+	AltitudeBaro = 0
+	AltitudeGeo  = 1
+
+	airGroundSubsonic   = 0
+	airGroundSupersonic = 1
+	airGroundGround     = 2
+)
+
+const base40Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ  .."
+
+var (
+	ErrUplink = errors.New("uat uplink frames are not decoded")
+
+	addressQualifierTable = map[byte]string{
+		0: "ADS-B target with ICAO 24 bit address",
+		1: "ADS-B target with self-assigned temporary address",
+		2: "TIS-B or ADS-R target with ICAO 24 bit address",
+		3: "TIS-B target with track file identifier",
+		4: "Surface vehicle",
+		5: "Fixed ADS-B beacon",
+		6: "ADS-R target with non ICAO address",
+		7: "Reserved",
+	}
+)
+
+type Frame struct {
+	// original is our unadulterated string
+	original string
+	message  []byte
+	received time.Time
+	uplink   bool
+
+	PayloadType      byte
+	AddressQualifier byte
+	Address          uint32
+
+	// State Vector
+	HasPosition       bool
+	Lat, Lon          float64
+	HasAltitude       bool
+	AltitudeType      byte
+	PrimaryAltitude   int32
+	Nic               byte
+	HasAirGroundState bool
+	OnGround          bool
+	HasVelocity       bool
+	Velocity          float64 // knots
+	HasTrack          bool
+	Track             float64
+	HasVerticalRate   bool
+	VerticalRate      int // feet per minute
+
+	// Mode Status
+	HasModeStatus   bool
+	EmitterCategory byte
+	CallSign        string
+	HasSquawk       bool
+	Squawk          uint32
+	EmergencyStatus byte
+
+	// Auxiliary State Vector
+	HasSecondaryAltitude bool
+	SecondaryAltitude    int32
+}
+
+func NewFrame(line string, t time.Time) *Frame {
+	return &Frame{
+		original: line,
+		received: t,
+	}
+}
+
+func (f *Frame) TimeStamp() time.Time {
+	return f.received
+}
+
+func (f *Frame) Icao() uint32 {
+	return f.Address
+}
+
+func (f *Frame) IcaoStr() string {
+	return fmt.Sprintf("%06X", f.Address)
+}
+
+func (f *Frame) Raw() []byte {
+	return []byte(f.original)
+}
+
+// Decode parses our dump978 line. Uplink frames are not an error, but do not give us anything to track
+func (f *Frame) Decode() (bool, error) {
+	if err := f.Parse(); nil != err {
+		if errors.Is(err, ErrUplink) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (f *Frame) Parse() error {
+	line := strings.TrimSpace(f.original)
+	if len(line) < 2 {
+		return fmt.Errorf("not a UAT frame: %s", f.original)
+	}
+	switch line[0] {
+	case '-':
+		f.uplink = false
+	case '+':
+		f.uplink = true
+		return ErrUplink
+	default:
+		return fmt.Errorf("not a UAT frame: %s", f.original)
+	}
+
+	encoded := line[1:]
+	if idx := strings.IndexByte(encoded, ';'); idx >= 0 {
+		encoded = encoded[:idx]
+	}
+	var err error
+	if f.message, err = hex.DecodeString(encoded); nil != err {
+		return fmt.Errorf("failed to decode UAT frame %s: %w", f.original, err)
+	}
+	if basicFrameBytes != len(f.message) && longFrameBytes != len(f.message) {
+		return fmt.Errorf("incorrect UAT frame length %d bytes: %s", len(f.message), f.original)
+	}
+
+	f.decodeHeader()
+	// only payload type 0 is a basic frame, the others carry the mode status or aux state vector in a long frame
+	if 0 != f.PayloadType && longFrameBytes != len(f.message) {
+		return fmt.Errorf("UAT payload type %d needs a long frame, got %d bytes: %s", f.PayloadType, len(f.message), f.original)
+	}
+	switch f.PayloadType {
+	case 0, 4, 7, 8, 9, 10:
+		f.decodeStateVector()
+	case 1:
+		f.decodeStateVector()
+		f.decodeModeStatus()
+		f.decodeAuxStateVector()
+	case 2, 5, 6:
+		f.decodeStateVector()
+		f.decodeAuxStateVector()
+	case 3:
+		f.decodeStateVector()
+		f.decodeModeStatus()
+	}
+	return nil
+}
+
+func (f *Frame) decodeHeader() {
+	f.PayloadType = f.message[0] >> 3 & 0x1f
+	f.AddressQualifier = f.message[0] & 0x07
+	f.Address = uint32(f.message[1])<<16 | uint32(f.message[2])<<8 | uint32(f.message[3])
+}
+
+func (f *Frame) decodeStateVector() {
+	m := f.message
+	f.Nic = m[11] & 0x0f
+
+	rawLat := uint32(m[4])<<15 | uint32(m[5])<<7 | uint32(m[6])>>1
+	rawLon := uint32(m[6]&0x01)<<23 | uint32(m[7])<<15 | uint32(m[8])<<7 | uint32(m[9])>>1
+	if 0 != f.Nic || 0 != rawLat || 0 != rawLon {
+		f.HasPosition = true
+		f.Lat = float64(rawLat) * 360.0 / 16777216.0
+		if f.Lat > 90 {
+			f.Lat -= 180
+		}
+		f.Lon = float64(rawLon) * 360.0 / 16777216.0
+		if f.Lon > 180 {
+			f.Lon -= 360
+		}
+	}
+
+	rawAlt := int32(m[10])<<4 | int32(m[11]&0xf0)>>4
+	if 0 != rawAlt {
+		f.HasAltitude = true
+		f.AltitudeType = m[9] & 0x01
+		f.PrimaryAltitude = (rawAlt-1)*25 - 1000
+	}
+
+	airGround := m[12] >> 6 & 0x03
+	switch airGround {
+	case airGroundSubsonic, airGroundSupersonic:
+		f.HasAirGroundState = true
+		f.OnGround = false
+
+		rawNs := int(m[12]&0x1f)<<6 | int(m[13]&0xfc)>>2
+		rawEw := int(m[13]&0x03)<<9 | int(m[14])<<1 | int(m[15]&0x80)>>7
+		if 0 != rawNs&0x3ff && 0 != rawEw&0x3ff {
+			ns := float64(rawNs&0x3ff - 1)
+			if 0 != rawNs&0x400 {
+				ns = -ns
+			}
+			ew := float64(rawEw&0x3ff - 1)
+			if 0 != rawEw&0x400 {
+				ew = -ew
+			}
+			if airGroundSupersonic == airGround {
+				ns *= 4
+				ew *= 4
+			}
+			f.HasVelocity = true
+			f.Velocity = math.Sqrt(ns*ns + ew*ew)
+			if 0 != ns || 0 != ew {
+				f.HasTrack = true
+				f.Track = math.Mod(360+90-math.Atan2(ns, ew)*180/math.Pi, 360)
+			}
+		}
+
+		rawVvel := int(m[15]&0x7f)<<4 | int(m[16]&0xf0)>>4
+		if 0 != rawVvel&0x1ff {
+			f.HasVerticalRate = true
+			f.VerticalRate = (rawVvel&0x1ff - 1) * 64
+			if 0 != rawVvel&0x200 {
+				f.VerticalRate = -f.VerticalRate
+			}
+		}
+
+	case airGroundGround:
+		f.HasAirGroundState = true
+		f.OnGround = true
+
+		rawGs := int(m[12]&0x1f)<<6 | int(m[13]&0xfc)>>2
+		if 0 != rawGs&0x3ff {
+			f.HasVelocity = true
+			f.Velocity = float64(rawGs&0x3ff - 1)
+		}
+		rawTrack := int(m[13]&0x03)<<9 | int(m[14])<<1 | int(m[15]&0x80)>>7
+		if 0 != rawTrack&0x600 {
+			// track, magnetic heading or true heading
+			f.HasTrack = true
+			f.Track = float64(rawTrack&0x1ff) * 360 / 512
+		}
+	}
+}
+
+func (f *Frame) decodeModeStatus() {
+	m := f.message
+	f.HasModeStatus = true
+
+	callSign := make([]byte, 8)
+	v := int(m[17])<<8 | int(m[18])
+	f.EmitterCategory = byte(v / 1600 % 40)
+	callSign[0] = base40Alphabet[v/40%40]
+	callSign[1] = base40Alphabet[v%40]
+	v = int(m[19])<<8 | int(m[20])
+	callSign[2] = base40Alphabet[v/1600%40]
+	callSign[3] = base40Alphabet[v/40%40]
+	callSign[4] = base40Alphabet[v%40]
+	v = int(m[21])<<8 | int(m[22])
+	callSign[5] = base40Alphabet[v/1600%40]
+	callSign[6] = base40Alphabet[v/40%40]
+	callSign[7] = base40Alphabet[v%40]
+	ident := strings.TrimRight(string(callSign), " ")
+
+	f.EmergencyStatus = m[23] >> 5 & 0x07
+
+	// the CSID bit tells us if we have a call sign, or a squawk in the call sign field
+	if 0 != m[26]&0x02 {
+		f.CallSign = ident
+	} else if squawk, err := strconv.ParseUint(ident, 10, 32); nil == err && 4 == len(ident) {
+		f.HasSquawk = true
+		f.Squawk = uint32(squawk)
+	}
+}
+
+func (f *Frame) decodeAuxStateVector() {
+	rawAlt := int32(f.message[29])<<4 | int32(f.message[30]&0xf0)>>4
+	if 0 != rawAlt {
+		f.HasSecondaryAltitude = true
+		f.SecondaryAltitude = (rawAlt-1)*25 - 1000
+	}
+}
+
+// IsUplink tells us this is a ground station uplink (FIS-B) frame
+func (f *Frame) IsUplink() bool {
+	return f.uplink
+}
+
+// Altitude gives us the barometric altitude if we have it, falling back to the geometric altitude
+func (f *Frame) Altitude() (int32, bool) {
+	if f.HasAltitude && AltitudeBaro == f.AltitudeType {
+		return f.PrimaryAltitude, true
+	}
+	// the auxiliary state vector altitude is always the other type to the primary altitude
+	if f.HasSecondaryAltitude && f.HasAltitude {
+		return f.SecondaryAltitude, true
+	}
+	return f.PrimaryAltitude, f.HasAltitude
+}
+
+// AddressType maps the UAT address qualifier onto the same address types we use for 1090MHz
+func (f *Frame) AddressType() mode_s.AddressType {
+	switch f.AddressQualifier {
+	case 1, 4, 5:
+		return mode_s.AddressTypeAdsbOther
+	case 3:
+		return mode_s.AddressTypeTisbOther
+	case 6:
+		return mode_s.AddressTypeAdsrOther
+	}
+	return mode_s.AddressTypeIcao
+}
+
+func (f *Frame) AddressQualifierString() string {
+	return addressQualifierTable[f.AddressQualifier]
+}
+
+// DataSource tells us how we got this frame. One of UAT, TIS-B or ADS-R
+func (f *Frame) DataSource() string {
+	switch f.AddressQualifier {
+	case 2, 3:
+		return mode_s.DataSourceTisb
+	case 6:
+		return mode_s.DataSourceAdsr
+	}
+	return mode_s.DataSourceUat
+}
+
+// Category is the emitter category, UAT uses the same categories as 1090ES ADS-B numbered 0-39
+func (f *Frame) Category() string {
+	return mode_s.CategoryString(f.EmitterCategory/8, f.EmitterCategory%8)
+}
+
+func (f *Frame) CategoryType() string {
+	return fmt.Sprintf("%d/%d", f.EmitterCategory/8, f.EmitterCategory%8)
+}
+
+func (f *Frame) Emergency() string {
+	return mode_s.EmergencyString(int(f.EmergencyStatus))
+}
@@ -0,0 +1,119 @@
+package uat
+
+import (
+	"math"
+	"plane.watch/lib/tracker/mode_s"
+	"testing"
+	"time"
+)
+
+func TestFrame_Decode(t *testing.T) {
+	tests := []struct {
+		name         string
+		line         string
+		payloadType  byte
+		address      uint32
+		addressType  mode_s.AddressType
+		dataSource   string
+		lat, lon     float64
+		altitude     int32
+		onGround     bool
+		velocity     float64
+		track        float64
+		verticalRate int
+		callSign     string
+		squawk       uint32
+		category     string
+		emergency    byte
+	}{
+		{
+			name:        "long frame, state vector, mode status and aux state vector",
+			line:        "-08a1b2c3d28f5ca4c7560f18019432e0b016df3eaa18840800000200000f50000000;rs=1;",
+			payloadType: 1, address: 0xA1B2C3, addressType: mode_s.AddressTypeIcao, dataSource: mode_s.DataSourceUat,
+			lat: -31.95, lon: 115.86, altitude: 5000, velocity: 141.42, track: 45, verticalRate: -640,
+			callSign: "QFA123", category: "Large (75000 to 300000 lbs)",
+		},
+		{
+			name:        "basic frame, TIS-B on the ground",
+			line:        "-027c123439999b97d27e0297802d40000000;rs=1;",
+			payloadType: 0, address: 0x7C1234, addressType: mode_s.AddressTypeIcao, dataSource: mode_s.DataSourceTisb,
+			lat: 40.5, lon: -73.25, altitude: 0, onGround: true, velocity: 10, track: 90,
+		},
+		{
+			name:        "long frame, self assigned address squawking 1200",
+			line:        "-19abcdef2eeeef59999b08d610cc008000066a0024e6c4a800000000000000000000;rs=1;",
+			payloadType: 3, address: 0xABCDEF, addressType: mode_s.AddressTypeAdsbOther, dataSource: mode_s.DataSourceUat,
+			lat: 33, lon: -117, altitude: 2500, velocity: 50, track: 180,
+			squawk: 1200, category: "Light (< 15500 lbs)", emergency: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFrame(tt.line, time.Now())
+			ok, err := f.Decode()
+			if !ok || nil != err {
+				t.Fatalf("Failed to decode %s: %s", tt.line, err)
+			}
+			if tt.payloadType != f.PayloadType {
+				t.Errorf("Incorrect payload type, expected %d, got %d", tt.payloadType, f.PayloadType)
+			}
+			if tt.address != f.Icao() || tt.addressType != f.AddressType() || tt.dataSource != f.DataSource() {
+				t.Errorf("Incorrect address %s (%s) from %s", f.IcaoStr(), f.AddressType(), f.DataSource())
+			}
+			if !f.HasPosition || math.Abs(tt.lat-f.Lat) > 0.0001 || math.Abs(tt.lon-f.Lon) > 0.0001 {
+				t.Errorf("Incorrect position, expected %0.4f,%0.4f got %0.4f,%0.4f", tt.lat, tt.lon, f.Lat, f.Lon)
+			}
+			if alt, altOk := f.Altitude(); !altOk || tt.altitude != alt {
+				t.Errorf("Incorrect altitude, expected %d, got %d", tt.altitude, alt)
+			}
+			if tt.onGround != f.OnGround {
+				t.Errorf("Incorrect on ground, expected %t", tt.onGround)
+			}
+			if !f.HasVelocity || math.Abs(tt.velocity-f.Velocity) > 0.01 {
+				t.Errorf("Incorrect velocity, expected %0.2f, got %0.2f", tt.velocity, f.Velocity)
+			}
+			if !f.HasTrack || math.Abs(tt.track-f.Track) > 0.01 {
+				t.Errorf("Incorrect track, expected %0.2f, got %0.2f", tt.track, f.Track)
+			}
+			if tt.verticalRate != f.VerticalRate {
+				t.Errorf("Incorrect vertical rate, expected %d, got %d", tt.verticalRate, f.VerticalRate)
+			}
+			if tt.callSign != f.CallSign {
+				t.Errorf("Incorrect call sign, expected %s, got %s", tt.callSign, f.CallSign)
+			}
+			if tt.squawk != f.Squawk {
+				t.Errorf("Incorrect squawk, expected %d, got %d", tt.squawk, f.Squawk)
+			}
+			if "" != tt.category && tt.category != f.Category() {
+				t.Errorf("Incorrect category, expected %s, got %s", tt.category, f.Category())
+			}
+			if tt.emergency != f.EmergencyStatus {
+				t.Errorf("Incorrect emergency status, expected %d, got %d", tt.emergency, f.EmergencyStatus)
+			}
+		})
+	}
+}
+
+func TestFrame_DecodeNotAdsb(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantOk  bool
+		wantErr bool
+	}{
+		{name: "uplink", line: "+3c6dd4a53a4c6ea6a1c3a2f0c3a0000000000000;rs=3;", wantOk: false, wantErr: false},
+		{name: "empty", line: "", wantErr: true},
+		{name: "not uat", line: "*8D7C7DAA99146D0980080D6C6DE4;", wantErr: true},
+		{name: "bad hex", line: "-zz;", wantErr: true},
+		{name: "bad length", line: "-08a1b2c3d28f5c;", wantErr: true},
+		{name: "basic frame with a long payload type", line: "-08a1b2c3d28f5ca4c7560f18019432e0b016;rs=1;", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := NewFrame(tt.line, time.Now()).Decode()
+			if tt.wantOk != ok || tt.wantErr != (nil != err) {
+				t.Errorf("Decode() = %t, %v", ok, err)
+			}
+		})
+	}
+}